@@ -0,0 +1,59 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"fmt"
+)
+
+// DisallowedOptionError is returned for an option whose optname isn't in the
+// allowlist of ExtractWithAllowlist.
+type DisallowedOptionError struct {
+	Name string
+}
+
+func (e *DisallowedOptionError) Error() string {
+	return fmt.Sprintf("option %s is not allowed", e.Name)
+}
+
+// ExtractWithAllowlist extracts options into dest struct, permitting only the
+// options whose optname is in allowed, for APIs taking options from untrusted
+// callers. Any other option results in a *DisallowedOptionError, whether or
+// not dest has a field for it, so known but sensitive options can be refused
+// as well as unknown ones. Strict extraction such as MustExtract only refuses
+// the options dest has no field for.
+//
+// The allowlist is checked before the options are looked up in the fields of
+// dest. Allowed options then match fields as in Extract, so an allowed optname
+// no field has is skipped rather than refused.
+func ExtractWithAllowlist(dest interface{}, allowed []string, options ...interface{}) error {
+	allowlist := make(map[string]bool, len(allowed))
+	for _, optname := range allowed {
+		allowlist[optname] = true
+	}
+	return (&extractor{allowlist: allowlist}).extract(dest, options...)
+}
+
+// checkAllowed refuses an option missing from the allowlist.
+func (x *extractor) checkAllowed(optname string) error {
+	if x.allowlist != nil && !x.allowlist[optname] {
+		return &DisallowedOptionError{Name: optname}
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package opts
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestExtractWithAllowlist(t *testing.T) {
+	allowed := []string{"WithUsername", "WithHost"}
+	opts := testoptions{}
+	if err := ExtractWithAllowlist(&opts, allowed, WithUsername("userbob"), WithHost("localhost")); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Username != "userbob" {
+		t.Fatalf("Username should be 'userbob', got '%s'", opts.Username)
+	}
+
+	// known options outside the allowlist are refused
+	var disallowed *DisallowedOptionError
+	err := ExtractWithAllowlist(&opts, allowed, WithPhoneNum(8675309))
+	if !errors.As(err, &disallowed) || disallowed.Name != "WithPhoneNum" {
+		t.Fatalf("err should be a *DisallowedOptionError for WithPhoneNum, got %v", err)
+	}
+	if opts.PhoneNum != 0 {
+		t.Fatalf("PhoneNum should have been left alone, got %d", opts.PhoneNum)
+	}
+	// and so are unknown ones
+	err = ExtractWithAllowlist(&opts, allowed, WithInvalidOption(true))
+	if !errors.As(err, &disallowed) || disallowed.Name != "WithInvalidOption" {
+		t.Fatalf("err should be a *DisallowedOptionError for WithInvalidOption, got %v", err)
+	}
+
+	var localized *ExtractError
+	if !errors.As(Localize(err), &localized) || localized.Code() != ErrCodeDisallowedOption {
+		t.Fatalf("err should localize to disallowed_option, got %v", Localize(err))
+	}
+}
@@ -0,0 +1,120 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"reflect"
+)
+
+// Action is what happened to an option in an ApplyRecord.
+type Action string
+
+// The actions of an ApplyRecord.
+const (
+	// ActionSet assigned the option into its field.
+	ActionSet Action = "set"
+	// ActionAppend appended the option into its slice field.
+	ActionAppend Action = "append"
+	// ActionSkip found no field for the option.
+	ActionSkip Action = "skip"
+	// ActionError failed to fit the option into its field.
+	ActionError Action = "error"
+	// ActionTruncate cut the string the option left in its field down to the
+	// field's truncatelen, following the record of the assignment.
+	ActionTruncate Action = "truncate"
+	// ActionDeprecated warned under DeprecationWarn that the option used a
+	// deprecated optname, ahead of the record of its assignment.
+	ActionDeprecated Action = "deprecated"
+)
+
+// ApplyRecord is what happened to one option during an extraction.
+type ApplyRecord struct {
+	// Index is the position of the option among the options passed, after
+	// bundles from Combine are expanded. Options in a group share the index
+	// of the group.
+	Index int
+	// OptName is the name the option was looked up by.
+	OptName string
+	// Field is the dotted path of the field the option was fitted into, or
+	// empty when it was skipped.
+	Field  string
+	Action Action
+	// Before and After are the values of the field around the assignment.
+	// Both are nil when the option was skipped, and After is nil when it
+	// failed.
+	Before interface{}
+	After  interface{}
+	// Err is why an option failed.
+	Err error
+}
+
+// ExtractWithLog extracts options into dest struct and returns a record of
+// every option in the order they were applied, so that the logs of two runs
+// can be diffed to find where their configs diverge. On error the log holds
+// the records up to and including the failure. Options not in dest are
+// skipped and logged as such.
+//
+// Only ExtractWithLog keeps records, other extractions pay nothing for it.
+func ExtractWithLog(dest interface{}, options ...interface{}) ([]ApplyRecord, error) {
+	var log []ApplyRecord
+	x := &extractor{}
+	x.recordApply = func(record ApplyRecord) {
+		log = append(log, record)
+	}
+	err := x.extract(dest, options...)
+	return log, err
+}
+
+// logApply records the outcome of assigning an option when the extractor keeps
+// a log. field is the zero reflect.Value for skipped options.
+func (x *extractor) logApply(optionStruct reflect.Value, sf reflect.StructField, optname string, field, previous, optionValue reflect.Value, err error) {
+	if x.recordApply == nil {
+		return
+	}
+	record := ApplyRecord{Index: x.optionIndex, OptName: optname, Action: ActionSkip}
+	if field.IsValid() {
+		record.Field = fieldPath(optionStruct.Type(), sf.Index)
+		record.Before = previous.Interface()
+		switch {
+		case err != nil:
+			record.Action, record.Err = ActionError, err
+		case field.Kind() == reflect.Slice && optionValue.Kind() != reflect.Slice:
+			record.Action, record.After = ActionAppend, field.Interface()
+		default:
+			record.Action, record.After = ActionSet, field.Interface()
+		}
+	}
+	x.recordApply(record)
+}
+
+// logTruncate records that a field was truncated after an option was assigned
+// into it, when assignments are being recorded.
+func (x *extractor) logTruncate(optionStruct reflect.Value, sf reflect.StructField, optname string, field, untruncated reflect.Value) {
+	if x.recordApply == nil {
+		return
+	}
+	x.recordApply(ApplyRecord{
+		Index:   x.optionIndex,
+		OptName: optname,
+		Field:   fieldPath(optionStruct.Type(), sf.Index),
+		Action:  ActionTruncate,
+		Before:  untruncated.Interface(),
+		After:   field.Interface(),
+	})
+}
@@ -0,0 +1,43 @@
+package opts
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractWithLog(t *testing.T) {
+	opts := testoptions{Username: "userbob"}
+	log, err := ExtractWithLog(&opts,
+		WithUsername("useralice"),
+		Combine(WithItem("a"), WithInvalidOption(true)),
+		WithGroup("DB", WithHost("db.internal")),
+	)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	want := []ApplyRecord{
+		{Index: 0, OptName: "WithUsername", Field: "Username", Action: ActionSet, Before: "userbob", After: "useralice"},
+		{Index: 1, OptName: "WithItem", Field: "Items", Action: ActionAppend, Before: []string(nil), After: []string{"a"}},
+		{Index: 2, OptName: "WithInvalidOption", Action: ActionSkip},
+	}
+	if !reflect.DeepEqual(log, want) {
+		t.Fatalf("log should be %+v, got %+v", want, log)
+	}
+
+	grouped := testgroupoptions{}
+	log, err = ExtractWithLog(&grouped, WithUsername("userbob"), WithGroup("DB", WithHost("db.internal"), WithPort(5432)))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(log) != 3 || log[1].Index != 1 || log[2].Index != 1 || log[2].Field != "Port" {
+		t.Fatalf("grouped options should share the index of the group, got %+v", log)
+	}
+
+	log, err = ExtractWithLog(&testcoerceoptions{}, WithCount("lots"))
+	if err == nil {
+		t.Fatalf("ExtractWithLog should have failed fitting WithCount, but err is nil")
+	}
+	if len(log) != 1 || log[0].Action != ActionError || log[0].Err == nil || log[0].After != nil {
+		t.Fatalf("log should hold the failure, got %+v", log)
+	}
+}
@@ -0,0 +1,69 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// fitArray copies the elements of a slice option into an array field. The
+// slice must be exactly as long as the array, unless the field is tagged
+// padzero:"true" in which case shorter slices leave the remaining elements at
+// their zero value.
+func fitArray(field reflect.Value, sf reflect.StructField, optname string, optionValue reflect.Value) error {
+	if !optionValue.Type().Elem().ConvertibleTo(field.Type().Elem()) {
+		return fmt.Errorf("failed to set %s when fitting %s elements into %s elements", optname, optionValue.Type().Elem().String(), field.Type().Elem().String())
+	}
+	if optionValue.Len() > field.Len() {
+		return fmt.Errorf("failed to set %s, %d elements do not fit into field %s of length %d", optname, optionValue.Len(), sf.Name, field.Len())
+	}
+	if optionValue.Len() < field.Len() && sf.Tag.Get("padzero") != "true" {
+		return fmt.Errorf("failed to set %s, %d elements are too few for field %s of length %d", optname, optionValue.Len(), sf.Name, field.Len())
+	}
+
+	array := reflect.New(field.Type()).Elem()
+	for i := 0; i < optionValue.Len(); i++ {
+		elem := optionValue.Index(i)
+		// numbers of another kind must survive the conversion
+		if elem.Kind() != field.Type().Elem().Kind() {
+			if fitted, ok, err := convertNumber(field.Type().Elem(), sf, optname, elem); ok {
+				if err != nil {
+					return err
+				}
+				array.Index(i).Set(fitted)
+				continue
+			}
+		}
+		array.Index(i).Set(elem.Convert(field.Type().Elem()))
+	}
+	field.Set(array)
+	return nil
+}
+
+// fitSlice replaces a slice field with the elements of a slice option, each
+// converted into the field's element type. This lets defined slice types such
+// as []Tag take a []string whole.
+func fitSlice(field reflect.Value, optionValue reflect.Value) {
+	slice := reflect.MakeSlice(field.Type(), optionValue.Len(), optionValue.Len())
+	for i := 0; i < optionValue.Len(); i++ {
+		slice.Index(i).Set(optionValue.Index(i).Convert(field.Type().Elem()))
+	}
+	field.Set(slice)
+}
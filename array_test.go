@@ -0,0 +1,54 @@
+package opts
+
+import (
+	"testing"
+)
+
+func TestArrayFromSlice(t *testing.T) {
+	key := make([]byte, 32)
+	for i := range key {
+		key[i] = byte(i)
+	}
+
+	opts := testarrayoptions{}
+	err := Extract(&opts, WithKey(key), WithPadded([]byte{1, 2}))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	for i := range opts.Key {
+		if opts.Key[i] != byte(i) {
+			t.Fatalf("Key should be copied from the slice, got %v", opts.Key)
+		}
+	}
+	if opts.Padded != [4]byte{1, 2, 0, 0} {
+		t.Fatalf("Padded should be [1 2 0 0], got %v", opts.Padded)
+	}
+
+	// exact length array into array
+	opts = testarrayoptions{}
+	err = Extract(&opts, WithPadded([]byte{1, 2, 3, 4}))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Padded != [4]byte{1, 2, 3, 4} {
+		t.Fatalf("Padded should be [1 2 3 4], got %v", opts.Padded)
+	}
+
+	opts = testarrayoptions{}
+	if err := Extract(&opts, WithKey([]byte{1, 2})); err == nil {
+		t.Fatalf("Extract should have failed on a short slice without padzero, but err is nil")
+	}
+
+	opts = testarrayoptions{}
+	if err := Extract(&opts, WithPadded([]byte{1, 2, 3, 4, 5})); err == nil {
+		t.Fatalf("Extract should have failed on an over-length slice, but err is nil")
+	}
+}
+
+type WithKey []byte
+type WithPadded []byte
+
+type testarrayoptions struct {
+	Key    [32]byte `optname:"WithKey"`
+	Padded [4]byte  `optname:"WithPadded" padzero:"true"`
+}
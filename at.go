@@ -0,0 +1,84 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// indexedOption carries an option bound for one position of an array or
+// slice field.
+type indexedOption struct {
+	index  int
+	option interface{}
+}
+
+// At wraps an option so that it sets the element at index of the array or
+// slice field it is extracted into, rather than the whole field, as in
+// At(0, WithCoord(1.5)) setting Coords[0]. An index beyond the length of an
+// array results in error, while a slice grows to reach the index, with zero
+// elements filling any gap. Other elements are left as they are.
+func At(index int, option interface{}) interface{} {
+	return indexedOption{index: index, option: option}
+}
+
+// applyIndexed applies the option of an indexedOption at its index.
+func (x *extractor) applyIndexed(optionStruct reflect.Value, fieldMap map[string]reflect.StructField, carrier indexedOption) error {
+	outer, outerIndexed := x.index, x.indexed
+	x.index, x.indexed = carrier.index, true
+	defer func() { x.index, x.indexed = outer, outerIndexed }()
+	for _, option := range expandOptions([]interface{}{carrier.option}) {
+		if err := x.apply(optionStruct, fieldMap, option); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fitAt fits optionValue into the element at the extractor's index of an
+// array or slice field.
+func (x *extractor) fitAt(field reflect.Value, sf reflect.StructField, optname string, optionValue reflect.Value) error {
+	index := x.index
+	// the element itself is fitted whole
+	x.indexed = false
+	defer func() { x.indexed = true }()
+
+	if field.Kind() != reflect.Array && field.Kind() != reflect.Slice {
+		return fmt.Errorf("failed to set %s at index %d, field %s is not an array or slice", optname, index, sf.Name)
+	}
+	if index < 0 || (field.Kind() == reflect.Array && index >= field.Len()) {
+		return fmt.Errorf("failed to set %s, index %d is out of range for field %s of length %d", optname, index, sf.Name, field.Len())
+	}
+
+	elem := reflect.New(field.Type().Elem()).Elem()
+	if index < field.Len() {
+		elem.Set(field.Index(index))
+	}
+	if err := x.fit(elem, sf, optname, optionValue); err != nil {
+		return err
+	}
+	if index >= field.Len() {
+		grown := reflect.MakeSlice(field.Type(), index+1, index+1)
+		reflect.Copy(grown, field)
+		field.Set(grown)
+	}
+	field.Index(index).Set(elem)
+	return nil
+}
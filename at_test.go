@@ -0,0 +1,51 @@
+package opts
+
+import (
+	"testing"
+)
+
+func TestAt(t *testing.T) {
+	opts := testatoptions{}
+	err := MustExtract(&opts,
+		At(0, WithCoord(1.5)),
+		At(2, WithCoord(-3)),
+		At(1, WithItem("b")),
+		At(3, WithItem("d")),
+	)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Coords != [3]float64{1.5, 0, -3} {
+		t.Fatalf("Coords should be [1.5 0 -3], got %v", opts.Coords)
+	}
+	// slices grow with zero elements
+	if len(opts.Items) != 4 || opts.Items[1] != "b" || opts.Items[3] != "d" || opts.Items[0] != "" {
+		t.Fatalf("Items should be [ b  d], got %q", opts.Items)
+	}
+
+	// other elements are left as they are
+	if err := MustExtract(&opts, At(0, WithItem("a"))); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(opts.Items) != 4 || opts.Items[0] != "a" || opts.Items[1] != "b" {
+		t.Fatalf("Items should be [a b  d], got %q", opts.Items)
+	}
+
+	if err := MustExtract(&opts, At(3, WithCoord(1))); err == nil {
+		t.Fatalf("MustExtract should have failed on an index beyond the array, but err is nil")
+	}
+	if err := MustExtract(&opts, At(-1, WithItem("z"))); err == nil {
+		t.Fatalf("MustExtract should have failed on a negative index, but err is nil")
+	}
+	if err := MustExtract(&opts, At(0, WithUsername("userbob"))); err == nil {
+		t.Fatalf("MustExtract should have failed indexing a field that isn't an array or slice, but err is nil")
+	}
+}
+
+type WithCoord float64
+
+type testatoptions struct {
+	Coords   [3]float64 `optname:"WithCoord"`
+	Items    []string   `optname:"WithItem"`
+	Username string     `optname:"WithUsername"`
+}
@@ -0,0 +1,101 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+)
+
+// ExtractAttrs extracts slog attributes into dest struct, matching each Key
+// against the optname tags. Keys not in dest are skipped.
+//
+// Values are resolved and fitted from Value.Any, so Int64, Uint64 and Float64
+// values convert into any numeric field they fit, and strings parse into bool
+// and numeric fields. A group whose key tags a struct field extracts into that
+// struct, while any other group extracts its attributes as if they weren't
+// grouped.
+func ExtractAttrs(dest interface{}, attrs ...slog.Attr) error {
+	return extractAttrs(dest, false, attrs)
+}
+
+// MustExtractAttrs extracts slog attributes into dest struct like ExtractAttrs.
+// Keys not in dest result in error.
+func MustExtractAttrs(dest interface{}, attrs ...slog.Attr) error {
+	return extractAttrs(dest, true, attrs)
+}
+
+// extractAttrs fits attrs into the struct dest points to.
+func extractAttrs(dest interface{}, mustFind bool, attrs []slog.Attr) error {
+	optionStruct, err := destStruct(dest)
+	if err != nil {
+		return err
+	}
+	x := &extractor{mustFind: mustFind, coerce: true, convertNumbers: true}
+	fieldMap, err := x.prepare(optionStruct)
+	if err != nil {
+		return err
+	}
+	if err := x.assignAttrs(optionStruct, attrs); err != nil {
+		return err
+	}
+	return x.finish(optionStruct, fieldMap)
+}
+
+// assignAttrs fits attrs into optionStruct.
+func (x *extractor) assignAttrs(optionStruct reflect.Value, attrs []slog.Attr) error {
+	fieldMap, err := x.mapFields(optionStruct.Type())
+	if err != nil {
+		return err
+	}
+
+	for _, attr := range attrs {
+		value := attr.Value.Resolve()
+		if value.Kind() != slog.KindGroup {
+			if err := x.assign(optionStruct, fieldMap, attr.Key, reflect.ValueOf(value.Any())); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// groups extract into the struct tagged with their key
+		field, found := x.lookup(fieldMap, attr.Key)
+		if !found {
+			if err := x.assignAttrs(optionStruct, value.Group()); err != nil {
+				return err
+			}
+			continue
+		}
+		fieldValue, _ := fieldByIndex(optionStruct, field.Index, true)
+		if fieldValue.Kind() == reflect.Ptr && fieldValue.Type().Elem().Kind() == reflect.Struct {
+			if fieldValue.IsNil() {
+				fieldValue.Set(reflect.New(fieldValue.Type().Elem()))
+			}
+			fieldValue = fieldValue.Elem()
+		}
+		if fieldValue.Kind() != reflect.Struct {
+			return fmt.Errorf("failed to set %s, group does not fit into field %s of kind %s", attr.Key, field.Name, fieldValue.Kind().String())
+		}
+		if err := x.assignAttrs(fieldValue, value.Group()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
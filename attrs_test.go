@@ -0,0 +1,65 @@
+package opts
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestExtractAttrs(t *testing.T) {
+	opts := testattroptions{}
+	err := ExtractAttrs(&opts,
+		slog.String("WithUsername", "userbob"),
+		slog.Int("WithPhoneNum", 8675309),
+		slog.Bool("WithBool", true),
+		slog.Duration("WithTimeout", 5*time.Second),
+		slog.Float64("WithRetries", 3),
+		slog.String("WithItem", "hello"),
+		slog.String("WithItem", "world"),
+		slog.Group("WithServer", slog.String("WithHost", "localhost"), slog.Int64("WithPort", 8080)),
+		slog.Group("flattened", slog.String("WithName", "inline")),
+		slog.String("WithUnknown", "skipped"),
+	)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Username != "userbob" || opts.PhoneNum != 8675309 || !opts.Boolean {
+		t.Fatalf("scalar attrs should have applied, got %+v", opts)
+	}
+	if opts.Timeout != 5*time.Second {
+		t.Fatalf("Timeout should be 5s, got %s", opts.Timeout)
+	}
+	if opts.Retries != 3 {
+		t.Fatalf("Retries should be 3, got %d", opts.Retries)
+	}
+	if len(opts.Items) != 2 {
+		t.Fatalf("Items should be [hello world], got %v", opts.Items)
+	}
+	if opts.Server.Host != "localhost" || opts.Server.Port != 8080 {
+		t.Fatalf("Server should be {localhost 8080}, got %+v", opts.Server)
+	}
+	if opts.Name != "inline" {
+		t.Fatalf("Name should be 'inline', got '%s'", opts.Name)
+	}
+
+	opts = testattroptions{}
+	if err := MustExtractAttrs(&opts, slog.String("WithUnknown", "x")); err == nil {
+		t.Fatalf("MustExtractAttrs should have failed on an unknown key, but err is nil")
+	}
+
+	opts = testattroptions{}
+	if err := ExtractAttrs(&opts, slog.Float64("WithRetries", 2.5)); err == nil {
+		t.Fatalf("ExtractAttrs should have failed fitting a fraction into an int, but err is nil")
+	}
+}
+
+type testattroptions struct {
+	Username string        `optname:"WithUsername"`
+	PhoneNum int           `optname:"WithPhoneNum"`
+	Boolean  bool          `optname:"WithBool"`
+	Timeout  time.Duration `optname:"WithTimeout"`
+	Retries  int8          `optname:"WithRetries"`
+	Items    []string      `optname:"WithItem"`
+	Name     string        `optname:"WithName"`
+	Server   *testserver   `optname:"WithServer"`
+}
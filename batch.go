@@ -0,0 +1,82 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// ExtractJob is one extraction of a batch, of Options into Dest.
+type ExtractJob struct {
+	Dest    interface{}
+	Options []interface{}
+}
+
+// ExtractBatch runs the jobs concurrently and returns the error of every job,
+// by index, with nil for jobs that succeeded. Options not in a job's Dest are
+// skipped. The jobs must not share a Dest.
+func ExtractBatch(jobs []ExtractJob) []error {
+	return ExtractBatchContext(context.Background(), jobs, false)
+}
+
+// ExtractBatchContext runs the jobs concurrently under ctx and returns the
+// error of every job, by index, with nil for jobs that succeeded. Options not
+// in a job's Dest are skipped. The jobs must not share a Dest.
+//
+// Jobs run on at most GOMAXPROCS goroutines. A running job checks ctx before
+// each option and stops with its error once ctx is done, and jobs that haven't
+// started by then fail with the error without starting. With cancelOnError the
+// first job to fail cancels the rest this way, for a fail fast start up when
+// one bad config makes the whole batch pointless.
+func ExtractBatchContext(ctx context.Context, jobs []ExtractJob, cancelOnError bool) []error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errs := make([]error, len(jobs))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	workers := runtime.GOMAXPROCS(0)
+	if workers > len(jobs) {
+		workers = len(jobs)
+	}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				if err := ctx.Err(); err != nil {
+					errs[i] = err
+					continue
+				}
+				errs[i] = (&extractor{ctx: ctx}).extract(jobs[i].Dest, jobs[i].Options...)
+				if errs[i] != nil && cancelOnError {
+					cancel()
+				}
+			}
+		}()
+	}
+	for i := range jobs {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return errs
+}
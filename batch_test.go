@@ -0,0 +1,50 @@
+package opts
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+)
+
+func TestExtractBatch(t *testing.T) {
+	dests := make([]testoptions, 2)
+	jobs := []ExtractJob{
+		{Dest: &dests[0], Options: []interface{}{WithUsername("userbob")}},
+		{Dest: &dests[1], Options: []interface{}{WithPtrString(nil), WithPhoneNum(5)}},
+		{Dest: "not a struct", Options: []interface{}{WithUsername("useralice")}},
+	}
+	errs := ExtractBatch(jobs)
+	if len(errs) != 3 || errs[0] != nil || errs[1] != nil || errs[2] == nil {
+		t.Fatalf("only the job with a string dest should fail, got %v", errs)
+	}
+	if dests[0].Username != "userbob" || dests[1].PhoneNum != 5 {
+		t.Fatalf("jobs should have applied, got %+v", dests)
+	}
+}
+
+func TestExtractBatchContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	errs := ExtractBatchContext(ctx, []ExtractJob{{Dest: &testoptions{}, Options: []interface{}{WithUsername("userbob")}}}, false)
+	if !errors.Is(errs[0], context.Canceled) {
+		t.Fatalf("err should be context.Canceled, got %v", errs[0])
+	}
+
+	// the first failure cancels the jobs after it, which on a single
+	// goroutine haven't started
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(1))
+	jobs := []ExtractJob{{Dest: "not a struct"}}
+	for i := 0; i < 100; i++ {
+		jobs = append(jobs, ExtractJob{Dest: &testoptions{}, Options: []interface{}{WithUsername("userbob")}})
+	}
+	errs = ExtractBatchContext(context.Background(), jobs, true)
+	if errs[0] == nil {
+		t.Fatalf("the first job should have failed, but err is nil")
+	}
+	for i, err := range errs[1:] {
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("job %d should have been canceled, got %v", i+1, err)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ByteBudgetExceededError is returned when the strings and slices assigned
+// during an extraction would exceed its byte budget.
+type ByteBudgetExceededError struct {
+	OptName string
+	Limit   int
+	Total   int
+}
+
+func (e *ByteBudgetExceededError) Error() string {
+	return fmt.Sprintf("option %s exceeds the byte budget, %d bytes but the limit is %d", e.OptName, e.Total, e.Limit)
+}
+
+// ExtractWithByteBudget extracts options into dest struct, capping the total
+// size of the string and slice options assigned at maxBytes, so bulk input
+// from untrusted callers can't make an extraction hold unbounded memory. It
+// complements maxlen, which bounds fields one at a time. Options not in dest
+// are skipped and don't count.
+//
+// A string counts its length in bytes of UTF-8, and a slice its number of
+// elements times the size of an element, so a []string of n elements counts
+// n string headers rather than the bytes they point to. Other options count
+// nothing. The option that would take the total past maxBytes isn't assigned
+// and results in a *ByteBudgetExceededError, but the options before it have
+// been, so dest may be partially extracted.
+func ExtractWithByteBudget(dest interface{}, maxBytes int, options ...interface{}) error {
+	return (&extractor{byteBudget: maxBytes, budgeted: true}).extract(dest, options...)
+}
+
+// spendBytes counts the bytes of an option about to be assigned against the
+// byte budget, refusing it when the budget runs out.
+func (x *extractor) spendBytes(optname string, optionValue reflect.Value) error {
+	if !x.budgeted {
+		return nil
+	}
+	var n int
+	switch optionValue.Kind() {
+	case reflect.String:
+		n = optionValue.Len()
+	case reflect.Slice:
+		n = optionValue.Len() * int(optionValue.Type().Elem().Size())
+	}
+	if x.bytesSpent+n > x.byteBudget {
+		return &ByteBudgetExceededError{OptName: optname, Limit: x.byteBudget, Total: x.bytesSpent + n}
+	}
+	x.bytesSpent += n
+	return nil
+}
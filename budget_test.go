@@ -0,0 +1,37 @@
+package opts
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestExtractWithByteBudget(t *testing.T) {
+	opts := testoptions{}
+	// 7 bytes of username, 3 of an item and 2 string headers of a list
+	err := ExtractWithByteBudget(&opts, 42, WithUsername("userbob"), WithItem("one"), WithList{"a", "b"}, WithPhoneNum(8675309))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Username != "userbob" || len(opts.List) != 2 || opts.PhoneNum != 8675309 {
+		t.Fatalf("options should have applied, got %+v", opts)
+	}
+
+	// the option crossing the budget isn't assigned, those before it are
+	opts = testoptions{}
+	var overBudget *ByteBudgetExceededError
+	err = ExtractWithByteBudget(&opts, 8, WithItem("four"), WithItem("four"), WithItem("four"), WithHost("not counted"))
+	if !errors.As(err, &overBudget) {
+		t.Fatalf("err should be a *ByteBudgetExceededError, got %v", err)
+	}
+	if overBudget.OptName != "WithItem" || overBudget.Limit != 8 || overBudget.Total != 12 {
+		t.Fatalf("overBudget should be {WithItem 8 12}, got %+v", overBudget)
+	}
+	if len(opts.Items) != 2 {
+		t.Fatalf("Items should hold the 2 items within budget, got %v", opts.Items)
+	}
+
+	// multi-byte characters count their UTF-8 bytes
+	if err := ExtractWithByteBudget(&testoptions{}, 5, WithUsername("héllo")); !errors.As(err, &overBudget) {
+		t.Fatalf("err should be a *ByteBudgetExceededError, got %v", err)
+	}
+}
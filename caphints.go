@@ -0,0 +1,55 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"reflect"
+)
+
+// ExtractWithCapHints extracts options into dest struct, growing slice fields
+// to the capacity hinted for them before the first option is appended, so bulk
+// loads of many elements don't reallocate over and over. hints maps the
+// optname or the name of a field to the number of elements expected, with the
+// optname taking precedence when both are present.
+//
+// The hints are purely advisory. A field hinted too low grows as it would
+// without a hint, and one hinted too high holds the unused capacity, so a
+// wrong hint never changes what is extracted. Hints for fields that aren't
+// slices, or that no option is assigned into, are ignored. Options not in dest
+// are skipped.
+func ExtractWithCapHints(dest interface{}, hints map[string]int, options ...interface{}) error {
+	return (&extractor{capHints: hints}).extract(dest, options...)
+}
+
+// growToHint grows slice field to the capacity hinted for it.
+func (x *extractor) growToHint(field reflect.Value, sf reflect.StructField, optname string) {
+	if x.capHints == nil || field.Kind() != reflect.Slice {
+		return
+	}
+	hint, found := x.capHints[optname]
+	if !found {
+		hint = x.capHints[sf.Name]
+	}
+	if field.Cap() >= hint {
+		return
+	}
+	grown := reflect.MakeSlice(field.Type(), field.Len(), hint)
+	reflect.Copy(grown, field)
+	field.Set(grown)
+}
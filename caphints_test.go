@@ -0,0 +1,60 @@
+package opts
+
+import (
+	"testing"
+)
+
+func TestExtractWithCapHints(t *testing.T) {
+	opts := testoptions{}
+	err := ExtractWithCapHints(&opts, map[string]int{"WithItem": 64, "List": 8, "Username": 4},
+		WithItem("a"), WithItem("b"), WithList{"x"}, WithUsername("userbob"))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(opts.Items) != 2 || opts.Items[0] != "a" || opts.Items[1] != "b" {
+		t.Fatalf("Items should be [a b], got %v", opts.Items)
+	}
+	if cap(opts.Items) < 64 {
+		t.Fatalf("Items should have been grown to 64, got %d", cap(opts.Items))
+	}
+	if len(opts.List) != 1 || opts.List[0] != "x" {
+		t.Fatalf("List should be [x], got %v", opts.List)
+	}
+
+	// hinting too low is safe
+	opts = testoptions{}
+	options := make([]interface{}, 100)
+	for i := range options {
+		options[i] = WithItem("a")
+	}
+	if err := ExtractWithCapHints(&opts, map[string]int{"Items": 2}, options...); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(opts.Items) != 100 {
+		t.Fatalf("Items should have 100 elements, got %d", len(opts.Items))
+	}
+}
+
+func BenchmarkExtractWithCapHints(b *testing.B) {
+	options := make([]interface{}, 10000)
+	for i := range options {
+		options[i] = WithItem("item")
+	}
+	b.Run("Extract", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			opts := testoptions{}
+			if err := Extract(&opts, options...); err != nil {
+				b.Fatalf("%s", err)
+			}
+		}
+	})
+	b.Run("ExtractWithCapHints", func(b *testing.B) {
+		hints := map[string]int{"WithItem": len(options)}
+		for i := 0; i < b.N; i++ {
+			opts := testoptions{}
+			if err := ExtractWithCapHints(&opts, hints, options...); err != nil {
+				b.Fatalf("%s", err)
+			}
+		}
+	})
+}
@@ -0,0 +1,76 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"strings"
+	"unicode"
+)
+
+// ExtractWithCaseConversion extracts options into dest struct, also matching
+// options against untagged fields by name. Option names and field names are
+// both normalized to snake case with any leading "with" dropped, so the
+// options WithPort, with_port and with-port all match a field named Port.
+// Options not in dest are skipped.
+//
+// Fields with an optname tag bypass the normalization and are only matched by
+// their exact tag.
+func ExtractWithCaseConversion(dest interface{}, options ...interface{}) error {
+	return (&extractor{caseConvert: true}).extract(dest, options...)
+}
+
+// caseKey normalizes a field or option name for case converted matching.
+func caseKey(name string) string {
+	parts := words(name)
+	if len(parts) > 1 && parts[0] == "with" {
+		parts = parts[1:]
+	}
+	return strings.Join(parts, "_")
+}
+
+// words splits camel, pascal, snake and kebab case names into lower case
+// words. Runs of capitals are kept together as an acronym, so WithTLSCert is
+// with, tls and cert.
+func words(name string) []string {
+	var parts []string
+	var word []rune
+	runes := []rune(name)
+	flush := func() {
+		if len(word) > 0 {
+			parts = append(parts, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+	}
+	for i, r := range runes {
+		switch {
+		case r == '_' || r == '-' || r == '.' || unicode.IsSpace(r):
+			flush()
+			continue
+		case unicode.IsUpper(r) && i > 0:
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				flush()
+			}
+		}
+		word = append(word, r)
+	}
+	flush()
+	return parts
+}
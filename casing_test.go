@@ -0,0 +1,66 @@
+package opts
+
+import (
+	"testing"
+)
+
+func TestCaseKey(t *testing.T) {
+	tests := []struct {
+		in, key string
+	}{
+		{"WithPort", "port"},
+		{"WithTLSCert", "tls_cert"},
+		{"with_port", "port"},
+		{"max-conns", "max_conns"},
+		{"HTTP2Server", "http2_server"},
+		{"With", "with"},
+	}
+	for _, test := range tests {
+		if got := caseKey(test.in); got != test.key {
+			t.Fatalf("caseKey(%s) should be %s, got %s", test.in, test.key, got)
+		}
+	}
+}
+
+func TestExtractWithCaseConversion(t *testing.T) {
+	opts := testcaseoptions{}
+	err := ExtractWithCaseConversion(&opts,
+		WithPort(8080),
+		namedOption{name: "with_max_conns", value: 10},
+		namedOption{name: "with-host-name", value: "localhost"},
+		namedOption{name: "with_username", value: "ignored"},
+		WithUsername("userbob"),
+	)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Port != 8080 {
+		t.Fatalf("Port should be 8080, got %d", opts.Port)
+	}
+	if opts.MaxConns != 10 {
+		t.Fatalf("MaxConns should be 10, got %d", opts.MaxConns)
+	}
+	if opts.HostName != "localhost" {
+		t.Fatalf("HostName should be 'localhost', got '%s'", opts.HostName)
+	}
+	// tagged fields only match exactly
+	if opts.User != "userbob" {
+		t.Fatalf("User should be 'userbob', got '%s'", opts.User)
+	}
+
+	// without case conversion untagged fields are left alone
+	opts = testcaseoptions{}
+	if err := Extract(&opts, WithPort(8080)); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Port != 0 {
+		t.Fatalf("Port should not have been set, got %d", opts.Port)
+	}
+}
+
+type testcaseoptions struct {
+	Port     int
+	MaxConns int
+	HostName string
+	User     string `optname:"WithUsername"`
+}
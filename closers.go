@@ -0,0 +1,70 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"io"
+	"reflect"
+)
+
+// closerType is the reflect.Type of io.Closer.
+var closerType = reflect.TypeOf((*io.Closer)(nil)).Elem()
+
+// ExtractWithClosers extracts options into dest struct and returns every value
+// it set that implements io.Closer, so the caller can register cleanup for the
+// resources options opened. Options not in dest are skipped.
+//
+// A field counts once for each option assigned into it. For slice fields the
+// elements an option added are returned, or every element when an option
+// replaced the slice. Closers are returned in the order they were assigned, so
+// close them in reverse to release resources last in, first out. On error the
+// closers assigned so far are returned along with it, for rolling back.
+func ExtractWithClosers(dest interface{}, options ...interface{}) ([]io.Closer, error) {
+	var closers []io.Closer
+	x := &extractor{}
+	x.afterAssign = func(optname string, field, previous, optionValue reflect.Value) {
+		if field.Kind() != reflect.Slice {
+			closers = appendCloser(closers, field)
+			return
+		}
+		from := previous.Len()
+		if optionValue.Kind() == reflect.Slice {
+			from = 0
+		}
+		for i := from; i < field.Len(); i++ {
+			closers = appendCloser(closers, field.Index(i))
+		}
+	}
+	err := x.extract(dest, options...)
+	return closers, err
+}
+
+// appendCloser appends v to closers when it holds a non-nil io.Closer.
+func appendCloser(closers []io.Closer, v reflect.Value) []io.Closer {
+	if !v.Type().Implements(closerType) {
+		return closers
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		if v.IsNil() {
+			return closers
+		}
+	}
+	return append(closers, v.Interface().(io.Closer))
+}
@@ -0,0 +1,51 @@
+package opts
+
+import (
+	"testing"
+)
+
+func TestExtractWithClosers(t *testing.T) {
+	db, cache, log := &testcloser{}, &testcloser{}, &testcloser{}
+
+	opts := testcloseroptions{}
+	closers, err := ExtractWithClosers(&opts,
+		WithDatabase(db),
+		WithCache(cache),
+		WithCache(log),
+		WithUsername("userbob"),
+	)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(closers) != 3 || closers[0] != db || closers[1] != cache || closers[2] != log {
+		t.Fatalf("closers should be [db cache log], got %v", closers)
+	}
+
+	// closers set before the error are returned for rolling back
+	closers, err = ExtractWithClosers(&testcloseroptions{}, WithDatabase(db), WithPhoneNum(5))
+	if err == nil {
+		t.Fatalf("ExtractWithClosers should have failed fitting WithPhoneNum, but err is nil")
+	}
+	if len(closers) != 1 || closers[0] != db {
+		t.Fatalf("closers should be [db], got %v", closers)
+	}
+}
+
+type testcloser struct {
+	closed bool
+}
+
+func (c *testcloser) Close() error {
+	c.closed = true
+	return nil
+}
+
+type WithDatabase *testcloser
+type WithCache *testcloser
+
+type testcloseroptions struct {
+	Database *testcloser   `optname:"WithDatabase"`
+	Caches   []*testcloser `optname:"WithCache"`
+	Username string        `optname:"WithUsername"`
+	PhoneNum []bool        `optname:"WithPhoneNum"`
+}
@@ -0,0 +1,167 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// NumberFormat describes how numbers are written in string options, for
+// configuration authored outside of the "1,234.56" convention.
+type NumberFormat struct {
+	// Decimal separates the integer and fractional parts. Zero means '.'.
+	Decimal rune
+	// Grouping separates groups of digits, such as thousands. Zero means
+	// numbers are not grouped.
+	Grouping rune
+}
+
+// ExtractWithCoercion extracts options into dest struct, parsing string options
+// into bool and numeric fields and formatting bool and numeric options into
+// string fields when their kinds don't otherwise fit. Options not in dest are
+// skipped.
+func ExtractWithCoercion(dest interface{}, options ...interface{}) error {
+	return (&extractor{coerce: true}).extract(dest, options...)
+}
+
+// ExtractWithNumberFormat extracts options into dest struct like
+// ExtractWithCoercion, but parses numeric strings written in format. For
+// example a format with Decimal ',' and Grouping '.' parses "1.234,56" as
+// 1234.56. Options not in dest are skipped.
+func ExtractWithNumberFormat(dest interface{}, format NumberFormat, options ...interface{}) error {
+	return (&extractor{coerce: true, numberFormat: format}).extract(dest, options...)
+}
+
+// ExtractWithCoercionTable extracts options into dest struct, converting an
+// option into a field of another kind only when allowed holds true for the
+// pair of their kinds, as in [2]reflect.Kind{reflect.String, reflect.Int}. The
+// conversions are those of ExtractWithCoercion and of converting between
+// numeric kinds, and a pair missing from allowed results in a fit error even
+// when the conversion would succeed. Options whose kind matches their field
+// are always assigned, whatever the table, so a nil table permits exact
+// matches only. Options not in dest are skipped.
+func ExtractWithCoercionTable(dest interface{}, allowed map[[2]reflect.Kind]bool, options ...interface{}) error {
+	if allowed == nil {
+		allowed = map[[2]reflect.Kind]bool{}
+	}
+	return (&extractor{coercions: allowed}).extract(dest, options...)
+}
+
+// convert converts optionValue to t with the conversions enabled on the
+// extractor. ok reports whether any of them applied.
+func (x *extractor) convert(t reflect.Type, sf reflect.StructField, optname string, optionValue reflect.Value) (fitted reflect.Value, ok bool, err error) {
+	if x.coercions != nil {
+		if !x.coercions[[2]reflect.Kind{optionValue.Kind(), t.Kind()}] {
+			return reflect.Value{}, false, nil
+		}
+		if fitted, ok, err := convertNumber(t, sf, optname, optionValue); ok {
+			return fitted, ok, err
+		}
+		return x.coerceValue(t, sf, optname, optionValue)
+	}
+	if x.convertNumbers {
+		if fitted, ok, err := convertNumber(t, sf, optname, optionValue); ok {
+			return fitted, ok, err
+		}
+	}
+	if x.coerce {
+		return x.coerceValue(t, sf, optname, optionValue)
+	}
+	return reflect.Value{}, false, nil
+}
+
+// coerceValue converts optionValue to t by parsing or formatting a string. ok
+// reports whether a coercion between the two kinds exists.
+func (x *extractor) coerceValue(t reflect.Type, sf reflect.StructField, optname string, optionValue reflect.Value) (fitted reflect.Value, ok bool, err error) {
+	fitted = reflect.New(t).Elem()
+
+	// format scalars into strings
+	if t.Kind() == reflect.String {
+		var formatted string
+		switch optionValue.Kind() {
+		case reflect.Bool:
+			formatted = strconv.FormatBool(optionValue.Bool())
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			formatted = strconv.FormatInt(optionValue.Int(), 10)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			formatted = strconv.FormatUint(optionValue.Uint(), 10)
+		case reflect.Float32, reflect.Float64:
+			formatted = strconv.FormatFloat(optionValue.Float(), 'g', -1, optionValue.Type().Bits())
+		default:
+			return fitted, false, nil
+		}
+		fitted.SetString(formatted)
+		return fitted, true, nil
+	}
+
+	// parse strings into scalars
+	if optionValue.Kind() != reflect.String {
+		return fitted, false, nil
+	}
+	in := optionValue.String()
+	switch t.Kind() {
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(in)
+		if err != nil {
+			return fitted, true, parseError(optname, sf, err)
+		}
+		fitted.SetBool(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(x.numberFormat.normalize(in), 10, t.Bits())
+		if err != nil {
+			return fitted, true, parseError(optname, sf, err)
+		}
+		fitted.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		parsed, err := strconv.ParseUint(x.numberFormat.normalize(in), 10, t.Bits())
+		if err != nil {
+			return fitted, true, parseError(optname, sf, err)
+		}
+		fitted.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(x.numberFormat.normalize(in), t.Bits())
+		if err != nil {
+			return fitted, true, parseError(optname, sf, err)
+		}
+		fitted.SetFloat(parsed)
+	default:
+		return fitted, false, nil
+	}
+	return fitted, true, nil
+}
+
+// normalize rewrites a number written in the format into the form strconv
+// parses. The zero format leaves the number untouched.
+func (format NumberFormat) normalize(in string) string {
+	if format.Grouping != 0 {
+		in = strings.ReplaceAll(in, string(format.Grouping), "")
+	}
+	if format.Decimal != 0 && format.Decimal != '.' {
+		in = strings.ReplaceAll(in, string(format.Decimal), ".")
+	}
+	return in
+}
+
+// parseError reports a string option that could not be parsed for a field.
+func parseError(optname string, sf reflect.StructField, err error) error {
+	return fmt.Errorf("failed to set %s, could not parse for field %s: %s", optname, sf.Name, err)
+}
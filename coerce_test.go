@@ -0,0 +1,149 @@
+package opts
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtractWithCoercion(t *testing.T) {
+	opts := testcoerceoptions{}
+	err := ExtractWithCoercion(&opts,
+		WithCount("42"),
+		WithRatio("0.5"),
+		WithEnabled("true"),
+		WithName(7),
+		WithPorts("80"),
+		WithPorts("443"),
+	)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Count != 42 {
+		t.Fatalf("Count should be 42, got %d", opts.Count)
+	}
+	if opts.Ratio != 0.5 {
+		t.Fatalf("Ratio should be 0.5, got %f", opts.Ratio)
+	}
+	if !opts.Enabled {
+		t.Fatalf("Enabled should be true")
+	}
+	if opts.Name != "7" {
+		t.Fatalf("Name should be '7', got '%s'", opts.Name)
+	}
+	if len(opts.Ports) != 2 || opts.Ports[0] != 80 || opts.Ports[1] != 443 {
+		t.Fatalf("Ports should be [80 443], got %v", opts.Ports)
+	}
+
+	// without coercion the kinds don't fit
+	opts = testcoerceoptions{}
+	if err := Extract(&opts, WithCount("42")); err == nil {
+		t.Fatalf("Extract should have failed without coercion, but err is nil")
+	}
+
+	opts = testcoerceoptions{}
+	err = ExtractWithCoercion(&opts, WithCount("forty-two"))
+	if err == nil {
+		t.Fatalf("ExtractWithCoercion should have failed to parse, but err is nil")
+	}
+	if !strings.Contains(err.Error(), "Count") {
+		t.Fatalf("error should name the field Count, got '%s'", err)
+	}
+}
+
+func TestExtractWithNumberFormat(t *testing.T) {
+	european := NumberFormat{Decimal: ',', Grouping: '.'}
+
+	opts := testcoerceoptions{}
+	err := ExtractWithNumberFormat(&opts, european, WithRatio("1.234,56"), WithCount("1.000.000"))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Ratio != 1234.56 {
+		t.Fatalf("Ratio should be 1234.56, got %f", opts.Ratio)
+	}
+	if opts.Count != 1000000 {
+		t.Fatalf("Count should be 1000000, got %d", opts.Count)
+	}
+
+	// the zero format parses like strconv
+	opts = testcoerceoptions{}
+	err = ExtractWithNumberFormat(&opts, NumberFormat{}, WithRatio("1234.56"))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Ratio != 1234.56 {
+		t.Fatalf("Ratio should be 1234.56, got %f", opts.Ratio)
+	}
+
+	opts = testcoerceoptions{}
+	err = ExtractWithNumberFormat(&opts, european, WithRatio("1,2,3"))
+	if err == nil {
+		t.Fatalf("ExtractWithNumberFormat should have failed to parse, but err is nil")
+	}
+	if !strings.Contains(err.Error(), "Ratio") {
+		t.Fatalf("error should name the field Ratio, got '%s'", err)
+	}
+}
+
+func TestExtractWithCoercionTable(t *testing.T) {
+	allowed := map[[2]reflect.Kind]bool{
+		{reflect.String, reflect.Int}: true,
+		{reflect.Int, reflect.String}: true,
+	}
+	opts := testcoerceoptions{}
+	err := ExtractWithCoercionTable(&opts, allowed, WithCount("42"), WithName(7), WithPorts("80"))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Count != 42 || opts.Name != "7" || len(opts.Ports) != 1 || opts.Ports[0] != 80 {
+		t.Fatalf("allowed conversions should have applied, got %+v", opts)
+	}
+
+	// string to float64 isn't in the table
+	if err := ExtractWithCoercionTable(&opts, allowed, WithRatio("0.5")); err == nil {
+		t.Fatalf("ExtractWithCoercionTable should have failed on a conversion not in the table, but err is nil")
+	}
+	// numbers convert only when allowed too
+	if err := ExtractWithCoercionTable(&testtableoptions{}, allowed, WithInt16(1)); err == nil {
+		t.Fatalf("ExtractWithCoercionTable should have failed converting int16 into int, but err is nil")
+	}
+	numbers := map[[2]reflect.Kind]bool{{reflect.Int16, reflect.Int}: true}
+	table := testtableoptions{}
+	if err := ExtractWithCoercionTable(&table, numbers, WithInt16(12)); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if table.Value != 12 {
+		t.Fatalf("Value should be 12, got %d", table.Value)
+	}
+
+	// exact matches need no entry
+	table = testtableoptions{}
+	if err := ExtractWithCoercionTable(&table, nil, WithUsername("userbob")); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if table.Username != "userbob" {
+		t.Fatalf("Username should be 'userbob', got '%s'", table.Username)
+	}
+}
+
+type WithInt16 int16
+
+type testtableoptions struct {
+	Value    int    `optname:"WithInt16"`
+	Username string `optname:"WithUsername"`
+}
+
+type WithCount string
+type WithRatio string
+type WithEnabled string
+type WithName int
+type WithPorts string
+
+type testcoerceoptions struct {
+	Count   int     `optname:"WithCount"`
+	Ratio   float64 `optname:"WithRatio"`
+	Enabled bool    `optname:"WithEnabled"`
+	Name    string  `optname:"WithName"`
+	Ports   []int   `optname:"WithPorts"`
+}
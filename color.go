@@ -0,0 +1,69 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"fmt"
+	"image/color"
+	"reflect"
+	"strconv"
+)
+
+var (
+	rgbaType  = reflect.TypeOf(color.RGBA{})
+	nrgbaType = reflect.TypeOf(color.NRGBA{})
+)
+
+// takesColor reports whether field is a color.RGBA or a color.NRGBA.
+func takesColor(field reflect.Value) bool {
+	return field.Type() == rgbaType || field.Type() == nrgbaType
+}
+
+// fitColor parses a hex color written as #rgb, #rrggbb or #rrggbbaa into a
+// color.RGBA or color.NRGBA field. Colors without alpha are opaque. The
+// components of a color.RGBA are alpha premultiplied, so a translucent hex
+// color is scaled by its alpha for one.
+func fitColor(field reflect.Value, sf reflect.StructField, optname, hex string) error {
+	invalid := fmt.Errorf("failed to set %s, %q is not a #rgb, #rrggbb or #rrggbbaa color for field %s", optname, hex, sf.Name)
+	if len(hex) == 0 || hex[0] != '#' {
+		return invalid
+	}
+	digits := hex[1:]
+	if len(digits) == 3 {
+		digits = string([]byte{digits[0], digits[0], digits[1], digits[1], digits[2], digits[2]})
+	}
+	if len(digits) == 6 {
+		digits += "ff"
+	}
+	if len(digits) != 8 {
+		return invalid
+	}
+	n, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil {
+		return invalid
+	}
+
+	c := color.NRGBA{R: uint8(n >> 24), G: uint8(n >> 16), B: uint8(n >> 8), A: uint8(n)}
+	if field.Type() == rgbaType {
+		field.Set(reflect.ValueOf(color.RGBAModel.Convert(c)))
+		return nil
+	}
+	field.Set(reflect.ValueOf(c))
+	return nil
+}
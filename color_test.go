@@ -0,0 +1,49 @@
+package opts
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestColorFields(t *testing.T) {
+	tests := []struct {
+		hex  string
+		want color.NRGBA
+	}{
+		{"#f00", color.NRGBA{R: 0xff, A: 0xff}},
+		{"#12ab9c", color.NRGBA{R: 0x12, G: 0xab, B: 0x9c, A: 0xff}},
+		{"#11223380", color.NRGBA{R: 0x11, G: 0x22, B: 0x33, A: 0x80}},
+	}
+	for _, test := range tests {
+		opts := testcoloroptions{}
+		if err := MustExtract(&opts, WithForeground(test.hex)); err != nil {
+			t.Fatalf("%s", err)
+		}
+		if opts.Foreground != test.want {
+			t.Fatalf("Foreground of %s should be %v, got %v", test.hex, test.want, opts.Foreground)
+		}
+	}
+
+	// RGBA holds premultiplied components
+	opts := testcoloroptions{}
+	if err := MustExtract(&opts, WithBackground("#ff000080")); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if want := (color.RGBA{R: 0x80, A: 0x80}); opts.Background != want {
+		t.Fatalf("Background should be %v, got %v", want, opts.Background)
+	}
+
+	for _, hex := range []string{"ff0000", "#ff00", "#gg0000", ""} {
+		if err := MustExtract(&testcoloroptions{}, WithBackground(hex)); err == nil {
+			t.Fatalf("MustExtract should have failed on %q, but err is nil", hex)
+		}
+	}
+}
+
+type WithForeground string
+type WithBackground string
+
+type testcoloroptions struct {
+	Foreground color.NRGBA `optname:"WithForeground"`
+	Background color.RGBA  `optname:"WithBackground"`
+}
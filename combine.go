@@ -0,0 +1,60 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+// composite is a bundle of options that extracts as its constituents.
+type composite []interface{}
+
+// Combine bundles options into a single option. When extracted, the bundle
+// expands into its options in order, as if they had been passed in its place.
+// This lets a package export one helper, such as WithDefaults(), that fans out
+// into many options.
+//
+// Bundles may contain other bundles to any depth. They are expanded depth
+// first, so the final order of options is the order in which they were written.
+func Combine(options ...interface{}) interface{} {
+	bundled := make(composite, len(options))
+	copy(bundled, options)
+	return bundled
+}
+
+// expandOptions replaces every composite in options with its constituents.
+func expandOptions(options []interface{}) []interface{} {
+	// avoid copying in the common case of no composites
+	found := false
+	for _, option := range options {
+		if _, ok := option.(composite); ok {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return options
+	}
+
+	expanded := make([]interface{}, 0, len(options))
+	for _, option := range options {
+		if bundled, ok := option.(composite); ok {
+			expanded = append(expanded, expandOptions(bundled)...)
+			continue
+		}
+		expanded = append(expanded, option)
+	}
+	return expanded
+}
@@ -0,0 +1,43 @@
+package opts
+
+import (
+	"testing"
+)
+
+func TestCombine(t *testing.T) {
+	withDefaults := func() interface{} {
+		return Combine(
+			WithUsername("default"),
+			WithItem("first"),
+			Combine(WithItem("second"), WithPhoneNum(8675309)),
+		)
+	}
+
+	opts := testoptions{}
+	err := MustExtract(&opts, withDefaults(), WithItem("third"), WithUsername("userbob"))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	if opts.Username != "userbob" {
+		t.Fatalf("Username should be 'userbob', got '%s'", opts.Username)
+	}
+	if opts.PhoneNum != 8675309 {
+		t.Fatalf("PhoneNum should be 8675309, got %d", opts.PhoneNum)
+	}
+	expected := []string{"first", "second", "third"}
+	if len(opts.Items) != len(expected) {
+		t.Fatalf("Items should be %v, got %v", expected, opts.Items)
+	}
+	for i := range expected {
+		if opts.Items[i] != expected[i] {
+			t.Fatalf("Items should be %v, got %v", expected, opts.Items)
+		}
+	}
+
+	opts = testoptions{}
+	err = MustExtract(&opts, Combine(WithInvalidOption(true)))
+	if err == nil {
+		t.Fatalf("MustExtract should have failed on an invalid option inside a bundle, but err is nil")
+	}
+}
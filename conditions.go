@@ -0,0 +1,150 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// condition is a comparison of a field against a literal, such as the
+// Mode==advanced in requiredif:"Mode==advanced".
+type condition struct {
+	field string
+	equal bool
+	value string
+}
+
+// parseCondition parses Field==value or Field!=value. Field names a field of
+// the struct declaring the tag, and value is compared with the field's value
+// formatted by fmt.
+func parseCondition(tag string) (condition, error) {
+	for _, op := range []string{"==", "!="} {
+		if field, value, found := strings.Cut(tag, op); found {
+			field = strings.TrimSpace(field)
+			if field == "" {
+				return condition{}, fmt.Errorf("condition %q has no field", tag)
+			}
+			return condition{field: field, equal: op == "==", value: strings.TrimSpace(value)}, nil
+		}
+	}
+	return condition{}, fmt.Errorf("condition %q needs == or !=", tag)
+}
+
+// holds evaluates the condition against the struct that declares the tag.
+func (c condition) holds(parent reflect.Value) (bool, error) {
+	fieldValue := parent.FieldByName(c.field)
+	if !fieldValue.IsValid() {
+		return false, fmt.Errorf("condition refers to unknown field %s", c.field)
+	}
+	if fieldValue.Kind() == reflect.Ptr {
+		if fieldValue.IsNil() {
+			return !c.equal, nil
+		}
+		fieldValue = fieldValue.Elem()
+	}
+	return (fmt.Sprint(fieldValue.Interface()) == c.value) == c.equal, nil
+}
+
+// checkConditions enforces the conditional requirements of tagged fields once
+// options have been assigned. A field tagged requiredif:"Mode==advanced" must
+// have been set by an option whenever the Mode field of the same struct holds
+// advanced. A field tagged requiredunless:"FromFile" must have been set by an
+// option unless the FromFile field of the same struct was, and one tagged
+// requiredunless:"FromFile,FromEnv" unless any of those fields was. Each tag
+// is checked on its own, so a field with both must satisfy both. Every
+// violated requirement is reported.
+func (x *extractor) checkConditions(optionStruct reflect.Value, fieldMap map[string]reflect.StructField) error {
+	var errs []error
+	for _, optname := range orderedFields(fieldMap) {
+		field := fieldMap[optname]
+		ifTag, hasIf := field.Tag.Lookup("requiredif")
+		unlessTag, hasUnless := field.Tag.Lookup("requiredunless")
+		if !hasIf && !hasUnless {
+			continue
+		}
+
+		parent, ok := conditionParent(optionStruct, field)
+		if !ok {
+			continue
+		}
+		fieldValue, _ := fieldByIndex(optionStruct, field.Index, false)
+		set := x.isSet(fieldValue)
+
+		if hasIf {
+			cond, err := parseCondition(ifTag)
+			if err != nil {
+				return fmt.Errorf("field %s: %s", field.Name, err)
+			}
+			cond.field = x.remapField(cond.field)
+			holds, err := cond.holds(parent)
+			if err != nil {
+				return fmt.Errorf("field %s: %s", field.Name, err)
+			}
+			if holds && !set {
+				errs = append(errs, fmt.Errorf("option %s is required when %s", optname, ifTag))
+			}
+		}
+		if hasUnless {
+			required, err := x.requiredUnless(parent, unlessTag)
+			if err != nil {
+				return fmt.Errorf("field %s: %s", field.Name, err)
+			}
+			if required && !set {
+				errs = append(errs, fmt.Errorf("option %s is required unless %s is set", optname, unlessTag))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// conditionParent returns the struct declaring field, which both requiredif
+// and requiredunless tags are evaluated against. It reports false when the
+// struct is behind a nil pointer, as no option reached its fields.
+func conditionParent(optionStruct reflect.Value, field reflect.StructField) (reflect.Value, bool) {
+	parent, ok := fieldByIndex(optionStruct, field.Index[:len(field.Index)-1], false)
+	if !ok {
+		return reflect.Value{}, false
+	}
+	if parent.Kind() == reflect.Ptr {
+		if parent.IsNil() {
+			return reflect.Value{}, false
+		}
+		parent = parent.Elem()
+	}
+	return parent, true
+}
+
+// requiredUnless reports whether none of the fields of parent named in a
+// requiredunless tag were set by an option.
+func (x *extractor) requiredUnless(parent reflect.Value, tag string) (bool, error) {
+	for _, name := range strings.Split(tag, ",") {
+		name = x.remapField(strings.TrimSpace(name))
+		other := parent.FieldByName(name)
+		if !other.IsValid() {
+			return false, fmt.Errorf("requiredunless refers to unknown field %s", name)
+		}
+		if x.isSet(other) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
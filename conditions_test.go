@@ -0,0 +1,105 @@
+package opts
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRequiredIf(t *testing.T) {
+	opts := testconditionoptions{}
+	err := Extract(&opts, WithMode("advanced"), WithWorkers(4), WithProfile("fast"))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	opts = testconditionoptions{}
+	err = Extract(&opts, WithMode("advanced"))
+	if err == nil {
+		t.Fatalf("Extract should have failed without WithWorkers, but err is nil")
+	}
+	eString := "option WithWorkers is required when Mode==advanced"
+	if !strings.Contains(err.Error(), eString) {
+		t.Fatalf("Extract should have failed with '%s', got '%s'", eString, err)
+	}
+	if !strings.Contains(err.Error(), "option WithProfile is required when Mode!=simple") {
+		t.Fatalf("Extract should have reported every violation, got '%s'", err)
+	}
+
+	opts = testconditionoptions{}
+	err = Extract(&opts, WithMode("simple"))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	bad := testbadconditionoptions{}
+	if err := Extract(&bad); err == nil {
+		t.Fatalf("Extract should have failed on a malformed condition, but err is nil")
+	}
+}
+
+func TestRequiredUnless(t *testing.T) {
+	if err := Extract(&testunlessoptions{}, WithFromFile("app.conf")); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if err := Extract(&testunlessoptions{}, WithFromEnv("APP")); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if err := Extract(&testunlessoptions{}, WithUsername("userbob")); err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	err := Extract(&testunlessoptions{})
+	if err == nil {
+		t.Fatalf("Extract should have failed without Username or FromFile, but err is nil")
+	}
+	if !strings.Contains(err.Error(), "option WithUsername is required unless FromFile,FromEnv is set") {
+		t.Fatalf("err should name WithUsername, got '%s'", err)
+	}
+
+	if err := Extract(&testbadunlessoptions{}); err == nil {
+		t.Fatalf("Extract should have failed on an unknown field, but err is nil")
+	}
+}
+
+func TestConditionsNilParent(t *testing.T) {
+	// nested structs behind nil pointers aren't checked
+	opts := testnilconditionoptions{}
+	if err := Extract(&opts); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Condition != nil || opts.Unless != nil {
+		t.Fatalf("nested structs should have been left nil, got %+v", opts)
+	}
+}
+
+type testnilconditionoptions struct {
+	Condition *testconditionoptions
+	Unless    *testunlessoptions
+}
+
+type WithFromFile string
+type WithFromEnv string
+
+type testunlessoptions struct {
+	FromFile string `optname:"WithFromFile"`
+	FromEnv  string `optname:"WithFromEnv"`
+	Username string `optname:"WithUsername" requiredunless:"FromFile,FromEnv"`
+}
+
+type testbadunlessoptions struct {
+	Username string `optname:"WithUsername" requiredunless:"FromNowhere"`
+}
+
+type WithMode string
+type WithWorkers int
+type WithProfile string
+
+type testconditionoptions struct {
+	Mode    string `optname:"WithMode"`
+	Workers int    `optname:"WithWorkers" requiredif:"Mode==advanced"`
+	Profile string `optname:"WithProfile" requiredif:"Mode!=simple"`
+}
+
+type testbadconditionoptions struct {
+	Workers int `optname:"WithWorkers" requiredif:"Mode"`
+}
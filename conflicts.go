@@ -0,0 +1,66 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+// Conflict is a field that more than one option assigned, so all but the last
+// of their values were silently discarded.
+type Conflict struct {
+	// Field is the dotted path of the field.
+	Field string
+	// Indices are the positions of the competing options among the options
+	// passed, as in ApplyRecord, in the order they were assigned.
+	Indices []int
+	// Values are the values each competing option left the field with, the
+	// last of which it holds.
+	Values []interface{}
+}
+
+// ExtractConflictReport extracts options into dest struct like Extract, and
+// reports every field that was assigned by more than one option, which points
+// out accidental double configuration. The field still holds the value of the
+// last option. Options appended into slice fields add to each other and so
+// aren't conflicts, while options that replace a slice field whole are.
+// Conflicts are in the order of the first option of each. Options not in dest
+// are skipped.
+func ExtractConflictReport(dest interface{}, options ...interface{}) (conflicts []Conflict, err error) {
+	var fields []string
+	assigned := make(map[string]*Conflict)
+	x := &extractor{}
+	x.recordApply = func(record ApplyRecord) {
+		if record.Action != ActionSet {
+			return
+		}
+		conflict, found := assigned[record.Field]
+		if !found {
+			conflict = &Conflict{Field: record.Field}
+			assigned[record.Field] = conflict
+			fields = append(fields, record.Field)
+		}
+		conflict.Indices = append(conflict.Indices, record.Index)
+		conflict.Values = append(conflict.Values, record.After)
+	}
+	err = x.extract(dest, options...)
+
+	for _, field := range fields {
+		if conflict := assigned[field]; len(conflict.Indices) > 1 {
+			conflicts = append(conflicts, *conflict)
+		}
+	}
+	return conflicts, err
+}
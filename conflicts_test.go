@@ -0,0 +1,38 @@
+package opts
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractConflictReport(t *testing.T) {
+	opts := testoptions{}
+	conflicts, err := ExtractConflictReport(&opts,
+		WithUsername("userbob"),
+		WithItem("a"),
+		WithPhoneNum(1),
+		WithItem("b"),
+		WithUsername("useralice"),
+		WithList([]string{"x"}),
+		WithList([]string{"y"}),
+	)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	want := []Conflict{
+		{Field: "Username", Indices: []int{0, 4}, Values: []interface{}{"userbob", "useralice"}},
+		{Field: "List", Indices: []int{5, 6}, Values: []interface{}{[]string{"x"}, []string{"y"}}},
+	}
+	if !reflect.DeepEqual(conflicts, want) {
+		t.Fatalf("conflicts should be %+v, got %+v", want, conflicts)
+	}
+	// last wins as usual
+	if opts.Username != "useralice" || len(opts.Items) != 2 {
+		t.Fatalf("options should have applied, got %+v", opts)
+	}
+
+	conflicts, err = ExtractConflictReport(&testoptions{}, WithUsername("userbob"))
+	if err != nil || conflicts != nil {
+		t.Fatalf("there should be no conflicts, got %+v and %v", conflicts, err)
+	}
+}
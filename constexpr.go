@@ -0,0 +1,263 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// applyConstants seeds the numeric fields tagged const that are still at their
+// zero value with the value of the expression in the tag, before any option is
+// assigned, so const:"100*1024" seeds 102400. An option for the field replaces
+// it as it would a default.
+//
+// The expression is intentionally limited to arithmetic on literals, with no
+// variables or functions:
+//
+//	expr   = term { ("+" | "-") term }
+//	term   = factor { ("*" | "/") factor }
+//	factor = ("+" | "-") factor | "(" expr ")" | number
+//
+// A number is an integer or a decimal, such as 64 or 1.5, and spaces between
+// tokens are ignored. Integers are computed exactly, with division truncating
+// as in Go, until a decimal joins the expression and makes it a float. A
+// malformed expression, a division by zero, an overflow and a result that
+// doesn't fit the field all fail the extraction naming the field.
+func applyConstants(optionStruct reflect.Value, fieldMap map[string]reflect.StructField) error {
+	for _, optname := range orderedFields(fieldMap) {
+		field := fieldMap[optname]
+		expr, found := field.Tag.Lookup("const")
+		if !found {
+			continue
+		}
+		fieldValue, ok := fieldByIndex(optionStruct, field.Index, false)
+		if !ok || !fieldValue.IsZero() {
+			continue
+		}
+		result, err := evalConst(expr)
+		if err != nil {
+			return fmt.Errorf("const for field %s: %s", field.Name, err)
+		}
+		fitted, ok, err := convertNumber(fieldValue.Type(), field, optname, reflect.ValueOf(result.value()))
+		if !ok {
+			return fmt.Errorf("const for field %s: field of type %s is not numeric", field.Name, fieldValue.Type().String())
+		}
+		if err != nil {
+			return fmt.Errorf("const for field %s: %w", field.Name, err)
+		}
+		fieldValue.Set(fitted)
+	}
+	return nil
+}
+
+// constValue is an integer or, once a decimal is involved, a float.
+type constValue struct {
+	isFloat bool
+	i       int64
+	f       float64
+}
+
+func (v constValue) value() interface{} {
+	if v.isFloat {
+		return v.f
+	}
+	return v.i
+}
+
+func (v constValue) float() float64 {
+	if v.isFloat {
+		return v.f
+	}
+	return float64(v.i)
+}
+
+// constParser is a recursive descent parser evaluating a const expression as
+// it goes.
+type constParser struct {
+	expr string
+	pos  int
+}
+
+// evalConst evaluates a const expression.
+func evalConst(expr string) (constValue, error) {
+	p := &constParser{expr: expr}
+	v, err := p.parseExpr()
+	if err != nil {
+		return constValue{}, err
+	}
+	if p.skipSpace(); p.pos < len(p.expr) {
+		return constValue{}, fmt.Errorf("unexpected %q at %d in %q", p.expr[p.pos], p.pos, expr)
+	}
+	return v, nil
+}
+
+func (p *constParser) skipSpace() {
+	for p.pos < len(p.expr) && p.expr[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+// peek returns the next token's first byte, or 0 at the end.
+func (p *constParser) peek() byte {
+	if p.skipSpace(); p.pos < len(p.expr) {
+		return p.expr[p.pos]
+	}
+	return 0
+}
+
+func (p *constParser) parseExpr() (constValue, error) {
+	v, err := p.parseTerm()
+	for err == nil {
+		op := p.peek()
+		if op != '+' && op != '-' {
+			break
+		}
+		p.pos++
+		var rhs constValue
+		if rhs, err = p.parseTerm(); err == nil {
+			v, err = applyConstOp(op, v, rhs)
+		}
+	}
+	return v, err
+}
+
+func (p *constParser) parseTerm() (constValue, error) {
+	v, err := p.parseFactor()
+	for err == nil {
+		op := p.peek()
+		if op != '*' && op != '/' {
+			break
+		}
+		p.pos++
+		var rhs constValue
+		if rhs, err = p.parseFactor(); err == nil {
+			v, err = applyConstOp(op, v, rhs)
+		}
+	}
+	return v, err
+}
+
+func (p *constParser) parseFactor() (constValue, error) {
+	switch c := p.peek(); {
+	case c == '+' || c == '-':
+		p.pos++
+		v, err := p.parseFactor()
+		if err != nil || c == '+' {
+			return v, err
+		}
+		return applyConstOp('-', constValue{}, v)
+	case c == '(':
+		p.pos++
+		v, err := p.parseExpr()
+		if err != nil {
+			return v, err
+		}
+		if p.peek() != ')' {
+			return constValue{}, fmt.Errorf("missing ) at %d in %q", p.pos, p.expr)
+		}
+		p.pos++
+		return v, nil
+	case c >= '0' && c <= '9' || c == '.':
+		return p.parseNumber()
+	case c == 0:
+		return constValue{}, fmt.Errorf("unexpected end of %q", p.expr)
+	default:
+		return constValue{}, fmt.Errorf("unexpected %q at %d in %q", c, p.pos, p.expr)
+	}
+}
+
+func (p *constParser) parseNumber() (constValue, error) {
+	start := p.pos
+	isFloat := false
+	for p.pos < len(p.expr) && (p.expr[p.pos] >= '0' && p.expr[p.pos] <= '9' || p.expr[p.pos] == '.') {
+		isFloat = isFloat || p.expr[p.pos] == '.'
+		p.pos++
+	}
+	literal := p.expr[start:p.pos]
+	if isFloat {
+		f, err := strconv.ParseFloat(literal, 64)
+		if err != nil {
+			return constValue{}, fmt.Errorf("malformed number %q in %q", literal, p.expr)
+		}
+		return constValue{isFloat: true, f: f}, nil
+	}
+	i, err := strconv.ParseInt(literal, 10, 64)
+	if err != nil {
+		return constValue{}, fmt.Errorf("malformed number %q in %q", literal, p.expr)
+	}
+	return constValue{i: i}, nil
+}
+
+// applyConstOp applies an arithmetic operator, staying in integers while both
+// sides are.
+func applyConstOp(op byte, a, b constValue) (constValue, error) {
+	if a.isFloat || b.isFloat {
+		x, y := a.float(), b.float()
+		var f float64
+		switch op {
+		case '+':
+			f = x + y
+		case '-':
+			f = x - y
+		case '*':
+			f = x * y
+		case '/':
+			if y == 0 {
+				return constValue{}, fmt.Errorf("division by zero")
+			}
+			f = x / y
+		}
+		if math.IsInf(f, 0) {
+			return constValue{}, fmt.Errorf("overflow")
+		}
+		return constValue{isFloat: true, f: f}, nil
+	}
+
+	x, y := a.i, b.i
+	var n int64
+	switch op {
+	case '+':
+		n = x + y
+		if (y > 0 && n < x) || (y < 0 && n > x) {
+			return constValue{}, fmt.Errorf("overflow")
+		}
+	case '-':
+		n = x - y
+		if (y < 0 && n < x) || (y > 0 && n > x) {
+			return constValue{}, fmt.Errorf("overflow")
+		}
+	case '*':
+		n = x * y
+		if x != 0 && (n/x != y || (x == -1 && y == math.MinInt64)) {
+			return constValue{}, fmt.Errorf("overflow")
+		}
+	case '/':
+		if y == 0 {
+			return constValue{}, fmt.Errorf("division by zero")
+		}
+		if x == math.MinInt64 && y == -1 {
+			return constValue{}, fmt.Errorf("overflow")
+		}
+		n = x / y
+	}
+	return constValue{i: n}, nil
+}
@@ -0,0 +1,104 @@
+package opts
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConstTag(t *testing.T) {
+	opts := testconstoptions{}
+	if err := Extract(&opts); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.BufferSize != 102400 {
+		t.Fatalf("BufferSize should be 102400, got %d", opts.BufferSize)
+	}
+	if opts.Timeout != 90*time.Second {
+		t.Fatalf("Timeout should be 1m30s, got %s", opts.Timeout)
+	}
+	if opts.Ratio != 0.75 {
+		t.Fatalf("Ratio should be 0.75, got %v", opts.Ratio)
+	}
+	if opts.Blocks != 3 {
+		t.Fatalf("Blocks should be 3, got %d", opts.Blocks)
+	}
+
+	// options replace constants
+	opts = testconstoptions{}
+	if err := Extract(&opts, WithBufferSize(512)); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.BufferSize != 512 {
+		t.Fatalf("BufferSize should be 512, got %d", opts.BufferSize)
+	}
+}
+
+func TestConstTagErrors(t *testing.T) {
+	tests := map[string]interface{}{
+		"malformed": &struct {
+			N int `optname:"WithN" const:"2**3"`
+		}{},
+		"variable": &struct {
+			N int `optname:"WithN" const:"size*2"`
+		}{},
+		"unbalanced": &struct {
+			N int `optname:"WithN" const:"(1+2"`
+		}{},
+		"division": &struct {
+			N int `optname:"WithN" const:"1/(2-2)"`
+		}{},
+		"overflow": &struct {
+			N int64 `optname:"WithN" const:"9223372036854775807+1"`
+		}{},
+		"doesn't fit": &struct {
+			N int8 `optname:"WithN" const:"2*64"`
+		}{},
+		"fraction": &struct {
+			N int `optname:"WithN" const:"5/2.0"`
+		}{},
+		"not numeric": &struct {
+			N string `optname:"WithN" const:"1"`
+		}{},
+		"with a default": &struct {
+			N int `optname:"WithN" const:"1" default:"2"`
+		}{},
+	}
+	for name, dest := range tests {
+		err := Extract(dest)
+		if err == nil || !strings.Contains(err.Error(), "field N") {
+			t.Fatalf("%s: err should name field N, got %v", name, err)
+		}
+	}
+}
+
+func TestEvalConst(t *testing.T) {
+	tests := map[string]interface{}{
+		"100*1024": int64(102400),
+		" 7 / 2 ":  int64(3),
+		"-(2+3)*4": int64(-20),
+		"1.5*2":    float64(3),
+		"10-4-3":   int64(3),
+		"2+3*4":    int64(14),
+		"+8/-2":    int64(-4),
+		"1/4+0.5":  float64(0.5),
+	}
+	for expr, want := range tests {
+		got, err := evalConst(expr)
+		if err != nil {
+			t.Fatalf("%s: %s", expr, err)
+		}
+		if got.value() != want {
+			t.Fatalf("%s should be %v, got %v", expr, want, got.value())
+		}
+	}
+}
+
+type WithBufferSize int
+
+type testconstoptions struct {
+	BufferSize int           `optname:"WithBufferSize" const:"100*1024"`
+	Timeout    time.Duration `optname:"WithTimeout" const:"90*1000*1000*1000"`
+	Ratio      float64       `optname:"WithRatio" const:"3/4.0"`
+	Blocks     uint          `optname:"WithBlocks" const:"(10+2)/4"`
+}
@@ -0,0 +1,109 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MustConsumeExtract extracts options into dest struct and guarantees every
+// option was used. Like MustExtract, options not in dest result in error, but
+// instead of stopping at the first problem every unmatched option and every
+// option that failed to fit is reported together in one joined error.
+func MustConsumeExtract(dest interface{}, options ...interface{}) error {
+	leftover, fitErrs, err := (&extractor{}).consume(dest, options...)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	if len(leftover) > 0 {
+		optnames := make([]string, len(leftover))
+		for i, option := range leftover {
+			optnames[i], _ = resolveOption(option)
+		}
+		errs = append(errs, fmt.Errorf("invalid options %s", strings.Join(optnames, ", ")))
+	}
+	errs = append(errs, fitErrs...)
+	return errors.Join(errs...)
+}
+
+// ExtractAndForward extracts the options that match a field of dest struct and
+// returns the rest in order, so layered handlers can each take their own
+// options and pass the others downstream. An option that matches no field is
+// forwarded, but one that matches a field and fails to fit is an error rather
+// than forwarded. Every fit failure is reported together in one joined error,
+// alongside the options to forward.
+func ExtractAndForward(dest interface{}, options ...interface{}) (forward []interface{}, err error) {
+	forward, fitErrs, err := (&extractor{}).consume(dest, options...)
+	if err != nil {
+		return nil, err
+	}
+	return forward, errors.Join(fitErrs...)
+}
+
+// consume assigns every option it can into dest struct, carrying on past
+// failures. Options that match no field are returned as leftover, and options
+// that matched but failed to fit are returned as fitErrs. err is only set when
+// dest itself can't be extracted into.
+func (x *extractor) consume(dest interface{}, options ...interface{}) (leftover []interface{}, fitErrs []error, err error) {
+	optionStruct, err := destStruct(dest)
+	if err != nil {
+		return nil, nil, err
+	}
+	fieldMap, err := x.prepare(optionStruct)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	options = expandOptions(options)
+	x.ordering = containsOrdered(options)
+	for _, option := range options {
+		if carriesOptions(option) {
+			if err := x.apply(optionStruct, fieldMap, option); err != nil {
+				fitErrs = append(fitErrs, err)
+			}
+			continue
+		}
+		optname, optionValue := resolveOption(option)
+		if _, found := x.lookup(fieldMap, optname); !found {
+			group, routed, err := x.routeGroup(optionStruct, optname, optionValue)
+			if err != nil {
+				fitErrs = append(fitErrs, err)
+			} else if routed {
+				if err := x.extractGroup(optionStruct, group); err != nil {
+					fitErrs = append(fitErrs, err)
+				}
+			} else {
+				leftover = append(leftover, option)
+			}
+			continue
+		}
+		if err := x.assign(optionStruct, fieldMap, optname, optionValue); err != nil {
+			fitErrs = append(fitErrs, err)
+		}
+	}
+	x.sortOrdered()
+	if err := x.finish(optionStruct, fieldMap); err != nil {
+		fitErrs = append(fitErrs, err)
+	}
+	return leftover, fitErrs, nil
+}
@@ -0,0 +1,69 @@
+package opts
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMustConsumeExtract(t *testing.T) {
+	opts := testoptions{}
+	err := MustConsumeExtract(&opts, WithUsername("userbob"), WithItem("hello"))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	opts = testoptions{}
+	err = MustConsumeExtract(&opts,
+		WithInvalidOption(true),
+		WithUsername("userbob"),
+		WithHost("localhost"),
+		WithPhoneNum(8675309),
+		WithEnv("FOO=bar"),
+	)
+	if err == nil {
+		t.Fatalf("MustConsumeExtract should have failed, but err is nil")
+	}
+
+	eString := "invalid options WithInvalidOption, WithHost, WithEnv"
+	if !strings.Contains(err.Error(), eString) {
+		t.Fatalf("MustConsumeExtract should have reported '%s', got '%s'", eString, err)
+	}
+	// consumable options still apply
+	if opts.Username != "userbob" || opts.PhoneNum != 8675309 {
+		t.Fatalf("matched options should have applied, got %+v", opts)
+	}
+
+	fitting := testcoerceoptions{}
+	err = MustConsumeExtract(&fitting, WithCount("1"), WithRatio("0.5"), WithInvalidOption(true))
+	if err == nil {
+		t.Fatalf("MustConsumeExtract should have failed, but err is nil")
+	}
+	lines := strings.Split(err.Error(), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("MustConsumeExtract should have reported 3 problems, got '%s'", err)
+	}
+}
+
+func TestExtractAndForward(t *testing.T) {
+	opts := testoptions{}
+	forward, err := ExtractAndForward(&opts, WithHost("localhost"), WithUsername("userbob"), WithEnv("FOO=bar"))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Username != "userbob" {
+		t.Fatalf("Username should be 'userbob', got '%s'", opts.Username)
+	}
+	if len(forward) != 2 || forward[0] != WithHost("localhost") || forward[1] != WithEnv("FOO=bar") {
+		t.Fatalf("forward should be [localhost FOO=bar], got %v", forward)
+	}
+
+	// options that match but don't fit are errors, not forwarded
+	fitting := testcoerceoptions{}
+	forward, err = ExtractAndForward(&fitting, WithCount("1"), WithInvalidOption(true))
+	if err == nil {
+		t.Fatalf("ExtractAndForward should have failed fitting WithCount, but err is nil")
+	}
+	if len(forward) != 1 || forward[0] != WithInvalidOption(true) {
+		t.Fatalf("forward should be [true], got %v", forward)
+	}
+}
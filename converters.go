@@ -0,0 +1,140 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"context"
+	"reflect"
+	"sync"
+)
+
+// converterFunc converts a reflected value into the target type of a
+// registered converter, with the context of the extraction and the field
+// being fitted.
+type converterFunc func(context.Context, reflect.StructField, reflect.Value) (reflect.Value, error)
+
+// converterKey identifies a registered converter by its source and target
+// types.
+type converterKey struct {
+	from, to reflect.Type
+}
+
+// converters holds the converters registered with RegisterConverter, and the
+// order their pairs of types were first registered in.
+var converters = struct {
+	mu     sync.RWMutex
+	byType map[converterKey]converterFunc
+	order  []converterKey
+}{byType: make(map[converterKey]converterFunc)}
+
+// RegisterConverter registers convert for fitting options of type S into
+// fields of type T, such as compiling a string into a *regexp.Regexp. An
+// option whose type is based on S, as type WithPattern string is on string,
+// is converted into S first, and when several registered S fit, the one
+// registered first is used. Registering the same pair of types again
+// replaces the converter and keeps its place. Converters are safe to register while extractions
+// run on other goroutines.
+//
+// Converters are tried once an option doesn't fit its field as is, before
+// any coercion, and an error from convert is returned as the error of the
+// extraction. Fields of type Lazy[T] defer the conversion until Get.
+func RegisterConverter[S, T any](convert func(S) (T, error)) {
+	RegisterContextConverter(func(_ context.Context, s S) (T, error) {
+		return convert(s)
+	})
+}
+
+// RegisterContextConverter is RegisterConverter for converters that do I/O,
+// such as looking a value up in another service, and so take a context. It is
+// the context of the extraction, as passed to ExtractContextConverters or
+// ExtractBatchContext, and context.Background for extractions without one and
+// for the deferred conversions of Lazy fields. A pair of types has one
+// converter, so registering either kind replaces the other.
+func RegisterContextConverter[S, T any](convert func(context.Context, S) (T, error)) {
+	RegisterFieldConverter(func(ctx context.Context, _ reflect.StructField, s S) (T, error) {
+		return convert(ctx, s)
+	})
+}
+
+// RegisterFieldConverter is RegisterContextConverter for converters that are
+// configured by the tags of the field they fit into, such as the parameters of
+// a key derivation. sf is the field being fitted, or the field holding it for
+// the value of a Secret or Lazy. A pair of types has one converter, whichever
+// way it was registered.
+func RegisterFieldConverter[S, T any](convert func(context.Context, reflect.StructField, S) (T, error)) {
+	from := reflect.TypeOf((*S)(nil)).Elem()
+	to := reflect.TypeOf((*T)(nil)).Elem()
+	key := converterKey{from: from, to: to}
+	converters.mu.Lock()
+	defer converters.mu.Unlock()
+	if _, found := converters.byType[key]; !found {
+		converters.order = append(converters.order, key)
+	}
+	converters.byType[key] = func(ctx context.Context, sf reflect.StructField, v reflect.Value) (reflect.Value, error) {
+		converted, err := convert(ctx, sf, v.Interface().(S))
+		return reflect.ValueOf(&converted).Elem(), err
+	}
+}
+
+// ExtractContextConverters extracts options into dest struct like Extract,
+// passing ctx to the converters registered with RegisterContextConverter so
+// conversions doing I/O honor its deadline and cancellation. Converters
+// registered with RegisterConverter run as they always do. The extraction
+// also stops with ctx's error when ctx is done between options. Options not in
+// dest are skipped.
+func ExtractContextConverters(ctx context.Context, dest interface{}, options ...interface{}) error {
+	return (&extractor{ctx: ctx}).extract(dest, options...)
+}
+
+// lookupConverter finds the converter for fitting values of type from into
+// type to. When no converter takes from itself, the first registered whose
+// source from converts into is used.
+func lookupConverter(from, to reflect.Type) (converterFunc, bool) {
+	converters.mu.RLock()
+	defer converters.mu.RUnlock()
+	if convert, found := converters.byType[converterKey{from: from, to: to}]; found {
+		return convert, true
+	}
+	// options based on the source type convert into it first
+	for _, key := range converters.order {
+		if key.to != to || key.from.Kind() != from.Kind() || !from.ConvertibleTo(key.from) {
+			continue
+		}
+		source, convert := key.from, converters.byType[key]
+		return func(ctx context.Context, sf reflect.StructField, v reflect.Value) (reflect.Value, error) {
+			return convert(ctx, sf, v.Convert(source))
+		}, true
+	}
+	return nil, false
+}
+
+// fitConverted fits optionValue into field with a registered converter,
+// reporting whether there is one.
+func fitConverted(ctx context.Context, sf reflect.StructField, field reflect.Value, optionValue reflect.Value) (bool, error) {
+	convert, found := lookupConverter(optionValue.Type(), field.Type())
+	if !found {
+		return false, nil
+	}
+	converted, err := convert(ctx, sf, optionValue)
+	if err != nil {
+		return true, err
+	}
+	field.Set(converted)
+	return true, nil
+}
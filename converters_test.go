@@ -0,0 +1,120 @@
+package opts
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func TestRegisterConverter(t *testing.T) {
+	RegisterConverter(func(s string) (*url.URL, error) {
+		return url.Parse(s)
+	})
+
+	opts := testconverteroptions{}
+	if err := Extract(&opts, WithEndpoint("https://example.com/api")); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Endpoint == nil || opts.Endpoint.Host != "example.com" {
+		t.Fatalf("Endpoint should have been parsed, got %v", opts.Endpoint)
+	}
+
+	if err := Extract(&opts, WithEndpoint("://bad")); err == nil {
+		t.Fatalf("Extract should have failed converting a bad url, but err is nil")
+	}
+}
+
+func TestConverterOrder(t *testing.T) {
+	RegisterConverter(func(s testfirstsource) (testconverted, error) {
+		return testconverted{value: "first " + string(s)}, nil
+	})
+	RegisterConverter(func(s testsecondsource) (testconverted, error) {
+		return testconverted{value: "second " + string(s)}, nil
+	})
+
+	// either source fits, so the first registered always wins
+	for i := 0; i < 20; i++ {
+		opts := testorderedconverteroptions{}
+		if err := Extract(&opts, WithConverted("value")); err != nil {
+			t.Fatalf("%s", err)
+		}
+		if opts.Converted.value != "first value" {
+			t.Fatalf("Converted should be 'first value', got '%s'", opts.Converted.value)
+		}
+	}
+}
+
+type testfirstsource string
+type testsecondsource string
+type testconverted struct {
+	value string
+}
+type WithConverted string
+
+type testorderedconverteroptions struct {
+	Converted testconverted `optname:"WithConverted"`
+}
+
+func TestExtractContextConverters(t *testing.T) {
+	type tenantKey struct{}
+	RegisterContextConverter(func(ctx context.Context, s string) (tenant, error) {
+		if err := ctx.Err(); err != nil {
+			return tenant{}, err
+		}
+		prefix, _ := ctx.Value(tenantKey{}).(string)
+		return tenant{ID: prefix + s}, nil
+	})
+
+	opts := testconverteroptions{}
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme-")
+	if err := ExtractContextConverters(ctx, &opts, WithTenant("ops")); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Tenant.ID != "acme-ops" {
+		t.Fatalf("Tenant should be 'acme-ops', got '%s'", opts.Tenant.ID)
+	}
+
+	// extractions without a context convert under context.Background
+	if err := Extract(&opts, WithTenant("ops")); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Tenant.ID != "ops" {
+		t.Fatalf("Tenant should be 'ops', got '%s'", opts.Tenant.ID)
+	}
+
+	// the converter sees a context cancelled mid extraction
+	cancelled, cancel := context.WithCancel(context.Background())
+	RegisterContextConverter(func(ctx context.Context, s string) (tenant, error) {
+		cancel()
+		return tenant{}, ctx.Err()
+	})
+	err := ExtractContextConverters(cancelled, &opts, WithTenant("ops"))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("ExtractContextConverters should have failed with context.Canceled, got %v", err)
+	}
+
+	// plain converters run as they always did
+	RegisterConverter(func(s string) (tenant, error) {
+		return tenant{ID: s}, nil
+	})
+	if err := ExtractContextConverters(ctx, &opts, WithTenant("ops")); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Tenant.ID != "ops" {
+		t.Fatalf("Tenant should be 'ops', got '%s'", opts.Tenant.ID)
+	}
+}
+
+type WithEndpoint string
+
+type WithTenant string
+
+type tenant struct {
+	ID string
+}
+
+type testconverteroptions struct {
+	Endpoint *url.URL `optname:"WithEndpoint"`
+	Tenant   tenant   `optname:"WithTenant"`
+}
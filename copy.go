@@ -0,0 +1,96 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"reflect"
+)
+
+// deepCopy returns a copy of src that shares no pointers, slices or maps with
+// it. Unexported struct fields are copied shallowly, so types that keep their
+// state private, such as time.Time or sync.Mutex, are copied as they are, and
+// funcs and channels are shared. The value held by a Secret is deep copied.
+func deepCopy(src reflect.Value) reflect.Value {
+	return copyValue(src, make(map[uintptr]reflect.Value))
+}
+
+// copyValue copies src, reusing the copies of pointers seen before so cyclic
+// values stay cyclic rather than recursing forever.
+func copyValue(src reflect.Value, seen map[uintptr]reflect.Value) reflect.Value {
+	switch src.Kind() {
+	case reflect.Ptr:
+		if src.IsNil() {
+			return reflect.Zero(src.Type())
+		}
+		if dst, found := seen[src.Pointer()]; found && dst.Type() == src.Type() {
+			return dst
+		}
+		dst := reflect.New(src.Type().Elem())
+		seen[src.Pointer()] = dst
+		dst.Elem().Set(copyValue(src.Elem(), seen))
+		return dst
+	case reflect.Interface:
+		if src.IsNil() {
+			return reflect.Zero(src.Type())
+		}
+		dst := reflect.New(src.Type()).Elem()
+		dst.Set(copyValue(src.Elem(), seen))
+		return dst
+	case reflect.Slice:
+		if src.IsNil() {
+			return reflect.Zero(src.Type())
+		}
+		dst := reflect.MakeSlice(src.Type(), src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			dst.Index(i).Set(copyValue(src.Index(i), seen))
+		}
+		return dst
+	case reflect.Array:
+		dst := reflect.New(src.Type()).Elem()
+		for i := 0; i < src.Len(); i++ {
+			dst.Index(i).Set(copyValue(src.Index(i), seen))
+		}
+		return dst
+	case reflect.Map:
+		if src.IsNil() {
+			return reflect.Zero(src.Type())
+		}
+		dst := reflect.MakeMapWithSize(src.Type(), src.Len())
+		iter := src.MapRange()
+		for iter.Next() {
+			dst.SetMapIndex(copyValue(iter.Key(), seen), copyValue(iter.Value(), seen))
+		}
+		return dst
+	case reflect.Struct:
+		dst := reflect.New(src.Type()).Elem()
+		dst.Set(src)
+		if secret, ok := dst.Addr().Interface().(secretField); ok {
+			held := secret.secretValue()
+			held.Set(copyValue(held, seen))
+			return dst
+		}
+		for i := 0; i < dst.NumField(); i++ {
+			if field := dst.Field(i); field.CanSet() {
+				field.Set(copyValue(field, seen))
+			}
+		}
+		return dst
+	}
+	return src
+}
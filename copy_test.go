@@ -0,0 +1,53 @@
+package opts
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDeepCopy(t *testing.T) {
+	name := "userbob"
+	original := testcopyoptions{
+		Name:   &name,
+		Items:  []string{"hello", "world"},
+		Env:    map[string]string{"FOO": "bar"},
+		Nested: &testserver{Host: "localhost", Port: 8080},
+		Any:    []int{1, 2},
+		hidden: 7,
+		Secret: NewSecret(map[string]string{"FOO": "bar"}),
+	}
+	original.Self = &original
+
+	copied := deepCopy(reflect.ValueOf(original)).Interface().(testcopyoptions)
+	if copied.hidden != 7 {
+		t.Fatalf("unexported fields should be copied, got %d", copied.hidden)
+	}
+
+	*copied.Name = "useralice"
+	copied.Items[0] = "changed"
+	copied.Env["FOO"] = "changed"
+	copied.Nested.Host = "changed"
+	copied.Any.([]int)[0] = 9
+
+	if name != "userbob" || original.Items[0] != "hello" || original.Env["FOO"] != "bar" || original.Nested.Host != "localhost" || original.Any.([]int)[0] != 1 {
+		t.Fatalf("changing the copy changed the original: %+v", original)
+	}
+	original.Secret.value["FOO"] = "changed"
+	if copied.Secret.value["FOO"] != "bar" {
+		t.Fatalf("the map held by a Secret should not be shared with the original")
+	}
+	if copied.Self == &original {
+		t.Fatalf("pointers should not be shared with the original")
+	}
+}
+
+type testcopyoptions struct {
+	Name   *string
+	Items  []string
+	Env    map[string]string
+	Nested *testserver
+	Any    interface{}
+	Self   *testcopyoptions
+	hidden int
+	Secret Secret[map[string]string]
+}
@@ -0,0 +1,77 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// NilOptionError is returned by ExtractDedupOptions for a nil option, which
+// derives no optname to collapse duplicates by.
+type NilOptionError struct {
+	// Index is the position of the nil option once nested option slices
+	// have been expanded.
+	Index int
+}
+
+func (e *NilOptionError) Error() string {
+	return fmt.Sprintf("option %d is nil", e.Index)
+}
+
+// ExtractDedupOptions extracts options into dest struct after collapsing
+// duplicate options. Options not in dest are skipped.
+//
+// Two options are duplicates when they derive the same optname and their values
+// are reflect.DeepEqual. The first occurrence is kept and the remaining options
+// keep their relative order, so slice fields don't accumulate repeats. A nil
+// option results in a *NilOptionError.
+func ExtractDedupOptions(dest interface{}, options ...interface{}) error {
+	deduped, err := dedupOptions(expandOptions(options))
+	if err != nil {
+		return err
+	}
+	return extract(dest, false, deduped...)
+}
+
+// dedupOptions removes duplicate options, keeping first occurrences in order.
+func dedupOptions(options []interface{}) ([]interface{}, error) {
+	deduped := make([]interface{}, 0, len(options))
+	seen := make(map[string][]interface{})
+	for i, option := range options {
+		if option == nil {
+			return nil, &NilOptionError{Index: i}
+		}
+		optname, _ := resolveOption(option)
+
+		duplicate := false
+		for _, kept := range seen[optname] {
+			if reflect.DeepEqual(option, kept) {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			continue
+		}
+		seen[optname] = append(seen[optname], option)
+		deduped = append(deduped, option)
+	}
+	return deduped, nil
+}
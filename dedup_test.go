@@ -0,0 +1,38 @@
+package opts
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestExtractDedupOptions(t *testing.T) {
+	opts := testoptions{}
+	err := ExtractDedupOptions(&opts,
+		WithItem("hello"),
+		WithItem("world"),
+		WithItem("hello"),
+		WithList([]string{"a", "b"}),
+		WithList([]string{"a", "b"}),
+		WithItem("world"),
+		WithItem("again"),
+	)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	expected := []string{"hello", "world", "again"}
+	if len(opts.Items) != len(expected) {
+		t.Fatalf("Items should be %v, got %v", expected, opts.Items)
+	}
+	for i := range expected {
+		if opts.Items[i] != expected[i] {
+			t.Fatalf("Items should be %v, got %v", expected, opts.Items)
+		}
+	}
+
+	var nilOption *NilOptionError
+	err = ExtractDedupOptions(&testoptions{}, WithItem("hello"), nil)
+	if !errors.As(err, &nilOption) || nilOption.Index != 1 {
+		t.Fatalf("ExtractDedupOptions should have failed with a *NilOptionError at 1, got '%v'", err)
+	}
+}
@@ -0,0 +1,71 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// defaultFuncs holds the functions registered with RegisterDefaultFunc.
+var defaultFuncs = struct {
+	mu     sync.RWMutex
+	byName map[string]func() (string, error)
+}{byName: make(map[string]func() (string, error))}
+
+// RegisterDefaultFunc registers fn for resolving defaults of the form
+// default:"$name", so dynamic defaults such as the hostname or the current
+// time can stay declared in tags. The string fn returns is parsed into the
+// field as a literal default would be, and an error from fn is returned as the
+// error of the extraction. Registering a name again replaces its function, and
+// a nil fn removes it. Functions are safe to register while extractions run on
+// other goroutines.
+//
+// A default naming a function that isn't registered results in error. A
+// literal default starting with $ escapes it by doubling it, as in
+// default:"$$5" for the string $5.
+func RegisterDefaultFunc(name string, fn func() (string, error)) {
+	defaultFuncs.mu.Lock()
+	defer defaultFuncs.mu.Unlock()
+	if fn == nil {
+		delete(defaultFuncs.byName, name)
+		return
+	}
+	defaultFuncs.byName[name] = fn
+}
+
+// resolveDefault returns the value of a default tag, calling the registered
+// function it names when it starts with $.
+func resolveDefault(tag string) (string, error) {
+	if !strings.HasPrefix(tag, "$") {
+		return tag, nil
+	}
+	if strings.HasPrefix(tag, "$$") {
+		return tag[1:], nil
+	}
+	name := tag[1:]
+	defaultFuncs.mu.RLock()
+	fn, found := defaultFuncs.byName[name]
+	defaultFuncs.mu.RUnlock()
+	if !found {
+		return "", fmt.Errorf("unknown default function %s", name)
+	}
+	return fn()
+}
@@ -0,0 +1,50 @@
+package opts
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDefaultFuncs(t *testing.T) {
+	RegisterDefaultFunc("testhost", func() (string, error) { return "host.internal", nil })
+	RegisterDefaultFunc("testport", func() (string, error) { return "8080", nil })
+	defer RegisterDefaultFunc("testhost", nil)
+	defer RegisterDefaultFunc("testport", nil)
+
+	opts := testdefaultfuncoptions{}
+	if err := Extract(&opts); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Host != "host.internal" || opts.Port != 8080 {
+		t.Fatalf("defaults should have come from the functions, got %+v", opts)
+	}
+	if opts.Price != "$5" {
+		t.Fatalf("Price should be '$5', got '%s'", opts.Price)
+	}
+
+	// options still override dynamic defaults
+	opts = testdefaultfuncoptions{}
+	if err := Extract(&opts, WithHost("app.internal")); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Host != "app.internal" {
+		t.Fatalf("Host should be 'app.internal', got '%s'", opts.Host)
+	}
+
+	RegisterDefaultFunc("testport", func() (string, error) { return "", errors.New("no port") })
+	if err := Extract(&testdefaultfuncoptions{}); err == nil {
+		t.Fatalf("Extract should have failed on the function error, but err is nil")
+	}
+	RegisterDefaultFunc("testport", nil)
+	if err := Extract(&testdefaultfuncoptions{}); err == nil {
+		t.Fatalf("Extract should have failed on an unknown function, but err is nil")
+	}
+}
+
+type testdefaultfuncoptions struct {
+	Host  string `optname:"WithHost" default:"$testhost"`
+	Port  int    `optname:"WithPort" default:"$testport"`
+	Price string `optname:"WithPrice" default:"$$5"`
+}
+
+type WithPrice string
@@ -0,0 +1,145 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// defaultSeparator splits the elements of a slice default when the field has
+// no defaultsep tag.
+const defaultSeparator = "|"
+
+// applyDefaults seeds the fields tagged default that are still at their zero
+// value, before any option is assigned. The tag is parsed into the kind of the
+// field as with ExtractWithCoercion.
+//
+// A slice default holds its elements separated by |, or by the separator in a
+// defaultsep tag, so default:"a|b|c" seeds three elements. A separator preceded
+// by a backslash is kept in the element instead, as in default:"a\|b". Options
+// for a seeded slice replace the default elements, unless the field is tagged
+// slicemode:"append" in which case they add to them. A default starting with $
+// names a function registered with RegisterDefaultFunc. Embedded structs can
+// override the defaults of the fields promoted from them, as embeddedDefaults
+// describes. Fields tagged const are seeded afterwards by applyConstants.
+func (x *extractor) applyDefaults(optionStruct reflect.Value, fieldMap map[string]reflect.StructField) error {
+	parse := &extractor{coerce: true, convertNumbers: true}
+	overrides, err := embeddedDefaults(optionStruct.Type(), fieldMap)
+	if err != nil {
+		return err
+	}
+	for _, optname := range orderedFields(fieldMap) {
+		field := fieldMap[optname]
+		tag, found := field.Tag.Lookup("default")
+		if override, overridden := overrides[optname]; overridden {
+			tag, found = override, true
+		}
+		if !found {
+			continue
+		}
+		// defaults don't allocate nested structs
+		fieldValue, ok := fieldByIndex(optionStruct, field.Index, false)
+		if !ok || !fieldValue.IsZero() {
+			continue
+		}
+		if x.envDefaults {
+			if tag, found = envDefault(tag, x.env); !found {
+				continue
+			}
+		}
+		tag, err := resolveDefault(tag)
+		if err != nil {
+			return fmt.Errorf("default for %s: %s", optname, err)
+		}
+
+		values := []string{tag}
+		if fieldValue.Kind() == reflect.Slice && field.Tag.Get("encoding") == "" {
+			sep := defaultSeparator
+			if custom, found := field.Tag.Lookup("defaultsep"); found {
+				sep = custom
+			}
+			values = splitDefault(tag, sep)
+		}
+		for _, value := range values {
+			if err := parse.fit(fieldValue, field, optname, reflect.ValueOf(value)); err != nil {
+				return fmt.Errorf("default for %s: %s", optname, err)
+			}
+		}
+		if fieldValue.Kind() == reflect.Slice {
+			if x.defaulted == nil {
+				x.defaulted = make(map[fieldKey]bool)
+			}
+			x.defaulted[keyOf(fieldValue)] = true
+		}
+	}
+	return applyConstants(optionStruct, fieldMap)
+}
+
+// prepare maps the fields of optionStruct and seeds their defaults and
+// constants. Every entry point that assigns into a struct goes through it, so
+// the tags are honored however the values arrive.
+func (x *extractor) prepare(optionStruct reflect.Value) (map[string]reflect.StructField, error) {
+	fieldMap, err := x.mapFields(optionStruct.Type())
+	if err != nil {
+		return nil, err
+	}
+	if err := x.applyDefaults(optionStruct, fieldMap); err != nil {
+		return nil, err
+	}
+	return fieldMap, nil
+}
+
+// clearDefault empties a slice field still holding its default elements
+// before the first option is assigned into it, unless the field appends.
+func (x *extractor) clearDefault(field reflect.Value, sf reflect.StructField) {
+	if x.defaulted == nil || !field.CanAddr() || !x.defaulted[keyOf(field)] {
+		return
+	}
+	delete(x.defaulted, keyOf(field))
+	if sf.Tag.Get("slicemode") != "append" {
+		field.Set(reflect.Zero(field.Type()))
+	}
+}
+
+// splitDefault splits a slice default on sep, keeping separators escaped with
+// a backslash.
+func splitDefault(tag, sep string) []string {
+	if sep == "" {
+		return []string{tag}
+	}
+	var values []string
+	var value strings.Builder
+	for len(tag) > 0 {
+		switch {
+		case strings.HasPrefix(tag, `\`+sep):
+			value.WriteString(sep)
+			tag = tag[len(sep)+1:]
+		case strings.HasPrefix(tag, sep):
+			values = append(values, value.String())
+			value.Reset()
+			tag = tag[len(sep):]
+		default:
+			value.WriteByte(tag[0])
+			tag = tag[1:]
+		}
+	}
+	return append(values, value.String())
+}
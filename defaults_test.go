@@ -0,0 +1,104 @@
+package opts
+
+import (
+	"log/slog"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDefaults(t *testing.T) {
+	opts := testdefaultoptions{}
+	if err := Extract(&opts); err != nil {
+		t.Fatalf("%s", err)
+	}
+	want := testdefaultoptions{
+		Username: "nobody",
+		PhoneNum: 5,
+		Items:    []string{"a", "b", "c"},
+		Ports:    []int{80, 443},
+		Tags:     []string{"x|y", "z"},
+		Paths:    []string{"/bin", "/usr/bin"},
+	}
+	if !reflect.DeepEqual(opts, want) {
+		t.Fatalf("opts should be %+v, got %+v", want, opts)
+	}
+
+	// options replace default slices unless the field appends
+	opts = testdefaultoptions{Username: "userbob"}
+	if err := Extract(&opts, WithItem("d"), WithItem("e"), WithPath("/sbin")); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Username != "userbob" {
+		t.Fatalf("Username should be left as 'userbob', got '%s'", opts.Username)
+	}
+	if !reflect.DeepEqual(opts.Items, []string{"d", "e"}) {
+		t.Fatalf("Items should be [d e], got %v", opts.Items)
+	}
+	if !reflect.DeepEqual(opts.Paths, []string{"/bin", "/usr/bin", "/sbin"}) {
+		t.Fatalf("Paths should be [/bin /usr/bin /sbin], got %v", opts.Paths)
+	}
+
+	if err := Extract(&testbaddefaultoptions{}); err == nil {
+		t.Fatalf("Extract should have failed parsing a default, but err is nil")
+	}
+}
+
+type WithPath string
+
+type testdefaultoptions struct {
+	Username string   `optname:"WithUsername" default:"nobody"`
+	PhoneNum int      `optname:"WithPhoneNum" default:"5"`
+	Items    []string `optname:"WithItem" default:"a|b|c"`
+	Ports    []int    `optname:"WithPorts" default:"80,443" defaultsep:","`
+	Tags     []string `optname:"WithTag" default:"x\\|y|z"`
+	Paths    []string `optname:"WithPath" default:"/bin:/usr/bin" defaultsep:":" slicemode:"append"`
+}
+
+type testbaddefaultoptions struct {
+	PhoneNum int `optname:"WithPhoneNum" default:"five"`
+}
+
+func TestDefaultsEveryEntryPoint(t *testing.T) {
+	entryPoints := map[string]func(dest *testdefaultoptions) error{
+		"ExtractKeyValueFile": func(dest *testdefaultoptions) error {
+			return ExtractKeyValueFile(dest, strings.NewReader("WithUsername=userbob\n"))
+		},
+		"ExtractHeaders": func(dest *testdefaultoptions) error {
+			return ExtractHeaders(dest, http.Header{"Withusername": {"userbob"}})
+		},
+		"ExtractDotted": func(dest *testdefaultoptions) error {
+			return ExtractDotted(dest, map[string]string{"WithUsername": "userbob"})
+		},
+		"ExtractAttrs": func(dest *testdefaultoptions) error {
+			return ExtractAttrs(dest, slog.String("WithUsername", "userbob"))
+		},
+		"ExtractUint64Map": func(dest *testdefaultoptions) error {
+			return ExtractUint64Map(dest, map[string]uint64{})
+		},
+		"ExtractValues": func(dest *testdefaultoptions) error {
+			return ExtractValues(dest, map[string][]string{"WithUsername": {"userbob"}}, "form", nil)
+		},
+		"LoadConfig": func(dest *testdefaultoptions) error {
+			return LoadConfig(dest, DefaultSource(map[string]interface{}{"WithUsername": "userbob"}))
+		},
+	}
+	for name, extract := range entryPoints {
+		opts := testdefaultoptions{}
+		if err := extract(&opts); err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		if opts.PhoneNum != 5 || len(opts.Paths) != 2 {
+			t.Fatalf("%s should have seeded the defaults, got %+v", name, opts)
+		}
+	}
+
+	var rows []testdefaultoptions
+	if err := ExtractTable(&rows, []string{"WithUsername"}, [][]interface{}{{"userbob"}}); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if rows[0].Username != "userbob" || rows[0].PhoneNum != 5 {
+		t.Fatalf("ExtractTable should have seeded the defaults, got %+v", rows[0])
+	}
+}
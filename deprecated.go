@@ -0,0 +1,142 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// DeprecationPolicy decides what happens when an option uses a deprecated
+// optname.
+type DeprecationPolicy int
+
+const (
+	// DeprecationAllow assigns deprecated options silently. Extractions
+	// without a policy allow them.
+	DeprecationAllow DeprecationPolicy = iota
+	// DeprecationWarn assigns deprecated options and tells the observer of
+	// the extraction, with an ActionDeprecated event naming the replacement.
+	// Extractions without an observer assign them silently.
+	DeprecationWarn
+	// DeprecationError refuses deprecated options with a
+	// *DeprecatedOptionError.
+	DeprecationError
+)
+
+// DeprecatedOptionError is returned under DeprecationError when an option uses
+// the deprecated optname Name in place of Replacement.
+type DeprecatedOptionError struct {
+	Name        string
+	Replacement string
+}
+
+func (e *DeprecatedOptionError) Error() string {
+	return fmt.Sprintf("option %s is deprecated, use %s", e.Name, e.Replacement)
+}
+
+// ExtractWithDeprecationPolicy extracts options into dest struct, applying
+// policy to options that use a deprecated optname. Options not in dest are
+// skipped.
+//
+// A field lists the optnames it used to go by in a deprecated tag, such as
+// deprecated:"WithUser,WithLogin" on a field tagged optname:"WithUsername".
+// Options named by a deprecated optname are assigned into the field as if they
+// had its optname, unless an option of the same name is mapped to a field of
+// its own.
+func ExtractWithDeprecationPolicy(dest interface{}, policy DeprecationPolicy, options ...interface{}) error {
+	return (&extractor{deprecation: policy}).extract(dest, options...)
+}
+
+// ExtractWithDeprecationWarnings is ExtractWithObserver under DeprecationWarn.
+// obs is called with an ActionDeprecated event, whose Err is a
+// *DeprecatedOptionError, ahead of the event of every option that uses a
+// deprecated optname.
+func ExtractWithDeprecationWarnings(dest interface{}, obs func(ExtractEvent), options ...interface{}) error {
+	x := &extractor{deprecation: DeprecationWarn}
+	x.recordApply = func(record ApplyRecord) {
+		obs(eventOf(record))
+	}
+	return x.extract(dest, options...)
+}
+
+// deprecatedNames parses the optnames listed by a deprecated tag.
+func deprecatedNames(sf reflect.StructField) []string {
+	tag := sf.Tag.Get("deprecated")
+	if tag == "" {
+		return nil
+	}
+	names := strings.Split(tag, ",")
+	for i := range names {
+		names[i] = strings.TrimSpace(names[i])
+	}
+	return names
+}
+
+// checkDeprecatedNames refuses a deprecated optname listed more than once
+// among the fields of a struct, as an option named by it would have no single
+// field to go to.
+func checkDeprecatedNames(fieldMap map[string]reflect.StructField) error {
+	listed := make(map[string]string)
+	for _, optname := range orderedFields(fieldMap) {
+		field := fieldMap[optname]
+		for _, name := range deprecatedNames(field) {
+			if other, found := listed[name]; found {
+				return fmt.Errorf("deprecated optname %s is listed by fields %s and %s", name, other, field.Name)
+			}
+			listed[name] = field.Name
+		}
+	}
+	return nil
+}
+
+// lookupDeprecated finds the field that lists optname as deprecated.
+func lookupDeprecated(fieldMap map[string]reflect.StructField, optname string) (reflect.StructField, bool) {
+	for _, field := range fieldMap {
+		for _, name := range deprecatedNames(field) {
+			if name == optname {
+				return field, true
+			}
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// checkDeprecated applies the extractor's policy to an option named optname
+// that was found as field.
+func (x *extractor) checkDeprecated(field reflect.StructField, optname string) error {
+	replacement := field.Tag.Get("optname")
+	if replacement == optname || x.deprecation == DeprecationAllow {
+		return nil
+	}
+	for _, name := range deprecatedNames(field) {
+		if name != optname {
+			continue
+		}
+		deprecated := &DeprecatedOptionError{Name: optname, Replacement: replacement}
+		if x.deprecation == DeprecationError {
+			return deprecated
+		}
+		if x.recordApply != nil {
+			x.recordApply(ApplyRecord{Index: x.optionIndex, OptName: optname, Action: ActionDeprecated, Err: deprecated})
+		}
+	}
+	return nil
+}
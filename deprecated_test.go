@@ -0,0 +1,75 @@
+package opts
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestExtractWithDeprecationPolicy(t *testing.T) {
+	opts := testdeprecatedoptions{}
+	if err := MustExtract(&opts, WithUser("userbob"), WithPhoneNum(5)); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Username != "userbob" {
+		t.Fatalf("Username should be 'userbob', got '%s'", opts.Username)
+	}
+
+	opts = testdeprecatedoptions{}
+	if err := ExtractWithDeprecationPolicy(&opts, DeprecationWarn, WithLogin("userbob")); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Username != "userbob" {
+		t.Fatalf("Username should be 'userbob', got '%s'", opts.Username)
+	}
+
+	// warnings go to the observer
+	opts = testdeprecatedoptions{}
+	var events []ExtractEvent
+	err := ExtractWithDeprecationWarnings(&opts, func(event ExtractEvent) { events = append(events, event) }, WithLogin("userbob"))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(events) != 2 || events[0].Action != ActionDeprecated || events[1].Action != ActionSet {
+		t.Fatalf("events should be a deprecation warning and a set, got %+v", events)
+	}
+	var deprecated *DeprecatedOptionError
+	if !errors.As(events[0].Err, &deprecated) || deprecated.Replacement != "WithUsername" {
+		t.Fatalf("the warning should carry a *DeprecatedOptionError, got %v", events[0].Err)
+	}
+
+	err = ExtractWithDeprecationPolicy(&opts, DeprecationError, WithUser("userbob"))
+	if !errors.As(err, &deprecated) {
+		t.Fatalf("err should be a *DeprecatedOptionError, got %v", err)
+	}
+	if deprecated.Name != "WithUser" || deprecated.Replacement != "WithUsername" {
+		t.Fatalf("deprecated should be {WithUser WithUsername}, got %+v", deprecated)
+	}
+	if err := ExtractWithDeprecationPolicy(&opts, DeprecationError, WithUsername("userbob")); err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	bad := testbaddeprecatedoptions{}
+	if err := Extract(&bad); err == nil {
+		t.Fatalf("Extract should have failed on an empty deprecated optname, but err is nil")
+	}
+	if err := Extract(&testduplicatedeprecatedoptions{}, WithUser("userbob")); err == nil {
+		t.Fatalf("Extract should have failed on a deprecated optname listed twice, but err is nil")
+	}
+}
+
+type WithUser string
+type WithLogin string
+
+type testdeprecatedoptions struct {
+	Username string `optname:"WithUsername" deprecated:"WithUser, WithLogin"`
+	PhoneNum int    `optname:"WithPhoneNum"`
+}
+
+type testbaddeprecatedoptions struct {
+	Username string `optname:"WithUsername" deprecated:"WithUser,"`
+}
+
+type testduplicatedeprecatedoptions struct {
+	Username string `optname:"WithUsername" deprecated:"WithUser"`
+	Login    string `optname:"WithLogin" deprecated:"WithUser"`
+}
@@ -0,0 +1,95 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ExtractDotted extracts flattened keys such as db.primary.host from kv into
+// dest struct. It is ExtractDelimited with a "." delimiter.
+func ExtractDotted(dest interface{}, kv map[string]string) error {
+	return ExtractDelimited(dest, ".", kv)
+}
+
+// ExtractDelimited extracts flattened keys from kv into dest struct. Each key
+// is split on delim, every segment but the last naming the optname of a struct
+// field to descend into and the last naming the optname of the field the value
+// is parsed into for its kind. Segments that name no nested struct result in
+// error naming the full key, while unknown final segments are skipped.
+func ExtractDelimited(dest interface{}, delim string, kv map[string]string) error {
+	optionStruct, err := destStruct(dest)
+	if err != nil {
+		return err
+	}
+	x := &extractor{coerce: true}
+	fieldMap, err := x.prepare(optionStruct)
+	if err != nil {
+		return err
+	}
+
+	// apply in a stable order so errors are deterministic
+	keys := make([]string, 0, len(kv))
+	for key := range kv {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		segments := strings.Split(key, delim)
+		nestedStruct, nestedMap := optionStruct, fieldMap
+		for i, segment := range segments[:len(segments)-1] {
+			nestedStruct, err = x.descend(nestedStruct, nestedMap, segment)
+			if err != nil {
+				return fmt.Errorf("key %s: no nested option %s: %s", key, strings.Join(segments[:i+1], delim), err)
+			}
+			if nestedMap, err = x.mapFields(nestedStruct.Type()); err != nil {
+				return fmt.Errorf("key %s: %w", key, err)
+			}
+		}
+		leaf := segments[len(segments)-1]
+		if err := x.assign(nestedStruct, nestedMap, leaf, reflect.ValueOf(kv[key])); err != nil {
+			return fmt.Errorf("key %s: %w", key, err)
+		}
+	}
+	return x.finish(optionStruct, fieldMap)
+}
+
+// descend returns the struct held by the field of optionStruct tagged optname,
+// allocating it when the field is a nil pointer.
+func (x *extractor) descend(optionStruct reflect.Value, fieldMap map[string]reflect.StructField, optname string) (reflect.Value, error) {
+	field, found := x.lookup(fieldMap, optname)
+	if !found {
+		return reflect.Value{}, &UnknownOptionError{OptName: optname}
+	}
+	fieldValue, _ := fieldByIndex(optionStruct, field.Index, true)
+	if fieldValue.Kind() == reflect.Ptr && fieldValue.Type().Elem().Kind() == reflect.Struct {
+		if fieldValue.IsNil() {
+			fieldValue.Set(reflect.New(fieldValue.Type().Elem()))
+		}
+		fieldValue = fieldValue.Elem()
+	}
+	if fieldValue.Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("field %s of kind %s is not a struct", field.Name, fieldValue.Kind().String())
+	}
+	return fieldValue, nil
+}
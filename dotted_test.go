@@ -0,0 +1,61 @@
+package opts
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExtractDotted(t *testing.T) {
+	opts := testdottedoptions{}
+	err := ExtractDotted(&opts, map[string]string{
+		"name":              "service",
+		"db.primary.host":   "db1.internal",
+		"db.primary.port":   "5432",
+		"db.replica.host":   "db2.internal",
+		"db.replica.port":   "5433",
+		"db.primary.absent": "skipped",
+	})
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Name != "service" {
+		t.Fatalf("Name should be 'service', got '%s'", opts.Name)
+	}
+	if opts.DB.Primary.Host != "db1.internal" || opts.DB.Primary.Port != 5432 {
+		t.Fatalf("DB.Primary should be {db1.internal 5432}, got %+v", opts.DB.Primary)
+	}
+	if opts.DB.Replica == nil || opts.DB.Replica.Host != "db2.internal" || opts.DB.Replica.Port != 5433 {
+		t.Fatalf("DB.Replica should be {db2.internal 5433}, got %+v", opts.DB.Replica)
+	}
+
+	opts = testdottedoptions{}
+	err = ExtractDotted(&opts, map[string]string{"db.standby.host": "db3.internal"})
+	if err == nil {
+		t.Fatalf("ExtractDotted should have failed on a missing nested option, but err is nil")
+	}
+	if !strings.Contains(err.Error(), "db.standby.host") || !strings.Contains(err.Error(), "db.standby") {
+		t.Fatalf("error should name the full key and missing path, got '%s'", err)
+	}
+
+	opts = testdottedoptions{}
+	err = ExtractDelimited(&opts, "/", map[string]string{"db/primary/port": "5432"})
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.DB.Primary.Port != 5432 {
+		t.Fatalf("DB.Primary.Port should be 5432, got %d", opts.DB.Primary.Port)
+	}
+}
+
+type testdottedserver struct {
+	Host string `optname:"host"`
+	Port int    `optname:"port"`
+}
+
+type testdottedoptions struct {
+	Name string `optname:"name"`
+	DB   struct {
+		Primary testdottedserver  `optname:"primary"`
+		Replica *testdottedserver `optname:"replica"`
+	} `optname:"db"`
+}
@@ -0,0 +1,106 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// embeddedDefaults finds the defaults that embedded structs declare for the
+// fields promoted from them, by optname. An embedded struct field carries them
+// in its own default tag as a comma separated list of optname=value, such as
+//
+//	type Server struct {
+//		Listener `default:"WithPort=8080,WithHost=0.0.0.0"`
+//	}
+//
+// where a comma preceded by a backslash is kept in the value. The value takes
+// the place of the default tag of the field it names, so it is parsed the same
+// way, and a field with no default tag of its own gets one.
+//
+// Defaults resolve from the outside in: an option beats the default of the
+// outermost struct, which beats the defaults of the structs it embeds on the
+// way down to the field, which beat the default tag of the field itself, which
+// beats the zero value. Naming an optname that isn't promoted from the
+// embedded struct results in error.
+func embeddedDefaults(t reflect.Type, fieldMap map[string]reflect.StructField) (map[string]string, error) {
+	overrides := make(map[string]string)
+	checked := make(map[string]bool)
+	for _, optname := range orderedFields(fieldMap) {
+		field := fieldMap[optname]
+		embedder := t
+		found := false
+		for depth, i := range field.Index[:len(field.Index)-1] {
+			sf := embedder.Field(i)
+			if embedder = sf.Type; embedder.Kind() == reflect.Ptr {
+				embedder = embedder.Elem()
+			}
+			tag, tagged := sf.Tag.Lookup("default")
+			if !sf.Anonymous || !tagged {
+				continue
+			}
+			defaults, err := parseEmbeddedDefaults(tag)
+			if err != nil {
+				return nil, fmt.Errorf("default of embedded %s: %s", sf.Name, err)
+			}
+			prefix := fmt.Sprint(field.Index[:depth+1])
+			if !checked[prefix] {
+				checked[prefix] = true
+				if err := checkEmbeddedDefaults(defaults, fieldMap, field.Index[:depth+1]); err != nil {
+					return nil, fmt.Errorf("default of embedded %s: %s", sf.Name, err)
+				}
+			}
+			// the outermost default wins
+			if value, named := defaults[optname]; named && !found {
+				overrides[optname] = value
+				found = true
+			}
+		}
+	}
+	return overrides, nil
+}
+
+// parseEmbeddedDefaults parses the optname=value list in the default tag of an
+// embedded struct.
+func parseEmbeddedDefaults(tag string) (map[string]string, error) {
+	defaults := make(map[string]string)
+	for _, entry := range splitDefault(tag, ",") {
+		optname, value, found := strings.Cut(entry, "=")
+		optname = strings.TrimSpace(optname)
+		if !found || optname == "" {
+			return nil, fmt.Errorf("%q must be optname=value", entry)
+		}
+		defaults[optname] = value
+	}
+	return defaults, nil
+}
+
+// checkEmbeddedDefaults makes sure every optname in defaults belongs to a
+// field promoted from the embedded struct at index.
+func checkEmbeddedDefaults(defaults map[string]string, fieldMap map[string]reflect.StructField, index []int) error {
+	for optname := range defaults {
+		field, found := fieldMap[optname]
+		if !found || len(field.Index) <= len(index) || fmt.Sprint(field.Index[:len(index)]) != fmt.Sprint(index) {
+			return fmt.Errorf("no field %s in the embedded struct", optname)
+		}
+	}
+	return nil
+}
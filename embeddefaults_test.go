@@ -0,0 +1,79 @@
+package opts
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEmbeddedDefaults(t *testing.T) {
+	opts := testouterdefaults{}
+	if err := Extract(&opts); err != nil {
+		t.Fatalf("%s", err)
+	}
+	// outer default > embedded default > the field's own default > zero
+	if opts.Host != "outer.example.com" {
+		t.Fatalf("Host should take the outer default, got '%s'", opts.Host)
+	}
+	if opts.Port != 8080 {
+		t.Fatalf("Port should take the middle default, got %d", opts.Port)
+	}
+	if opts.Username != "inner" {
+		t.Fatalf("Username should take its own default, got '%s'", opts.Username)
+	}
+	if opts.PhoneNum != 0 {
+		t.Fatalf("PhoneNum should be left at zero, got %d", opts.PhoneNum)
+	}
+	if opts.Name != "middle" {
+		t.Fatalf("Name should take the middle default, got '%s'", opts.Name)
+	}
+
+	// options override defaults at every level
+	opts = testouterdefaults{}
+	if err := Extract(&opts, WithHost("localhost"), WithUsername("userbob")); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Host != "localhost" || opts.Username != "userbob" || opts.Port != 8080 {
+		t.Fatalf("options should have overridden the defaults they name, got %+v", opts)
+	}
+
+	// values already set are kept
+	opts = testouterdefaults{}
+	opts.Port = 9090
+	if err := Extract(&opts); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Port != 9090 {
+		t.Fatalf("Port should have been kept at 9090, got %d", opts.Port)
+	}
+}
+
+func TestEmbeddedDefaultsErrors(t *testing.T) {
+	err := Extract(&struct {
+		testinnerdefaults `default:"WithBool=true"`
+	}{})
+	if err == nil || !strings.Contains(err.Error(), "no field WithBool") {
+		t.Fatalf("err should report WithBool isn't embedded, got %v", err)
+	}
+	err = Extract(&struct {
+		testinnerdefaults `default:"WithHost"`
+	}{})
+	if err == nil || !strings.Contains(err.Error(), "optname=value") {
+		t.Fatalf("err should report the malformed default, got %v", err)
+	}
+}
+
+type testinnerdefaults struct {
+	Host     string `optname:"WithHost" default:"inner.example.com"`
+	Port     int    `optname:"WithPort" default:"80"`
+	Username string `optname:"WithUsername" default:"inner"`
+	PhoneNum int    `optname:"WithPhoneNum"`
+}
+
+type testmiddledefaults struct {
+	testinnerdefaults `default:"WithHost=middle.example.com,WithPort=8080"`
+	Name              string `optname:"WithSlug" default:"middle"`
+}
+
+type testouterdefaults struct {
+	testmiddledefaults `default:"WithHost=outer.example.com"`
+}
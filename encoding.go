@@ -0,0 +1,63 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// decoders are the encodings a []byte field can name in its encoding tag to
+// accept string options.
+//
+//	base64     standard base64, padded or not
+//	base64url  URL safe base64, padded or not
+//	hex        hexadecimal
+var decoders = map[string]func(string) ([]byte, error){
+	"base64": func(s string) ([]byte, error) {
+		if strings.HasSuffix(s, "=") {
+			return base64.StdEncoding.DecodeString(s)
+		}
+		return base64.RawStdEncoding.DecodeString(s)
+	},
+	"base64url": func(s string) ([]byte, error) {
+		if strings.HasSuffix(s, "=") {
+			return base64.URLEncoding.DecodeString(s)
+		}
+		return base64.RawURLEncoding.DecodeString(s)
+	},
+	"hex": hex.DecodeString,
+}
+
+// fitEncoded decodes a string option into a byte slice field.
+func fitEncoded(field reflect.Value, sf reflect.StructField, optname, encoding, in string) error {
+	decode, found := decoders[encoding]
+	if !found {
+		return fmt.Errorf("failed to set %s, unknown encoding %q for field %s", optname, encoding, sf.Name)
+	}
+	decoded, err := decode(in)
+	if err != nil {
+		return fmt.Errorf("failed to set %s, could not decode %s for field %s: %s", optname, encoding, sf.Name, err)
+	}
+	field.Set(reflect.ValueOf(decoded).Convert(field.Type()))
+	return nil
+}
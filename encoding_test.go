@@ -0,0 +1,67 @@
+package opts
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestEncodedBytes(t *testing.T) {
+	secret := []byte{0xde, 0xad, 0xbe, 0xef, 0xfb, 0xff}
+
+	opts := testencodingoptions{}
+	err := Extract(&opts,
+		WithCert("3q2+7/v/"),
+		WithToken64("3q2-7_v_"),
+		WithHexKey("deadbeeffbff"),
+	)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if !bytes.Equal(opts.Cert, secret) {
+		t.Fatalf("Cert should be %x, got %x", secret, opts.Cert)
+	}
+	if !bytes.Equal(opts.Token, secret) {
+		t.Fatalf("Token should be %x, got %x", secret, opts.Token)
+	}
+	if !bytes.Equal(opts.Key, secret) {
+		t.Fatalf("Key should be %x, got %x", secret, opts.Key)
+	}
+
+	// padding is optional
+	opts = testencodingoptions{}
+	if err := Extract(&opts, WithCert("aGk="), WithToken64("aGk")); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if string(opts.Cert) != "hi" || string(opts.Token) != "hi" {
+		t.Fatalf("Cert and Token should be 'hi', got '%s' and '%s'", opts.Cert, opts.Token)
+	}
+
+	opts = testencodingoptions{}
+	err = Extract(&opts, WithHexKey("not hex"))
+	if err == nil {
+		t.Fatalf("Extract should have failed to decode, but err is nil")
+	}
+	if !strings.Contains(err.Error(), "Key") {
+		t.Fatalf("error should name the field Key, got '%s'", err)
+	}
+
+	bad := testbadencodingoptions{}
+	if err := Extract(&bad); err == nil {
+		t.Fatalf("Extract should have failed on an unknown encoding, but err is nil")
+	}
+}
+
+type WithCert string
+type WithToken64 string
+type WithHexKey string
+
+type testencodingoptions struct {
+	Cert  []byte `optname:"WithCert" encoding:"base64"`
+	Token []byte `optname:"WithToken64" encoding:"base64url"`
+	Key   []byte `optname:"WithHexKey" encoding:"hex"`
+}
+
+type testbadencodingoptions struct {
+	Key []byte `optname:"WithHexKey" encoding:"rot13"`
+}
@@ -0,0 +1,93 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"strings"
+)
+
+// ExtractEnvDefaults extracts options into dest struct like Extract, but reads
+// default tags as a comma separated list of defaults per environment and
+// seeds each field with the one for env. In
+//
+//	default:"dev:localhost,prod:db.internal,127.0.0.1"
+//
+// the field defaults to localhost when env is dev, db.internal when env is
+// prod, and to the bare entry 127.0.0.1 for any other env. Without a bare
+// entry, a field whose tag has no entry for env is left unset. Only the first
+// colon of an entry ends the environment, so values may hold more of them, but
+// a bare entry holding one, and any entry holding a comma, escapes it with a
+// backslash, as in \: and \,. The chosen default is then read as any default
+// is, so slice separators and $ functions work within it. Options not in dest
+// are skipped.
+func ExtractEnvDefaults(dest interface{}, env string, options ...interface{}) error {
+	return (&extractor{envDefaults: true, env: env}).extract(dest, options...)
+}
+
+// envDefault picks the entry of a default tag for env, or the bare entry when
+// there is none for env. found reports whether the tag had either.
+func envDefault(tag, env string) (value string, found bool) {
+	var fallback string
+	var hasFallback bool
+	for _, entry := range splitEnvEntries(tag) {
+		if !entry.named {
+			fallback, hasFallback = entry.value, true
+			continue
+		}
+		if entry.env == env {
+			return entry.value, true
+		}
+	}
+	return fallback, hasFallback
+}
+
+// envEntry is one entry of a default tag read per environment.
+type envEntry struct {
+	env   string
+	named bool
+	value string
+}
+
+// splitEnvEntries splits a default tag on unescaped commas, and each entry on
+// its first unescaped colon. Escaped commas and colons lose their backslash,
+// while other escapes are kept for reading the chosen default.
+func splitEnvEntries(tag string) []envEntry {
+	var entries []envEntry
+	var entry envEntry
+	var part strings.Builder
+	for i := 0; i < len(tag); i++ {
+		switch c := tag[i]; {
+		case c == '\\' && i+1 < len(tag) && (tag[i+1] == ',' || tag[i+1] == ':'):
+			part.WriteByte(tag[i+1])
+			i++
+		case c == ':' && !entry.named:
+			entry.env, entry.named = part.String(), true
+			part.Reset()
+		case c == ',':
+			entry.value = part.String()
+			entries = append(entries, entry)
+			entry = envEntry{}
+			part.Reset()
+		default:
+			part.WriteByte(c)
+		}
+	}
+	entry.value = part.String()
+	return append(entries, entry)
+}
@@ -0,0 +1,64 @@
+package opts
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractEnvDefaults(t *testing.T) {
+	opts := testenvdefaultoptions{}
+	if err := ExtractEnvDefaults(&opts, "prod"); err != nil {
+		t.Fatalf("%s", err)
+	}
+	want := testenvdefaultoptions{Host: "db.internal", Port: 5432, Items: []string{"a", "b"}, Note: "x:y"}
+	if !reflect.DeepEqual(opts, want) {
+		t.Fatalf("opts should be %+v, got %+v", want, opts)
+	}
+
+	// environments without an entry fall back to the bare one, or stay unset
+	opts = testenvdefaultoptions{}
+	if err := ExtractEnvDefaults(&opts, "staging"); err != nil {
+		t.Fatalf("%s", err)
+	}
+	want = testenvdefaultoptions{Host: "127.0.0.1", Note: "a,b"}
+	if !reflect.DeepEqual(opts, want) {
+		t.Fatalf("opts should be %+v, got %+v", want, opts)
+	}
+
+	// options still win
+	opts = testenvdefaultoptions{}
+	if err := ExtractEnvDefaults(&opts, "dev", WithHost("app.internal")); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Host != "app.internal" || opts.Port != 5433 {
+		t.Fatalf("opts should be {app.internal 5433}, got %+v", opts)
+	}
+}
+
+func TestEnvDefault(t *testing.T) {
+	tests := []struct {
+		tag, env, want string
+		found          bool
+	}{
+		{"dev:localhost,prod:db.internal", "dev", "localhost", true},
+		{"dev:localhost,prod:db.internal", "test", "", false},
+		{"prod:db:5432,fallback\\:1", "prod", "db:5432", true},
+		{"prod:db:5432,fallback\\:1", "dev", "fallback:1", true},
+		{"dev:a\\,b", "dev", "a,b", true},
+	}
+	for _, test := range tests {
+		value, found := envDefault(test.tag, test.env)
+		if value != test.want || found != test.found {
+			t.Fatalf("envDefault(%q, %q) should be %q %v, got %q %v", test.tag, test.env, test.want, test.found, value, found)
+		}
+	}
+}
+
+type testenvdefaultoptions struct {
+	Host  string   `optname:"WithHost" default:"dev:localhost,prod:db.internal,127.0.0.1"`
+	Port  int      `optname:"WithPort" default:"dev:5433,prod:5432"`
+	Items []string `optname:"WithItem" default:"prod:a|b"`
+	Note  string   `optname:"WithNote" default:"prod:x:y,a\\,b"`
+}
+
+type WithNote string
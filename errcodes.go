@@ -0,0 +1,186 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrorCode identifies the kind of an extraction failure independently of its
+// English message, so user interfaces can render it in their own language.
+// The codes and the params of each are stable across versions: codes may be
+// added, but never renamed or given another meaning.
+type ErrorCode string
+
+// The codes of an ExtractError, along with the params each carries.
+const (
+	// ErrCodeUnknownOption is an option dest has no field for, when options
+	// must be found. Params: optname.
+	ErrCodeUnknownOption ErrorCode = "unknown_option"
+	// ErrCodeDisallowedOption is an option missing from the allowlist of
+	// ExtractWithAllowlist. Params: optname.
+	ErrCodeDisallowedOption ErrorCode = "disallowed_option"
+	// ErrCodeDeprecatedOption is an option refused for using a deprecated
+	// optname. Params: optname, replacement.
+	ErrCodeDeprecatedOption ErrorCode = "deprecated_option"
+	// ErrCodeFeatureDisabled is an option for a disabled feature. Params:
+	// optname, feature.
+	ErrCodeFeatureDisabled ErrorCode = "feature_disabled"
+	// ErrCodeSourceNotPermitted is a field set from a source its source tag
+	// doesn't allow. Params: field, source.
+	ErrCodeSourceNotPermitted ErrorCode = "source_not_permitted"
+	// ErrCodeKindMismatch is an option of another kind than its field.
+	// Params: optname, want, got.
+	ErrCodeKindMismatch ErrorCode = "kind_mismatch"
+	// ErrCodeOverflow is a number that doesn't fit its field. Params:
+	// optname, field, value, type.
+	ErrCodeOverflow ErrorCode = "overflow"
+	// ErrCodeTooManyOptions is more options than an extraction allows.
+	// Params: limit, got.
+	ErrCodeTooManyOptions ErrorCode = "too_many_options"
+	// ErrCodeByteBudgetExceeded is an option past the byte budget of
+	// ExtractWithByteBudget. Params: optname, limit, total.
+	ErrCodeByteBudgetExceeded ErrorCode = "byte_budget_exceeded"
+	// ErrCodeCanceled is an extraction stopped by its context. Params: none.
+	ErrCodeCanceled ErrorCode = "canceled"
+	// ErrCodeOther is any other failure, such as a malformed tag or a value
+	// that can't be parsed. Params: none, the English message is all there is.
+	ErrCodeOther ErrorCode = "other"
+)
+
+// UnknownOptionError is returned for an option dest has no field for, when
+// options must be found.
+type UnknownOptionError struct {
+	OptName string
+}
+
+func (e *UnknownOptionError) Error() string {
+	return fmt.Sprintf("invalid option %s", e.OptName)
+}
+
+// ExtractError is an extraction failure with a stable code and the params
+// needed to describe it, such as the optname and field involved. Error returns
+// the English message of the failure it wraps, which errors.Is and errors.As
+// still reach through Unwrap.
+type ExtractError struct {
+	code   ErrorCode
+	params map[string]string
+	err    error
+}
+
+// Code returns the stable code of the failure.
+func (e *ExtractError) Code() ErrorCode {
+	return e.code
+}
+
+// Params returns the params of the failure by name, as listed for its code.
+func (e *ExtractError) Params() map[string]string {
+	return e.params
+}
+
+func (e *ExtractError) Error() string {
+	return e.err.Error()
+}
+
+func (e *ExtractError) Unwrap() error {
+	return e.err
+}
+
+// ExtractWithLocalizedErrors extracts options into dest struct like
+// MustExtract, returning any failure as an *ExtractError whose code and params
+// can be rendered in another language. Options not in dest result in error
+// with ErrCodeUnknownOption.
+func ExtractWithLocalizedErrors(dest interface{}, options ...interface{}) error {
+	return Localize(MustExtract(dest, options...))
+}
+
+// Localize turns the error of any extraction into an *ExtractError, finding
+// its code from the typed error it wraps, or returns nil for nil. It works on
+// the errors of every entry point, which keep returning their errors as they
+// were so existing callers are unaffected, and is how callers of the entry
+// points other than ExtractWithLocalizedErrors get codes. Failures with no
+// typed error get ErrCodeOther, and a failure joining several takes the first
+// code listed above that any of them has.
+func Localize(err error) error {
+	if err == nil {
+		return nil
+	}
+	var localized *ExtractError
+	if errors.As(err, &localized) {
+		return localized
+	}
+
+	e := &ExtractError{code: ErrCodeOther, params: map[string]string{}, err: err}
+	var (
+		unknown      *UnknownOptionError
+		disallowed   *DisallowedOptionError
+		deprecated   *DeprecatedOptionError
+		disabled     *FeatureDisabledError
+		notPermitted *SourceNotPermittedError
+		mismatch     *KindMismatchError
+		overflow     *OverflowError
+		tooMany      *TooManyOptionsError
+		overBudget   *ByteBudgetExceededError
+	)
+	switch {
+	case errors.As(err, &unknown):
+		e.code = ErrCodeUnknownOption
+		e.params["optname"] = unknown.OptName
+	case errors.As(err, &disallowed):
+		e.code = ErrCodeDisallowedOption
+		e.params["optname"] = disallowed.Name
+	case errors.As(err, &deprecated):
+		e.code = ErrCodeDeprecatedOption
+		e.params["optname"] = deprecated.Name
+		e.params["replacement"] = deprecated.Replacement
+	case errors.As(err, &disabled):
+		e.code = ErrCodeFeatureDisabled
+		e.params["optname"] = disabled.OptName
+		e.params["feature"] = disabled.Feature
+	case errors.As(err, &notPermitted):
+		e.code = ErrCodeSourceNotPermitted
+		e.params["field"] = notPermitted.Field
+		e.params["source"] = string(notPermitted.Source)
+	case errors.As(err, &mismatch):
+		e.code = ErrCodeKindMismatch
+		e.params["optname"] = mismatch.OptName
+		e.params["want"] = mismatch.Want
+		e.params["got"] = mismatch.Got
+	case errors.As(err, &overflow):
+		e.code = ErrCodeOverflow
+		e.params["optname"] = overflow.OptName
+		e.params["field"] = overflow.Field
+		e.params["value"] = fmt.Sprint(overflow.Value)
+		e.params["type"] = overflow.TargetType.String()
+	case errors.As(err, &tooMany):
+		e.code = ErrCodeTooManyOptions
+		e.params["limit"] = fmt.Sprint(tooMany.Limit)
+		e.params["got"] = fmt.Sprint(tooMany.Got)
+	case errors.As(err, &overBudget):
+		e.code = ErrCodeByteBudgetExceeded
+		e.params["optname"] = overBudget.OptName
+		e.params["limit"] = fmt.Sprint(overBudget.Limit)
+		e.params["total"] = fmt.Sprint(overBudget.Total)
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		e.code = ErrCodeCanceled
+	}
+	return e
+}
@@ -0,0 +1,61 @@
+package opts
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestExtractWithLocalizedErrors(t *testing.T) {
+	if err := ExtractWithLocalizedErrors(&testoptions{}, WithUsername("userbob")); err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	err := ExtractWithLocalizedErrors(&testoptions{}, WithHost("localhost"))
+	var localized *ExtractError
+	if !errors.As(err, &localized) {
+		t.Fatalf("err should be an *ExtractError, got %v", err)
+	}
+	if localized.Code() != ErrCodeUnknownOption || localized.Params()["optname"] != "WithHost" {
+		t.Fatalf("err should be unknown_option for WithHost, got %s %v", localized.Code(), localized.Params())
+	}
+	if err.Error() != "invalid option WithHost" {
+		t.Fatalf("err should keep the English message, got '%s'", err)
+	}
+}
+
+func TestLocalize(t *testing.T) {
+	if Localize(nil) != nil {
+		t.Fatalf("Localize(nil) should be nil")
+	}
+
+	tests := []struct {
+		err    error
+		code   ErrorCode
+		params map[string]string
+	}{
+		{Extract(&testoverflowoptions{}, WithLevels{1, 300}), ErrCodeOverflow, map[string]string{"optname": "WithLevels", "field": "Levels", "value": "300", "type": "int8"}},
+		{ExtractStrictShape(&testoptions{}, WithItem("a")), ErrCodeKindMismatch, map[string]string{"optname": "WithItem", "want": "slice", "got": "string"}},
+		{ExtractNestedMap(&testoptions{}, map[string]interface{}{"WithPhoneNum": "x"}), ErrCodeOther, map[string]string{}},
+		{ExtractWithMaxOptions(&testoptions{}, 1, WithUsername("a"), WithUsername("b")), ErrCodeTooManyOptions, map[string]string{"limit": "1", "got": "2"}},
+		{context.Canceled, ErrCodeCanceled, map[string]string{}},
+	}
+	for _, test := range tests {
+		var localized *ExtractError
+		if !errors.As(Localize(test.err), &localized) {
+			t.Fatalf("Localize should return an *ExtractError for %v", test.err)
+		}
+		if localized.Code() != test.code {
+			t.Fatalf("code of '%v' should be %s, got %s", test.err, test.code, localized.Code())
+		}
+		for name, want := range test.params {
+			if got := localized.Params()[name]; got != want {
+				t.Fatalf("param %s of '%v' should be '%s', got '%s'", name, test.err, want, got)
+			}
+		}
+		// the typed error stays reachable
+		if !errors.Is(localized, test.err) {
+			t.Fatalf("Localize should wrap '%v'", test.err)
+		}
+	}
+}
@@ -22,9 +22,31 @@
 // called optname. So if an option of type WithUsername is passed and the struct
 // has a field called Username tagged optname:"WithUsername", this will populate
 // Username with the value of WithUsername.
+//
+// Untagged struct fields are scanned for tags of their own, so the tagged
+// fields of nested and embedded structs share the namespace of the outer
+// struct.
+//
+// An option with an OptName() string method is named by the method instead of
+// its type, so option types can be renamed without changing the options they
+// carry.
+//
+// An option may also be a reflect.Value, which extracts as the value it holds.
+// The zero reflect.Value holds nothing and is skipped.
+//
+// A field tagged jsonfit:"true" additionally accepts any option that survives a
+// JSON round trip into the field's type, when no other fit applies.
+//
+// A field tagged default:"value" that is at its zero value is seeded with the
+// value before options are assigned.
+//
+// A field tagged inherit:"parent.Field" that is still at its zero value once
+// options are assigned takes the value of Field in the struct one level up,
+// for cascading defaults in nested configs.
 package opts
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"strings"
@@ -42,7 +64,360 @@ func MustExtract(dest interface{}, options ...interface{}) error {
 
 // Underlying extract function.
 func extract(dest interface{}, mustFind bool, options ...interface{}) error {
+	return (&extractor{mustFind: mustFind}).extract(dest, options...)
+}
+
+// extractor holds the settings of an extraction.
+type extractor struct {
+	// error on options not in dest
+	mustFind bool
+	// parse strings into other scalars and format scalars into strings
+	coerce bool
+	// how coerced strings write numbers
+	numberFormat NumberFormat
+	// convert between numeric kinds when the value fits
+	convertNumbers bool
+	// read default tags per environment, choosing the entries for env
+	envDefaults bool
+	env         string
+	// names fields in place of optname tags when set
+	resolve func(reflect.StructField) (string, bool)
+	// maps the old field names tags refer to onto their current names
+	fieldRemap map[string]string
+	// capacities to grow slice fields to before appending into them
+	capHints map[string]int
+	// fitted into the fields still unset once options are assigned
+	fallbacks map[string]interface{}
+	// the only optnames options may use when set
+	allowlist map[string]bool
+	// the bytes of strings and slices options may assign when budgeted, and
+	// those assigned so far
+	budgeted   bool
+	byteBudget int
+	bytesSpent int
+	// leave out assignments that don't change the value of their field
+	changeOnly bool
+	// refuse options that would turn strings into numbers or back
+	noStringCoercion bool
+	// the only (from, to) kind conversions permitted when not nil
+	coercions map[[2]reflect.Kind]bool
+	// how deeply nested structs are scanned, zero means defaultMaxDepth
+	maxDepth int
+	// derive names for untagged fields and match them regardless of case
+	caseConvert bool
+	// called for every tagged field once options are assigned
+	fieldHook FieldHook
+	// refuse tag keys the package doesn't know
+	strictTags bool
+	// refuse single options for slice fields and slices for single fields
+	strictShape bool
+	// refuse single options for slice fields
+	strictSlice bool
+	// the features fields may be gated by, nil when not gating
+	features map[string]bool
+	// strings seen so far when interning, nil when not interning
+	interned map[string]string
+	// stop between options once done, nil when not cancellable
+	ctx context.Context
+	// only assign options, skipping the passes that follow
+	dryRun bool
+	// what happens to options using a deprecated optname
+	deprecation DeprecationPolicy
+	// hand options to the handlers registered for their optname
+	plugins bool
+	// called after each option is fitted, with the field's previous value
+	afterAssign func(optname string, field, previous, optionValue reflect.Value)
+	// called with what happened to each option
+	recordApply func(record ApplyRecord)
+	// the position of the option being applied, and whether the options of
+	// a group are being applied
+	optionIndex int
+	inGroup     bool
+
+	// fields assigned by an option so far
+	set map[fieldKey]bool
+	// slice fields still holding their default elements
+	defaulted map[fieldKey]bool
+	// the leading elements of pipeline slice fields that no option added
+	kept map[fieldKey]int
+	// the priority of the option being applied, and the highest priority
+	// that reached each single value field
+	priority   int
+	priorities map[fieldKey]int
+	// the element position of the option being applied, when indexed
+	index   int
+	indexed bool
+	// whether slice appends are sorted by key, the key of the option being
+	// applied, and the appends buffered for sorting
+	ordering      bool
+	orderKey      int
+	orderedFields map[fieldKey]*orderedField
+	// the source of the layer being applied, empty outside of layers
+	source Source
+}
+
+// context returns the context of the extraction.
+func (x *extractor) context() context.Context {
+	if x.ctx == nil {
+		return context.Background()
+	}
+	return x.ctx
+}
+
+// fieldKey identifies a field by where it lives in memory, so assignments can
+// be tracked across nested structs.
+type fieldKey struct {
+	addr uintptr
+	t    reflect.Type
+}
+
+// keyOf returns the fieldKey of an addressable field.
+func keyOf(fieldValue reflect.Value) fieldKey {
+	return fieldKey{addr: fieldValue.UnsafeAddr(), t: fieldValue.Type()}
+}
+
+// markSet records that an option assigned fieldValue.
+func (x *extractor) markSet(fieldValue reflect.Value) {
+	if !fieldValue.CanAddr() {
+		return
+	}
+	if x.set == nil {
+		x.set = make(map[fieldKey]bool)
+	}
+	x.set[keyOf(fieldValue)] = true
+}
+
+// isSet reports whether an option assigned fieldValue.
+func (x *extractor) isSet(fieldValue reflect.Value) bool {
+	return fieldValue.CanAddr() && x.set[keyOf(fieldValue)]
+}
+
+// extract assigns options into dest struct with the extractor's settings.
+func (x *extractor) extract(dest interface{}, options ...interface{}) error {
 	// reflection of destination
+	optionStruct, err := destStruct(dest)
+	if err != nil {
+		return err
+	}
+
+	// map all the optnames to struct fields and seed their defaults
+	fieldMap, err := x.prepare(optionStruct)
+	if err != nil {
+		return err
+	}
+
+	// iterate the options to assign them
+	options = expandOptions(options)
+	outer := !x.inGroup
+	x.inGroup = true
+	defer func() { x.inGroup = !outer }()
+	if outer && containsOrdered(options) {
+		x.ordering = true
+	}
+	// slices are sorted per struct, groups sort their own
+	outerOrdered := x.orderedFields
+	x.orderedFields = nil
+	defer func() { x.orderedFields = outerOrdered }()
+	for i := 0; i < len(options); i++ {
+		// options in a group share the position of the group
+		if outer {
+			x.optionIndex = i
+		}
+		if x.ctx != nil {
+			if err := x.ctx.Err(); err != nil {
+				return err
+			}
+		}
+		if err := x.apply(optionStruct, fieldMap, options[i]); err != nil {
+			return err
+		}
+	}
+	x.sortOrdered()
+	if outer && x.fallbacks != nil {
+		if err := x.applyFallbacks(optionStruct, fieldMap); err != nil {
+			return err
+		}
+	}
+	return x.finish(optionStruct, fieldMap)
+}
+
+// apply assigns one option into optionStruct, including the options that
+// carry other options.
+func (x *extractor) apply(optionStruct reflect.Value, fieldMap map[string]reflect.StructField, option interface{}) error {
+	switch carrier := option.(type) {
+	case groupOption:
+		// grouped options extract into their own struct
+		return x.extractGroup(optionStruct, carrier)
+	case spreadOption:
+		// spread structs extract as their fields
+		return x.spread(optionStruct, fieldMap, carrier)
+	case prioritizedOption:
+		// prioritized options apply at their priority
+		return x.applyPrioritized(optionStruct, fieldMap, carrier)
+	case indexedOption:
+		// indexed options apply to one element
+		return x.applyIndexed(optionStruct, fieldMap, carrier)
+	case orderedOption:
+		// ordered options append by their key
+		return x.applyOrdered(optionStruct, fieldMap, carrier)
+	}
+
+	// reflect the option
+	optname, optionValue := resolveOption(option)
+	if handled, err := x.handle(optionStruct, optname, optionValue); handled {
+		return err
+	}
+	if _, found := x.lookup(fieldMap, optname); !found {
+		// options named after a group are routed into it
+		group, routed, err := x.routeGroup(optionStruct, optname, optionValue)
+		if err != nil {
+			return err
+		}
+		if routed {
+			return x.extractGroup(optionStruct, group)
+		}
+	}
+	return x.assign(optionStruct, fieldMap, optname, optionValue)
+}
+
+// carriesOptions reports whether option is applied as the options it carries.
+func carriesOptions(option interface{}) bool {
+	switch option.(type) {
+	case groupOption, spreadOption, prioritizedOption, indexedOption, orderedOption:
+		return true
+	}
+	return false
+}
+
+// finish runs the passes that follow assigning options into optionStruct.
+func (x *extractor) finish(optionStruct reflect.Value, fieldMap map[string]reflect.StructField) error {
+	if x.dryRun {
+		return nil
+	}
+	if err := x.inheritFields(optionStruct); err != nil {
+		return err
+	}
+	if err := normalizeFields(optionStruct, fieldMap); err != nil {
+		return err
+	}
+	if err := x.pipelineFields(optionStruct, fieldMap); err != nil {
+		return err
+	}
+	x.internStrings(optionStruct, fieldMap)
+	if err := x.checkLengths(optionStruct, fieldMap); err != nil {
+		return err
+	}
+	if err := setFingerprints(optionStruct, fieldMap); err != nil {
+		return err
+	}
+	if err := x.runFieldHook(optionStruct, fieldMap); err != nil {
+		return err
+	}
+	return x.checkConditions(optionStruct, fieldMap)
+}
+
+// assign fits one option into the field of optionStruct tagged optname.
+func (x *extractor) assign(optionStruct reflect.Value, fieldMap map[string]reflect.StructField, optname string, optionValue reflect.Value) error {
+	// nothing to assign from the zero reflect.Value
+	if !optionValue.IsValid() {
+		return nil
+	}
+
+	if err := x.checkAllowed(optname); err != nil {
+		return err
+	}
+
+	// find the field
+	field, found := x.lookup(fieldMap, optname)
+	if !found {
+		x.logApply(optionStruct, field, optname, reflect.Value{}, reflect.Value{}, optionValue, nil)
+		// skip this value when finding it is not required
+		if !x.mustFind {
+			return nil
+		}
+		return &UnknownOptionError{OptName: optname}
+	}
+	if err := x.checkDeprecated(field, optname); err != nil {
+		return err
+	}
+	if err := x.checkFeature(field, optname); err != nil {
+		return err
+	}
+	if err := x.checkSource(field); err != nil {
+		return err
+	}
+
+	fieldValue, _ := fieldByIndex(optionStruct, field.Index, true)
+	if x.strictShape {
+		if err := checkShape(fieldValue, field, optname, optionValue); err != nil {
+			return err
+		}
+	}
+	if x.strictSlice {
+		if err := checkSliceAppend(fieldValue, field, optname, optionValue); err != nil {
+			return err
+		}
+	}
+	if x.noStringCoercion {
+		if err := checkStringCoercion(fieldValue, field, optname, optionValue); err != nil {
+			return err
+		}
+	}
+	if x.outranked(fieldValue) {
+		return nil
+	}
+	x.clearDefault(fieldValue, field)
+	x.growToHint(fieldValue, field, optname)
+	x.keepElements(fieldValue, field, optionValue)
+	var previous reflect.Value
+	if x.afterAssign != nil || x.recordApply != nil || x.changeOnly {
+		previous = reflect.New(fieldValue.Type()).Elem()
+		previous.Set(fieldValue)
+	}
+	before := -1
+	if x.ordering && fieldValue.Kind() == reflect.Slice {
+		before = fieldValue.Len()
+	}
+	if err := x.spendBytes(optname, optionValue); err != nil {
+		return err
+	}
+	var err error
+	switch {
+	case x.indexed:
+		err = x.fitAt(fieldValue, field, optname, optionValue)
+	case x.strictSlice && appendsSlice(fieldValue, field, optionValue):
+		err = x.fitAppend(fieldValue, field, optname, optionValue)
+	default:
+		err = x.fit(fieldValue, field, optname, optionValue)
+	}
+	var untruncated reflect.Value
+	var truncated bool
+	if err == nil {
+		untruncated, truncated = truncate(fieldValue, field)
+	}
+	if err == nil && x.unchanged(fieldValue, previous) {
+		x.markSet(fieldValue)
+		return nil
+	}
+	x.logApply(optionStruct, field, optname, fieldValue, previous, optionValue, err)
+	if truncated {
+		x.logTruncate(optionStruct, field, optname, fieldValue, untruncated)
+	}
+	if err != nil {
+		return err
+	}
+	x.markSet(fieldValue)
+	if before >= 0 {
+		x.recordOrder(fieldValue, field, before, optionValue)
+	}
+	if x.afterAssign != nil {
+		x.afterAssign(optname, fieldValue, previous, optionValue)
+	}
+	return nil
+}
+
+// destStruct resolves dest to the struct value options are assigned into.
+func destStruct(dest interface{}) (reflect.Value, error) {
 	optionStruct := reflect.ValueOf(dest)
 	// the destination must be addressable to make changes
 	if optionStruct.Kind() == reflect.Ptr || optionStruct.Kind() == reflect.Interface {
@@ -51,62 +426,211 @@ func extract(dest interface{}, mustFind bool, options ...interface{}) error {
 
 	// it must be a struct
 	if optionStruct.Kind() != reflect.Struct {
-		return fmt.Errorf("dest must be a struct")
+		return optionStruct, fmt.Errorf("dest must be a struct")
 	}
+	return optionStruct, nil
+}
+
+// namedOption carries an option value whose optname was given explicitly
+// rather than derived from its type.
+type namedOption struct {
+	name  string
+	value interface{}
+}
+
+// optNamer is implemented by options that name themselves.
+type optNamer interface {
+	OptName() string
+}
 
-	// map all the optnames to struct field names
-	fieldNameMap := make(map[string]string)
-	for i := 0; i < optionStruct.NumField(); i++ {
-		// use optname tags
-		optname := optionStruct.Type().Field(i).Tag.Get("optname")
-		if optname == "" {
-			continue
+// resolveOption returns the optname and reflected value of an option. An
+// option that is already a reflect.Value is used as is, and the zero
+// reflect.Value resolves to an invalid value that assign skips.
+func resolveOption(option interface{}) (string, reflect.Value) {
+	if named, ok := option.(namedOption); ok {
+		return named.name, reflect.ValueOf(named.value)
+	}
+	if optionValue, ok := option.(reflect.Value); ok {
+		// look through interfaces to the value they hold
+		for optionValue.Kind() == reflect.Interface {
+			optionValue = optionValue.Elem()
+		}
+		if !optionValue.IsValid() {
+			return "", optionValue
 		}
-		// make sure this option is not already in use
-		if _, found := fieldNameMap[optname]; found {
-			return fmt.Errorf("option name %s has multiple tagged fields", optname)
+		if isOpt(optionValue) {
+			return resolveOpt(optionValue)
 		}
-		// store for assignments
-		fieldNameMap[optname] = optionStruct.Type().Field(i).Name
+		return optionName(optionValue), optionValue
+	}
+	optionValue := reflect.ValueOf(option)
+	if isOpt(optionValue) {
+		return resolveOpt(optionValue)
 	}
+	return optionName(optionValue), optionValue
+}
 
-	// iterate the options to assign them
-	for i := 0; i < len(options); i++ {
-		// reflect the option
-		optionValue := reflect.ValueOf(options[i])
-		// transform the tag to just the type without package name
-		extracter := strings.Split(optionValue.Type().String(), ".")
-		optname := extracter[len(extracter)-1]
-
-		// find the fieldName
-		fieldName, found := fieldNameMap[optname]
-		if !found {
-			// skip this value when finding it is not required
-			if !mustFind {
-				continue
-			}
-			return fmt.Errorf("invalid option %s", optname)
+// optionName derives the optname of an option from its OptName method, or
+// otherwise from its type name.
+func optionName(optionValue reflect.Value) string {
+	if optionValue.CanInterface() {
+		if namer, ok := optionValue.Interface().(optNamer); ok {
+			return namer.OptName()
 		}
+	}
+	// transform the tag to just the type without package name
+	extracter := strings.Split(optionValue.Type().String(), ".")
+	return extracter[len(extracter)-1]
+}
 
-		// fit the optionValue as exact match
-		if optionStruct.FieldByName(fieldName).Type().Kind() == optionValue.Kind() {
-			optionValue = optionValue.Convert(optionStruct.FieldByName(fieldName).Type())
-			optionStruct.FieldByName(fieldName).Set(optionValue)
-			// fit has been made, skip to next
-			continue
+// fit assigns optionValue into field, either directly or by appending into a
+// slice. sf describes the tagged struct field being assigned.
+func (x *extractor) fit(field reflect.Value, sf reflect.StructField, optname string, optionValue reflect.Value) error {
+	// fit the optionValue with the field's own setter
+	if setter, ok := optSetter(field); ok {
+		return fitSetter(setter, sf, optname, optionValue)
+	}
+
+	// fit the optionValue into an interface it satisfies, empty interfaces
+	// accept any option
+	if field.Type().Kind() == reflect.Interface {
+		if field.Type().NumMethod() == 0 || optionValue.Type().Implements(field.Type()) {
+			field.Set(optionValue)
+			return nil
 		}
+		return fmt.Errorf("failed to set %s, %s does not implement %s", optname, optionValue.Type().String(), field.Type().String())
+	}
 
-		// fit the optionValue by appending into a slice
-		if optionStruct.FieldByName(fieldName).Type().Kind() == reflect.Slice && optionStruct.FieldByName(fieldName).Type().Elem().Kind() == optionValue.Kind() {
-			optionValue = optionValue.Convert(optionStruct.FieldByName(fieldName).Type().Elem())
-			optionStruct.FieldByName(fieldName).Set(reflect.Append(optionStruct.FieldByName(fieldName), optionValue))
-			// fit has been made, skip to next
-			continue
+	// fit the optionValue as exact match
+	if field.Type().Kind() == optionValue.Kind() && optionValue.Type().ConvertibleTo(field.Type()) {
+		field.Set(optionValue.Convert(field.Type()))
+		return nil
+	}
+
+	// fit a json.Number into a numeric field without losing digits
+	if fitted, err := fitJSONNumber(field, sf, optname, optionValue); fitted {
+		return err
+	}
+
+	// fit an iterator by ranging over it into a slice or map
+	if arity := seqArity(optionValue.Type()); (arity == 1 && field.Kind() == reflect.Slice) || (arity == 2 && field.Kind() == reflect.Map) {
+		return x.fitSeq(field, sf, optname, optionValue)
+	}
+
+	// fit a slice into a slice of another element type element by element
+	if field.Type().Kind() == reflect.Slice && optionValue.Kind() == reflect.Slice && optionValue.Type().Elem().Kind() == field.Type().Elem().Kind() && optionValue.Type().Elem().ConvertibleTo(field.Type().Elem()) {
+		fitSlice(field, optionValue)
+		return nil
+	}
+
+	// fit the optionValue into the value held by a secret
+	if field.CanAddr() {
+		if secret, ok := field.Addr().Interface().(secretField); ok {
+			return x.fitSecret(secret, sf, optname, optionValue)
+		}
+	}
+
+	// fit the optionValue into a lazy field, converting it on first use
+	if field.CanAddr() {
+		if lazy, ok := field.Addr().Interface().(lazyField); ok {
+			return fitLazy(lazy, sf, optname, optionValue)
+		}
+	}
+
+	// fit the optionValue with a registered converter
+	if converted, err := fitConverted(x.context(), sf, field, optionValue); converted {
+		if err != nil {
+			return fmt.Errorf("failed to set %s, could not convert for field %s: %w", optname, sf.Name, err)
 		}
+		return nil
+	}
 
-		// failed to find fit
-		return fmt.Errorf("failed to set %s when fitting %s into %s", optname, optionStruct.FieldByName(fieldName).Type().Kind().String(), optionValue.Kind().String())
+	// fit a string into a regular expression by compiling it
+	if optionValue.Kind() == reflect.String && takesRegexp(field) {
+		return fitRegexp(field, sf, optname, optionValue.String())
+	}
 
+	// fit a hex string into a color by parsing it
+	if optionValue.Kind() == reflect.String && takesColor(field) {
+		return fitColor(field, sf, optname, optionValue.String())
 	}
-	return nil
+
+	// fit a string into bytes by decoding it
+	if encoding, found := sf.Tag.Lookup("encoding"); found && optionValue.Kind() == reflect.String && field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.Uint8 {
+		return fitEncoded(field, sf, optname, encoding, optionValue.String())
+	}
+
+	// fit a single character string into a rune
+	if optionValue.Kind() == reflect.String && takesRune(field, sf) {
+		return fitRune(field, sf, optname, optionValue.String())
+	}
+
+	// fit a string into a number by parsing it as a quantity
+	if kind, found := sf.Tag.Lookup("quantity"); found && optionValue.Kind() == reflect.String && numericKind(field.Kind()) {
+		return fitQuantity(field, sf, optname, kind, optionValue.String())
+	}
+
+	// fit a PATH style string into a slice by splitting it
+	if optionValue.Kind() == reflect.String && takesPathList(field, sf) {
+		fitPathList(field, optionValue.String())
+		return nil
+	}
+
+	// fit the optionValue by appending into a slice
+	if field.Type().Kind() == reflect.Slice && field.Type().Elem().Kind() == optionValue.Kind() && optionValue.Type().ConvertibleTo(field.Type().Elem()) {
+		optionValue = optionValue.Convert(field.Type().Elem())
+		field.Set(reflect.Append(field, optionValue))
+		return nil
+	}
+
+	// fit a slice into an array element by element
+	if field.Type().Kind() == reflect.Array && optionValue.Kind() == reflect.Slice {
+		return fitArray(field, sf, optname, optionValue)
+	}
+
+	// fit a pair into a map entry
+	if field.Type().Kind() == reflect.Map && isPair(optionValue) {
+		return x.fitPair(field, sf, optname, optionValue)
+	}
+
+	// fit a "key=value" string into a map of strings
+	if field.Type().Kind() == reflect.Map && field.Type().Key().Kind() == reflect.String && field.Type().Elem().Kind() == reflect.String && optionValue.Kind() == reflect.String {
+		// only the first = splits, values may contain more
+		pair := strings.SplitN(optionValue.String(), "=", 2)
+		if len(pair) != 2 {
+			return fmt.Errorf("failed to set %s, expected key=value but got %q", optname, optionValue.String())
+		}
+		if field.IsNil() {
+			field.Set(reflect.MakeMap(field.Type()))
+		}
+		key := reflect.ValueOf(pair[0]).Convert(field.Type().Key())
+		field.SetMapIndex(key, reflect.ValueOf(pair[1]).Convert(field.Type().Elem()))
+		return nil
+	}
+
+	// fit the optionValue by converting it into the field or its elements
+	if fitted, ok, err := x.convert(field.Type(), sf, optname, optionValue); ok {
+		if err != nil {
+			return err
+		}
+		field.Set(fitted)
+		return nil
+	}
+	if field.Type().Kind() == reflect.Slice {
+		if fitted, ok, err := x.convert(field.Type().Elem(), sf, optname, optionValue); ok {
+			if err != nil {
+				return err
+			}
+			field.Set(reflect.Append(field, fitted))
+			return nil
+		}
+	}
+
+	// fit the optionValue through json as a last resort when the field opts in
+	if sf.Tag.Get("jsonfit") == "true" {
+		return fitJSON(field, sf, optname, optionValue)
+	}
+
+	// failed to find fit
+	return fmt.Errorf("failed to set %s when fitting %s into %s", optname, field.Type().Kind().String(), optionValue.Kind().String())
 }
@@ -2,6 +2,7 @@ package opts
 
 import (
 	"fmt"
+	"reflect"
 	"testing"
 )
 
@@ -37,6 +38,114 @@ func TestOptionExtraction(t *testing.T) {
 	}
 }
 
+func TestMapFromKeyValue(t *testing.T) {
+	opts := testmapoptions{}
+	err := Extract(&opts, WithEnv("FOO=bar"), WithEnv("QUERY=a=b"), WithEnv("EMPTY="))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	expected := map[string]string{"FOO": "bar", "QUERY": "a=b", "EMPTY": ""}
+	if len(opts.Env) != len(expected) {
+		t.Fatalf("Env should have %d entries, got %v", len(expected), opts.Env)
+	}
+	for k, v := range expected {
+		if opts.Env[k] != v {
+			t.Fatalf("Env[%s] should be '%s', got '%s'", k, v, opts.Env[k])
+		}
+	}
+
+	opts = testmapoptions{}
+	err = Extract(&opts, WithEnv("FOO"))
+	if err == nil {
+		t.Fatalf("Extract should have failed on a value without =, but err is nil")
+	}
+}
+
+func TestInterfaceFields(t *testing.T) {
+	opts := testinterfaceoptions{}
+	err := Extract(&opts, WithExtra("hello"), WithExtraNum(42), WithExtraPoint(testpoint{X: 1, Y: 2}), WithLabel("label"))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	if opts.Extra != WithExtra("hello") {
+		t.Fatalf("Extra should be WithExtra(\"hello\"), got %#v", opts.Extra)
+	}
+	if opts.ExtraNum != WithExtraNum(42) {
+		t.Fatalf("ExtraNum should be WithExtraNum(42), got %#v", opts.ExtraNum)
+	}
+	if opts.ExtraPoint != WithExtraPoint(testpoint{X: 1, Y: 2}) {
+		t.Fatalf("ExtraPoint should be WithExtraPoint{1 2}, got %#v", opts.ExtraPoint)
+	}
+	if opts.Label.String() != "label" {
+		t.Fatalf("Label should be 'label', got '%s'", opts.Label)
+	}
+
+	strict := teststringeroptions{}
+	err = Extract(&strict, WithExtra("hello"))
+	if err == nil {
+		t.Fatalf("Extract should have failed fitting a value that does not implement fmt.Stringer, but err is nil")
+	}
+}
+
+func TestReflectValueOptions(t *testing.T) {
+	var held interface{} = WithItem("world")
+
+	opts := testoptions{}
+	err := MustExtract(&opts,
+		reflect.ValueOf(WithUsername("userbob")),
+		reflect.ValueOf(WithItem("hello")),
+		reflect.ValueOf(&held).Elem(),
+		reflect.Value{},
+	)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Username != "userbob" {
+		t.Fatalf("Username should be 'userbob', got '%s'", opts.Username)
+	}
+	if len(opts.Items) != 2 || opts.Items[0] != "hello" || opts.Items[1] != "world" {
+		t.Fatalf("Items should be [hello world], got %v", opts.Items)
+	}
+}
+
+func TestOptNameMethod(t *testing.T) {
+	opts := testoptions{}
+	if err := MustExtract(&opts, testlegacyuser("userbob"), reflect.ValueOf(testlegacyuser("useralice"))); err != nil {
+		t.Fatalf("%s", err)
+	}
+	// the method name wins over the type name
+	if opts.Username != "useralice" {
+		t.Fatalf("Username should be 'useralice', got '%s'", opts.Username)
+	}
+}
+
+func TestNamedSliceFields(t *testing.T) {
+	opts := testnamedsliceoptions{}
+	err := Extract(&opts,
+		WithList{"a", "b"},
+		WithItem("c"),
+		WithItem("d"),
+		WithTag{"x", "y"},
+		WithTag{"z"},
+	)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	// named slices are assigned whole and appended into
+	if len(opts.List) != 2 || opts.List[0] != "a" || opts.List[1] != "b" {
+		t.Fatalf("List should be [a b], got %v", opts.List)
+	}
+	if len(opts.Items) != 2 || opts.Items[0] != "c" || opts.Items[1] != "d" {
+		t.Fatalf("Items should be [c d], got %v", opts.Items)
+	}
+	// elements convert into the named element type
+	if len(opts.Tags) != 1 || opts.Tags[0] != testtag("z") {
+		t.Fatalf("Tags should be [z], got %v", opts.Tags)
+	}
+}
+
 type WithBool bool
 type WithItem string
 type WithUsername string
@@ -53,3 +162,51 @@ type testoptions struct {
 	List      []string `optname:"WithList"`
 	Boolean   bool     `optname:"WithBool"`
 }
+
+type WithEnv string
+
+type testmapoptions struct {
+	Env map[string]string `optname:"WithEnv"`
+}
+
+type WithExtra string
+type WithExtraNum int
+type WithExtraPoint testpoint
+type WithLabel string
+
+func (l WithLabel) String() string {
+	return string(l)
+}
+
+type testpoint struct {
+	X, Y int
+}
+
+type testinterfaceoptions struct {
+	Extra      interface{}  `optname:"WithExtra"`
+	ExtraNum   interface{}  `optname:"WithExtraNum"`
+	ExtraPoint interface{}  `optname:"WithExtraPoint"`
+	Label      fmt.Stringer `optname:"WithLabel"`
+}
+
+type teststringeroptions struct {
+	Stringer fmt.Stringer `optname:"WithExtra"`
+}
+
+type WithTag []string
+
+type testtag string
+type teststringlist []string
+type testtaglist []testtag
+
+type testnamedsliceoptions struct {
+	List  teststringlist `optname:"WithList"`
+	Items teststringlist `optname:"WithItem"`
+	Tags  testtaglist    `optname:"WithTag"`
+}
+
+type testlegacyuser string
+
+func (testlegacyuser) OptName() string {
+	return "WithUsername"
+}
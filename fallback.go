@@ -0,0 +1,65 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// ExtractWithFallbacks extracts options into dest struct, then fits the
+// fallback keyed by optname into every tagged field that is still at its zero
+// value. Options not in dest are skipped.
+//
+// Unlike defaults, fallbacks are applied after the options, but before the
+// passes that end an extraction, such as normalization, truncation, length
+// checks and fingerprints, so a fallback is treated like the option it stands
+// in for. A field counts as unset when reflect.Value.IsZero reports true, so a
+// field an option explicitly set to its zero value still receives the
+// fallback.
+func ExtractWithFallbacks(dest interface{}, fallbacks map[string]interface{}, options ...interface{}) error {
+	return (&extractor{fallbacks: fallbacks}).extract(dest, options...)
+}
+
+// applyFallbacks fits the fallbacks of the extractor into the fields of
+// optionStruct still at their zero value.
+func (x *extractor) applyFallbacks(optionStruct reflect.Value, fieldMap map[string]reflect.StructField) error {
+	// apply in a stable order so errors are deterministic
+	optnames := make([]string, 0, len(x.fallbacks))
+	for optname := range x.fallbacks {
+		optnames = append(optnames, optname)
+	}
+	sort.Strings(optnames)
+
+	for _, optname := range optnames {
+		field, found := x.lookup(fieldMap, optname)
+		if !found {
+			return fmt.Errorf("fallback for invalid option %s", optname)
+		}
+		// a nil pointer along the way leaves the field unset
+		if fieldValue, ok := fieldByIndex(optionStruct, field.Index, false); ok && !fieldValue.IsZero() {
+			continue
+		}
+		if err := x.assign(optionStruct, fieldMap, optname, reflect.ValueOf(x.fallbacks[optname])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package opts
+
+import (
+	"testing"
+)
+
+func TestExtractWithFallbacks(t *testing.T) {
+	fallbacks := map[string]interface{}{
+		"WithUsername": WithUsername("fallback"),
+		"WithPhoneNum": WithPhoneNum(5551234),
+		"WithBool":     WithBool(true),
+	}
+
+	opts := testoptions{}
+	err := ExtractWithFallbacks(&opts, fallbacks, WithUsername("userbob"), WithBool(false))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	if opts.Username != "userbob" {
+		t.Fatalf("Username should be 'userbob', got '%s'", opts.Username)
+	}
+	if opts.PhoneNum != 5551234 {
+		t.Fatalf("PhoneNum should have fallen back to 5551234, got %d", opts.PhoneNum)
+	}
+	// an option explicitly setting the zero value still receives the fallback
+	if !opts.Boolean {
+		t.Fatalf("Boolean should have fallen back to true")
+	}
+
+	// fallbacks go through the passes ending an extraction
+	normalized := testnormalizeoptions{}
+	if err := ExtractWithFallbacks(&normalized, map[string]interface{}{"WithUsername": " UserBob "}); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if normalized.Username != "userbob" {
+		t.Fatalf("Username should have been normalized to 'userbob', got '%s'", normalized.Username)
+	}
+	if err := ExtractWithFallbacks(&testlengthoptions{}, map[string]interface{}{"WithHost": "toolong"}); err == nil {
+		t.Fatalf("ExtractWithFallbacks should have failed on a fallback over maxlen, but err is nil")
+	}
+
+	opts = testoptions{}
+	err = ExtractWithFallbacks(&opts, map[string]interface{}{"WithInvalidOption": true})
+	if err == nil {
+		t.Fatalf("ExtractWithFallbacks should have failed on an invalid fallback, but err is nil")
+	}
+}
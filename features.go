@@ -0,0 +1,62 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// FeatureDisabledError is returned when an option targets a field gated by a
+// feature that isn't enabled.
+type FeatureDisabledError struct {
+	OptName string
+	Feature string
+}
+
+func (e *FeatureDisabledError) Error() string {
+	return fmt.Sprintf("option %s requires the disabled feature %s", e.OptName, e.Feature)
+}
+
+// ExtractWithFeatureFlags extracts options into dest struct, where a field
+// tagged feature:"beta" only accepts options when enabled["beta"] is true.
+// This lets one option vocabulary gate experimental settings. Options not in
+// dest are skipped.
+//
+// An option for a disabled field results in a *FeatureDisabledError rather
+// than being skipped, so that a setting someone asked for is never dropped
+// silently. Other extractions ignore the feature tag.
+func ExtractWithFeatureFlags(dest interface{}, enabled map[string]bool, options ...interface{}) error {
+	features := make(map[string]bool, len(enabled))
+	for feature, on := range enabled {
+		features[feature] = on
+	}
+	return (&extractor{features: features}).extract(dest, options...)
+}
+
+// checkFeature refuses an option for a field gated by a disabled feature.
+func (x *extractor) checkFeature(field reflect.StructField, optname string) error {
+	if x.features == nil {
+		return nil
+	}
+	if feature := field.Tag.Get("feature"); feature != "" && !x.features[feature] {
+		return &FeatureDisabledError{OptName: optname, Feature: feature}
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package opts
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestExtractWithFeatureFlags(t *testing.T) {
+	opts := testfeatureoptions{}
+	err := ExtractWithFeatureFlags(&opts, map[string]bool{"beta": true}, WithUsername("userbob"), WithBool(true))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Username != "userbob" || !opts.Boolean {
+		t.Fatalf("options should have applied, got %+v", opts)
+	}
+
+	var disabled *FeatureDisabledError
+	err = ExtractWithFeatureFlags(&testfeatureoptions{}, map[string]bool{"beta": false}, WithBool(true))
+	if !errors.As(err, &disabled) {
+		t.Fatalf("err should be a *FeatureDisabledError, got %v", err)
+	}
+	if disabled.OptName != "WithBool" || disabled.Feature != "beta" {
+		t.Fatalf("disabled should be {WithBool beta}, got %+v", disabled)
+	}
+	if err := ExtractWithFeatureFlags(&testfeatureoptions{}, nil, WithBool(true)); err == nil {
+		t.Fatalf("ExtractWithFeatureFlags should have failed with no features enabled, but err is nil")
+	}
+
+	// other extractions ignore the feature tag
+	if err := Extract(&testfeatureoptions{}, WithBool(true)); err != nil {
+		t.Fatalf("%s", err)
+	}
+}
+
+type testfeatureoptions struct {
+	Username string `optname:"WithUsername"`
+	Boolean  bool   `optname:"WithBool" feature:"beta"`
+}
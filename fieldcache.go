@@ -0,0 +1,115 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// fieldCacheKey identifies a field map by the struct type it maps and the
+// settings of the extractor that change how it is scanned.
+type fieldCacheKey struct {
+	t           reflect.Type
+	depth       int
+	caseConvert bool
+	strictTags  bool
+}
+
+// fieldCache holds the field maps of the struct types extracted into, so the
+// fields of a type are only scanned by reflection once, along with the group
+// maps of the types that options are routed through. The maps are shared and
+// never modified once cached.
+var fieldCache = struct {
+	mu           sync.RWMutex
+	byType       map[fieldCacheKey]map[string]reflect.StructField
+	groups       map[fieldCacheKey]map[string]reflect.StructField
+	hits, misses atomic.Int64
+}{
+	byType: make(map[fieldCacheKey]map[string]reflect.StructField),
+	groups: make(map[fieldCacheKey]map[string]reflect.StructField),
+}
+
+// CacheStats reports how well the cache of scanned struct fields is working:
+// the lookups it answered, the lookups that had to scan a struct type, and the
+// field maps it holds. A workload whose misses keep growing extracts into ever
+// new types, such as structs built with reflect.StructOf, and gains nothing
+// from the cache.
+//
+// The stats are process-global and count the extractions of every goroutine.
+// Extractions with a resolver from ExtractWithResolver bypass the cache and
+// aren't counted.
+func CacheStats() (hits, misses, entries int) {
+	fieldCache.mu.RLock()
+	entries = len(fieldCache.byType)
+	fieldCache.mu.RUnlock()
+	return int(fieldCache.hits.Load()), int(fieldCache.misses.Load()), entries
+}
+
+// ResetCacheStats zeroes the hits and misses of CacheStats, for tests and for
+// measuring one phase of a workload. The cached field maps are kept. Like the
+// stats, the reset is process-global and affects every caller.
+func ResetCacheStats() {
+	fieldCache.hits.Store(0)
+	fieldCache.misses.Store(0)
+}
+
+// cacheKey returns the key the maps of t are cached under.
+func (x *extractor) cacheKey(t reflect.Type) fieldCacheKey {
+	return fieldCacheKey{t: t, depth: x.depth(), caseConvert: x.caseConvert, strictTags: x.strictTags}
+}
+
+// cachedFields returns the cached field map of t, if any.
+func (x *extractor) cachedFields(t reflect.Type) (map[string]reflect.StructField, fieldCacheKey, bool) {
+	key := x.cacheKey(t)
+	fieldCache.mu.RLock()
+	fieldMap, found := fieldCache.byType[key]
+	fieldCache.mu.RUnlock()
+	if found {
+		fieldCache.hits.Add(1)
+	} else {
+		fieldCache.misses.Add(1)
+	}
+	return fieldMap, key, found
+}
+
+// cacheFields caches the field map scanned for key.
+func cacheFields(key fieldCacheKey, fieldMap map[string]reflect.StructField) {
+	fieldCache.mu.Lock()
+	defer fieldCache.mu.Unlock()
+	fieldCache.byType[key] = fieldMap
+}
+
+// cachedGroups returns the cached group map of t, if any. Group lookups are
+// not counted in CacheStats.
+func (x *extractor) cachedGroups(t reflect.Type) (map[string]reflect.StructField, fieldCacheKey, bool) {
+	key := x.cacheKey(t)
+	fieldCache.mu.RLock()
+	defer fieldCache.mu.RUnlock()
+	groups, found := fieldCache.groups[key]
+	return groups, key, found
+}
+
+// cacheGroups caches the group map scanned for key.
+func cacheGroups(key fieldCacheKey, groups map[string]reflect.StructField) {
+	fieldCache.mu.Lock()
+	defer fieldCache.mu.Unlock()
+	fieldCache.groups[key] = groups
+}
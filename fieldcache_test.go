@@ -0,0 +1,68 @@
+package opts
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestCacheStats(t *testing.T) {
+	type testcachedoptions struct {
+		Username string `optname:"WithUsername"`
+	}
+	ResetCacheStats()
+	_, _, before := CacheStats()
+	for i := 0; i < 3; i++ {
+		if err := Extract(&testcachedoptions{}, WithUsername("userbob")); err != nil {
+			t.Fatalf("%s", err)
+		}
+	}
+	hits, misses, entries := CacheStats()
+	if hits != 2 || misses != 1 || entries != before+1 {
+		t.Fatalf("stats should be 2 hits, 1 miss and %d entries, got %d, %d and %d", before+1, hits, misses, entries)
+	}
+
+	// counters are safe to update from many goroutines
+	ResetCacheStats()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				Extract(&testcachedoptions{}, WithUsername("userbob"))
+			}
+		}()
+	}
+	wg.Wait()
+	if hits, misses, _ := CacheStats(); hits != 800 || misses != 0 {
+		t.Fatalf("stats should be 800 hits and no misses, got %d and %d", hits, misses)
+	}
+
+	// resolvers bypass the cache
+	ResetCacheStats()
+	resolve := func(sf reflect.StructField) (string, bool) {
+		return sf.Tag.Get("optname"), true
+	}
+	if err := ExtractWithResolver(&testcachedoptions{}, resolve, WithUsername("userbob")); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if hits, misses, _ := CacheStats(); hits != 0 || misses != 0 {
+		t.Fatalf("resolved extractions shouldn't be counted, got %d hits and %d misses", hits, misses)
+	}
+}
+
+func TestGroupCache(t *testing.T) {
+	x := &extractor{}
+	first, err := x.mapGroups(reflect.TypeOf(testgroupoptions{}))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	second, err := x.mapGroups(reflect.TypeOf(testgroupoptions{}))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if reflect.ValueOf(first).Pointer() != reflect.ValueOf(second).Pointer() {
+		t.Fatalf("the group map should have been cached")
+	}
+}
@@ -0,0 +1,42 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+// ExtractWithFieldRemap extracts options into dest struct, resolving the field
+// names that tags refer to through fieldRemap, which maps an old field name to
+// the current one. It keeps tags such as requiredif:"Addr==localhost",
+// requiredunless:"Addr" and inherit:"parent.Addr" working while a field is
+// renamed from Addr to Address across a codebase, without editing them all at
+// once. Names not in fieldRemap are used as they are. Options not in dest are
+// skipped.
+//
+// fieldRemap renames fields, not options. Options keep being matched by the
+// optname tags of the fields, so an option whose optname changes lists its old
+// optname in a deprecated tag instead.
+func ExtractWithFieldRemap(dest interface{}, fieldRemap map[string]string, options ...interface{}) error {
+	return (&extractor{fieldRemap: fieldRemap}).extract(dest, options...)
+}
+
+// remapField returns the current name of the field a tag refers to as name.
+func (x *extractor) remapField(name string) string {
+	if current, found := x.fieldRemap[name]; found {
+		return current
+	}
+	return name
+}
@@ -0,0 +1,50 @@
+package opts
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExtractWithFieldRemap(t *testing.T) {
+	remap := map[string]string{"Addr": "Address"}
+
+	// tags still naming the old field fail without the remap
+	err := Extract(&testremapoptions{}, WithHost("example.com"))
+	if err == nil || !strings.Contains(err.Error(), "unknown field Addr") {
+		t.Fatalf("Extract should have failed on the unknown field Addr, got %v", err)
+	}
+
+	opts := testremapoptions{}
+	err = ExtractWithFieldRemap(&opts, remap, WithHost("example.com"))
+	if err == nil || !strings.Contains(err.Error(), "option WithPort is required when Addr==example.com") {
+		t.Fatalf("WithPort should be required once Address is example.com, got %v", err)
+	}
+
+	opts = testremapoptions{}
+	err = ExtractWithFieldRemap(&opts, remap, WithHost("example.com"), WithPort(443))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Admin.Address != "example.com" {
+		t.Fatalf("Admin.Address should be inherited as 'example.com', got '%s'", opts.Admin.Address)
+	}
+
+	// requiredunless is satisfied through the remapped name
+	err = ExtractWithFieldRemap(&testremapunlessoptions{}, remap, WithHost("example.com"))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+}
+
+type testremapoptions struct {
+	Address string `optname:"WithHost"`
+	Port    int    `optname:"WithPort" requiredif:"Addr==example.com"`
+	Admin   struct {
+		Address string `inherit:"parent.Addr"`
+	}
+}
+
+type testremapunlessoptions struct {
+	Address string `optname:"WithHost"`
+	Port    int    `optname:"WithPort" requiredunless:"Addr"`
+}
@@ -0,0 +1,327 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// defaultMaxDepth bounds how deeply nested structs are scanned for optname
+// tags when an extraction doesn't set its own limit.
+const defaultMaxDepth = 32
+
+// ExtractWithMaxDepth extracts options into dest struct, scanning nested
+// structs for optname tags at most depth levels deep, so a depth of 1 scans
+// the structs nested directly in dest but not those nested in them. depth must
+// be positive, and anything less results in error. Options not in dest are
+// skipped.
+func ExtractWithMaxDepth(dest interface{}, depth int, options ...interface{}) error {
+	if depth < 1 {
+		return fmt.Errorf("max depth must be positive, got %d", depth)
+	}
+	return (&extractor{maxDepth: depth}).extract(dest, options...)
+}
+
+// mapFields maps the optname tags of a struct type to their fields.
+//
+// Untagged struct fields, and pointers to structs, are scanned recursively so
+// their tagged fields share the namespace of the outer struct. The Index of
+// every mapped field is its full path from t. A struct type already being
+// scanned further up is not entered again, which keeps self-referential types
+// finite.
+//
+// The maps are cached by type, except for extractions naming fields with a
+// resolver, so callers must not modify them.
+func (x *extractor) mapFields(t reflect.Type) (map[string]reflect.StructField, error) {
+	if x.resolve != nil {
+		return x.scanFieldMap(t)
+	}
+	fieldMap, key, found := x.cachedFields(t)
+	if found {
+		return fieldMap, nil
+	}
+	fieldMap, err := x.scanFieldMap(t)
+	if err != nil {
+		return nil, err
+	}
+	cacheFields(key, fieldMap)
+	return fieldMap, nil
+}
+
+// scanFieldMap scans the field map of t.
+func (x *extractor) scanFieldMap(t reflect.Type) (map[string]reflect.StructField, error) {
+	fieldMap := make(map[string]reflect.StructField)
+	if err := x.scanFields(t, nil, map[reflect.Type]bool{}, x.depth(), fieldMap, nil); err != nil {
+		return nil, err
+	}
+	if err := checkDeprecatedNames(fieldMap); err != nil {
+		return nil, err
+	}
+	return fieldMap, nil
+}
+
+// mapGroups maps the group tags of a struct type to their fields. Grouped
+// structs are not scanned into the namespace of the outer struct, they have
+// namespaces of their own. Like field maps, group maps are cached by type
+// except for extractions with a resolver.
+func (x *extractor) mapGroups(t reflect.Type) (map[string]reflect.StructField, error) {
+	if x.resolve != nil {
+		return x.scanGroups(t)
+	}
+	groups, key, found := x.cachedGroups(t)
+	if found {
+		return groups, nil
+	}
+	groups, err := x.scanGroups(t)
+	if err != nil {
+		return nil, err
+	}
+	cacheGroups(key, groups)
+	return groups, nil
+}
+
+// scanGroups scans the group map of t.
+func (x *extractor) scanGroups(t reflect.Type) (map[string]reflect.StructField, error) {
+	groups := make(map[string]reflect.StructField)
+	if err := x.scanFields(t, nil, map[reflect.Type]bool{}, x.depth(), make(map[string]reflect.StructField), groups); err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// depth returns how deeply nested structs are scanned.
+func (x *extractor) depth() int {
+	if x.maxDepth == 0 {
+		return defaultMaxDepth
+	}
+	return x.maxDepth
+}
+
+// scanFields adds the tagged fields of t, found at index, into fieldMap and
+// the group fields into groups when it isn't nil. visiting holds the struct
+// types being scanned on the way down to t.
+func (x *extractor) scanFields(t reflect.Type, index []int, visiting map[reflect.Type]bool, depth int, fieldMap, groups map[string]reflect.StructField) error {
+	if depth < 0 {
+		return fmt.Errorf("nesting too deep at %s", t.String())
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		sf.Index = append(append([]int{}, index...), i)
+		if x.strictTags {
+			if err := checkTagKeys(sf); err != nil {
+				return err
+			}
+		}
+
+		// grouped structs keep a namespace of their own
+		if group, found := sf.Tag.Lookup("group"); found && isStruct(sf.Type) {
+			if groups != nil {
+				if _, found := groups[group]; found {
+					return fmt.Errorf("group %s has multiple tagged fields", group)
+				}
+				groups[group] = sf
+			}
+			if x.fieldName(sf) == "" {
+				continue
+			}
+		}
+
+		optname := x.fieldName(sf)
+		if optname == "" {
+			// recurse into nested structs that can be assigned into
+			if !isStruct(sf.Type) {
+				continue
+			}
+			nested := sf.Type
+			if nested.Kind() == reflect.Ptr {
+				nested = nested.Elem()
+			}
+			if visiting[nested] {
+				continue
+			}
+			if !sf.IsExported() && !(sf.Anonymous && sf.Type.Kind() == reflect.Struct) {
+				continue
+			}
+			if err := x.scanFields(nested, sf.Index, visiting, depth-1, fieldMap, groups); err != nil {
+				return err
+			}
+			continue
+		}
+		// make sure this option is not already in use
+		if _, found := fieldMap[optname]; found {
+			return fmt.Errorf("option name %s has multiple tagged fields", optname)
+		}
+		if err := checkTags(sf); err != nil {
+			return err
+		}
+		// store for assignments
+		fieldMap[optname] = sf
+	}
+	return nil
+}
+
+// fieldName returns the optname of a field, or "" for a field options can't
+// name. It comes from the extractor's resolver when it has one, and otherwise
+// from the optname tag.
+func (x *extractor) fieldName(sf reflect.StructField) string {
+	if x.resolve != nil {
+		optname, ok := x.resolve(sf)
+		if !ok {
+			return ""
+		}
+		return optname
+	}
+	// use optname tags
+	optname := sf.Tag.Get("optname")
+	if optname == "" && x.caseConvert && sf.IsExported() && !isStruct(sf.Type) {
+		optname = caseKey(sf.Name)
+	}
+	return optname
+}
+
+// ExtractWithResolver extracts options into dest struct, naming its fields
+// with resolve instead of optname tags, so structs that can't be tagged can
+// follow any naming convention, such as another tag or a transformed field
+// name. resolve is called for every field as it is scanned and reports false
+// for fields options can't name. Struct fields it reports false for are
+// scanned into, as untagged structs are. Two fields resolving to the same
+// optname result in error. Options not in dest are skipped.
+func ExtractWithResolver(dest interface{}, resolve func(reflect.StructField) (optname string, ok bool), options ...interface{}) error {
+	return (&extractor{resolve: resolve}).extract(dest, options...)
+}
+
+// isStruct reports whether t is a struct or a pointer to one.
+func isStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
+
+// lookup finds the field for optname. With case conversion, names that don't
+// match a tag exactly are matched against the derived names of untagged fields.
+// Names that still match nothing are matched against deprecated tags.
+func (x *extractor) lookup(fieldMap map[string]reflect.StructField, optname string) (reflect.StructField, bool) {
+	field, found := fieldMap[optname]
+	if !found && x.caseConvert {
+		field, found = fieldMap[caseKey(optname)]
+		// explicit tags are only matched exactly
+		if found && field.Tag.Get("optname") != "" {
+			return reflect.StructField{}, false
+		}
+	}
+	if !found {
+		// fall back to the optnames fields used to go by
+		field, found = lookupDeprecated(fieldMap, optname)
+	}
+	return field, found
+}
+
+// checkTags validates the tags of a mapped field, so mistakes surface before
+// any option is assigned.
+func checkTags(sf reflect.StructField) error {
+	if transforms, err := parseNormalize(sf.Tag.Get("normalize")); err != nil {
+		return fmt.Errorf("field %s: %s", sf.Name, err)
+	} else if transforms != nil && !normalizable(sf.Type) {
+		return fmt.Errorf("field %s: normalize only applies to strings", sf.Name)
+	}
+	if encoding, found := sf.Tag.Lookup("encoding"); found {
+		if _, known := decoders[encoding]; !known {
+			return fmt.Errorf("field %s: unknown encoding %q", sf.Name, encoding)
+		}
+	}
+	if kind, found := sf.Tag.Lookup("quantity"); found && !quantityKinds[kind] {
+		return fmt.Errorf("field %s: unknown quantity %q", sf.Name, kind)
+	}
+	if mode := sf.Tag.Get("slicemode"); mode != "" && mode != "replace" && mode != "append" {
+		return fmt.Errorf("field %s: unknown slicemode %q", sf.Name, mode)
+	}
+	if _, found := sf.Tag.Lookup("const"); found {
+		if _, hasDefault := sf.Tag.Lookup("default"); hasDefault {
+			return fmt.Errorf("field %s: const and default can't be combined", sf.Name)
+		}
+	}
+	if _, err := parsePipeline(sf.Tag.Get("pipeline")); err != nil {
+		return fmt.Errorf("field %s: %s", sf.Name, err)
+	}
+	if err := checkLengthTags(sf); err != nil {
+		return fmt.Errorf("field %s: %s", sf.Name, err)
+	}
+	for _, name := range deprecatedNames(sf) {
+		if name == "" {
+			return fmt.Errorf("field %s: deprecated lists an empty optname", sf.Name)
+		}
+	}
+	if tag, found := sf.Tag.Lookup("requiredif"); found {
+		if _, err := parseCondition(tag); err != nil {
+			return fmt.Errorf("field %s: %s", sf.Name, err)
+		}
+	}
+	if tag, found := sf.Tag.Lookup("requiredunless"); found {
+		for _, name := range strings.Split(tag, ",") {
+			if strings.TrimSpace(name) == "" {
+				return fmt.Errorf("field %s: requiredunless lists an empty field", sf.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// fieldByIndex returns the nested field of v at index. Nil pointers along the
+// way are allocated when alloc is set, otherwise ok is false.
+func fieldByIndex(v reflect.Value, index []int, alloc bool) (field reflect.Value, ok bool) {
+	for i, step := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !alloc {
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(step)
+	}
+	return v, true
+}
+
+// orderedFields returns the optnames of fieldMap in the order their fields are
+// declared, depth first through nested structs.
+func orderedFields(fieldMap map[string]reflect.StructField) []string {
+	optnames := make([]string, 0, len(fieldMap))
+	for optname := range fieldMap {
+		optnames = append(optnames, optname)
+	}
+	sort.Slice(optnames, func(i, j int) bool {
+		a, b := fieldMap[optnames[i]].Index, fieldMap[optnames[j]].Index
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
+	return optnames
+}
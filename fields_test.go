@@ -0,0 +1,147 @@
+package opts
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNestedFields(t *testing.T) {
+	opts := testnestedoptions{}
+	err := Extract(&opts, WithUsername("userbob"), WithHost("localhost"), WithPort(8080), WithItem("hello"))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Username != "userbob" {
+		t.Fatalf("Username should be 'userbob', got '%s'", opts.Username)
+	}
+	if opts.Server.Host != "localhost" {
+		t.Fatalf("Server.Host should be 'localhost', got '%s'", opts.Server.Host)
+	}
+	if opts.Server.Port != 8080 {
+		t.Fatalf("Server.Port should be 8080, got %d", opts.Server.Port)
+	}
+	if len(opts.Items) != 1 || opts.Items[0] != "hello" {
+		t.Fatalf("embedded Items should be [hello], got %v", opts.Items)
+	}
+}
+
+func TestExtractWithMaxDepth(t *testing.T) {
+	opts := testnestedoptions{}
+	err := ExtractWithMaxDepth(&opts, 1, WithHost("localhost"))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	deep := testdeepoptions{}
+	err = ExtractWithMaxDepth(&deep, 1, WithHost("localhost"))
+	if err == nil {
+		t.Fatalf("ExtractWithMaxDepth should have failed, but err is nil")
+	}
+	if !strings.HasPrefix(err.Error(), "nesting too deep") {
+		t.Fatalf("ExtractWithMaxDepth should have failed with nesting too deep, got '%s'", err)
+	}
+
+	deep = testdeepoptions{}
+	err = ExtractWithMaxDepth(&deep, 2, WithHost("localhost"))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if deep.Outer.Inner.Host != "localhost" {
+		t.Fatalf("Outer.Inner.Host should be 'localhost', got '%s'", deep.Outer.Inner.Host)
+	}
+
+	for _, depth := range []int{0, -1} {
+		err = ExtractWithMaxDepth(&opts, depth, WithHost("localhost"))
+		if err == nil || err.Error() != fmt.Sprintf("max depth must be positive, got %d", depth) {
+			t.Fatalf("ExtractWithMaxDepth(%d) should have failed with max depth must be positive, got '%v'", depth, err)
+		}
+	}
+}
+
+func TestSelfReferentialFields(t *testing.T) {
+	node := testnode{}
+	err := Extract(&node, WithName(7), WithUsername("userbob"))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if node.Label != "userbob" {
+		t.Fatalf("Label should be 'userbob', got '%s'", node.Label)
+	}
+	if node.Next != nil || node.Attrs.Parent != nil {
+		t.Fatalf("self-referential fields should not be entered")
+	}
+}
+
+func TestExtractWithResolver(t *testing.T) {
+	// name fields after a cfg tag, prefixed
+	resolve := func(sf reflect.StructField) (string, bool) {
+		name, found := sf.Tag.Lookup("cfg")
+		if !found {
+			return "", false
+		}
+		return "With" + name, true
+	}
+	opts := testresolveroptions{}
+	if err := ExtractWithResolver(&opts, resolve, WithHost("localhost"), WithPort(8080)); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Address != "localhost" || opts.Server.Port != 8080 {
+		t.Fatalf("resolved fields should have been set, got %+v", opts)
+	}
+
+	// the optname tag is no longer read
+	opts = testresolveroptions{}
+	if err := ExtractWithResolver(&opts, resolve, WithUsername("userbob")); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Username != "" {
+		t.Fatalf("Username should not have been set, got '%s'", opts.Username)
+	}
+
+	everything := func(sf reflect.StructField) (string, bool) { return "WithAll", true }
+	if err := ExtractWithResolver(&testresolveroptions{}, everything); err == nil {
+		t.Fatalf("ExtractWithResolver should have failed on duplicate optnames, but err is nil")
+	}
+}
+
+type testresolveroptions struct {
+	Address  string `cfg:"Host"`
+	Username string `optname:"WithUsername"`
+	Server   struct {
+		Port int `cfg:"Port"`
+	}
+}
+
+type WithHost string
+type WithPort int
+
+type testserver struct {
+	Host string `optname:"WithHost"`
+	Port int    `optname:"WithPort"`
+}
+
+type testembedded struct {
+	Items []string `optname:"WithItem"`
+}
+
+type testnestedoptions struct {
+	testembedded
+	Username string `optname:"WithUsername"`
+	Server   *testserver
+}
+
+type testdeepoptions struct {
+	Outer struct {
+		Inner *testserver
+	}
+}
+
+type testnode struct {
+	Label string `optname:"WithUsername"`
+	Next  *testnode
+	Attrs struct {
+		Parent *testnode
+	}
+}
@@ -0,0 +1,206 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// setFingerprints sets the fields tagged fingerprint:"true" to a hash of every
+// other tagged field once options have been assigned and normalized, so that a
+// config can be cheaply compared with the one before a reload.
+//
+// The hash is the SHA-256 of a line of optname=value per tagged field, sorted
+// by optname, with each value encoded by valueEncoder, which is stable across
+// runs. A string fingerprint holds the hash in hex and a []byte fingerprint
+// holds it raw. Fingerprint fields are left out of the hash, so they don't feed
+// into each other. Values that can't be hashed, such as funcs, result in error.
+func setFingerprints(optionStruct reflect.Value, fieldMap map[string]reflect.StructField) error {
+	var fingerprints, optnames []string
+	for optname, field := range fieldMap {
+		if field.Tag.Get("fingerprint") == "true" {
+			fingerprints = append(fingerprints, optname)
+			continue
+		}
+		optnames = append(optnames, optname)
+	}
+	if len(fingerprints) == 0 {
+		return nil
+	}
+	sort.Strings(optnames)
+
+	hash := sha256.New()
+	for _, optname := range optnames {
+		var encoded bytes.Buffer
+		fieldValue, _ := fieldByIndex(optionStruct, fieldMap[optname].Index, false)
+		encoder := &valueEncoder{marshalText: true, visiting: map[uintptr]bool{}}
+		if err := encoder.encode(&encoded, fieldValue); err != nil {
+			return fmt.Errorf("fingerprint of %s: %s", optname, err)
+		}
+		fmt.Fprintf(hash, "%s=%s\n", optname, encoded.Bytes())
+	}
+	sum := hash.Sum(nil)
+
+	for _, optname := range fingerprints {
+		field := fieldMap[optname]
+		fieldValue, _ := fieldByIndex(optionStruct, field.Index, true)
+		switch {
+		case fieldValue.Kind() == reflect.String:
+			fieldValue.SetString(hex.EncodeToString(sum))
+		case fieldValue.Kind() == reflect.Slice && fieldValue.Type().Elem().Kind() == reflect.Uint8:
+			fieldValue.SetBytes(append([]byte(nil), sum...))
+		default:
+			return fmt.Errorf("field %s: fingerprint must be a string or []byte", field.Name)
+		}
+	}
+	return nil
+}
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// valueEncoder writes values for hashing. Values are read by reflection, so
+// unexported fields and the value held by a Secret are hashed rather than the
+// masked or empty forms their methods and JSON give. Map entries are sorted by
+// their encoding, and a pointer already on the way down to a value is written
+// as a cycle rather than followed again.
+type valueEncoder struct {
+	// marshalText writes types that marshal to text, such as time.Time, as
+	// their text, since their fields aren't stable for equal values
+	marshalText bool
+	// the pointers on the way down to the value being encoded
+	visiting map[uintptr]bool
+}
+
+// encode writes v to buf.
+func (e *valueEncoder) encode(buf *bytes.Buffer, v reflect.Value) error {
+	if !v.IsValid() || ((v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil()) {
+		buf.WriteString("null")
+		return nil
+	}
+	if e.marshalText && v.CanInterface() && v.Type().Implements(textMarshalerType) {
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return err
+		}
+		buf.WriteString(strconv.Quote(string(text)))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		buf.WriteString(strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		buf.WriteString(strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		buf.WriteString(strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		buf.WriteString(strconv.FormatFloat(v.Float(), 'g', -1, 64))
+	case reflect.Complex64, reflect.Complex128:
+		buf.WriteString(strconv.FormatComplex(v.Complex(), 'g', -1, 128))
+	case reflect.String:
+		buf.WriteString(strconv.Quote(v.String()))
+	case reflect.Ptr:
+		if e.visiting[v.Pointer()] {
+			buf.WriteString("cycle")
+			return nil
+		}
+		e.visiting[v.Pointer()] = true
+		defer delete(e.visiting, v.Pointer())
+		buf.WriteByte('&')
+		return e.encode(buf, v.Elem())
+	case reflect.Interface:
+		buf.WriteString(typeName(v.Elem().Type()))
+		buf.WriteByte(':')
+		return e.encode(buf, v.Elem())
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+		buf.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := e.encode(buf, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case reflect.Map:
+		if v.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+		entries := make([]string, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			var entry bytes.Buffer
+			if err := e.encode(&entry, iter.Key()); err != nil {
+				return err
+			}
+			entry.WriteByte(':')
+			if err := e.encode(&entry, iter.Value()); err != nil {
+				return err
+			}
+			entries = append(entries, entry.String())
+		}
+		sort.Strings(entries)
+		buf.WriteByte('{')
+		for i, entry := range entries {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString(entry)
+		}
+		buf.WriteByte('}')
+	case reflect.Struct:
+		buf.WriteByte('{')
+		for i := 0; i < v.NumField(); i++ {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString(v.Type().Field(i).Name)
+			buf.WriteByte(':')
+			if err := e.encode(buf, v.Field(i)); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return fmt.Errorf("values of kind %s can't be hashed", v.Kind().String())
+	}
+	return nil
+}
+
+// typeName names t along with the path of its package, so types of the same
+// name from different packages are told apart.
+func typeName(t reflect.Type) string {
+	if t.PkgPath() == "" {
+		return t.String()
+	}
+	return t.PkgPath() + ":" + t.String()
+}
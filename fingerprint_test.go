@@ -0,0 +1,65 @@
+package opts
+
+import (
+	"testing"
+)
+
+func TestFingerprint(t *testing.T) {
+	first := testfingerprintoptions{}
+	if err := Extract(&first, WithUsername("userbob"), WithEnv("a=1"), WithEnv("b=2")); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(first.Fingerprint) != 64 || len(first.Raw) != 32 {
+		t.Fatalf("fingerprints should be a hex and a raw SHA-256, got %q and %d bytes", first.Fingerprint, len(first.Raw))
+	}
+
+	// equal configs have equal fingerprints regardless of option order
+	second := testfingerprintoptions{}
+	if err := Extract(&second, WithEnv("b=2"), WithEnv("a=1"), WithUsername("userbob")); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if first.Fingerprint != second.Fingerprint {
+		t.Fatalf("fingerprints should match, got %s and %s", first.Fingerprint, second.Fingerprint)
+	}
+
+	changed := testfingerprintoptions{}
+	if err := Extract(&changed, WithUsername("useralice"), WithEnv("a=1"), WithEnv("b=2")); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if first.Fingerprint == changed.Fingerprint {
+		t.Fatalf("fingerprints should differ when a field changes")
+	}
+
+	// secrets are hashed by the value they hold
+	secret := testsecretfingerprintoptions{}
+	if err := Extract(&secret, WithPassword("hunter2")); err != nil {
+		t.Fatalf("%s", err)
+	}
+	otherSecret := testsecretfingerprintoptions{}
+	if err := Extract(&otherSecret, WithPassword("swordfish")); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if secret.Fingerprint == otherSecret.Fingerprint {
+		t.Fatalf("fingerprints should differ when a secret changes")
+	}
+
+	if err := Extract(&testbadfingerprintoptions{}); err == nil {
+		t.Fatalf("Extract should have failed on an int fingerprint, but err is nil")
+	}
+}
+
+type testfingerprintoptions struct {
+	Username    string            `optname:"WithUsername"`
+	Env         map[string]string `optname:"WithEnv"`
+	Fingerprint string            `optname:"WithFingerprint" fingerprint:"true"`
+	Raw         []byte            `optname:"WithRawFingerprint" fingerprint:"true"`
+}
+
+type testbadfingerprintoptions struct {
+	Fingerprint int `optname:"WithFingerprint" fingerprint:"true"`
+}
+
+type testsecretfingerprintoptions struct {
+	Password    Secret[string] `optname:"WithPassword"`
+	Fingerprint string         `optname:"WithFingerprint" fingerprint:"true"`
+}
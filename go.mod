@@ -1,3 +1,12 @@
 module github.com/protosam/opts
 
-go 1.17
+go 1.21
+
+require (
+	github.com/Masterminds/semver/v3 v3.2.1
+	golang.org/x/crypto v0.31.0
+	golang.org/x/text v0.21.0
+	google.golang.org/grpc v1.60.1
+)
+
+require golang.org/x/sys v0.28.0 // indirect
@@ -0,0 +1,110 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// groupOption carries options bound for the struct field tagged with a group.
+type groupOption struct {
+	name    string
+	options []interface{}
+}
+
+// WithGroup bundles options for the nested struct field tagged group:"name".
+// When extracted, the options are extracted into that struct, which keeps a
+// namespace of its own instead of sharing the outer struct's. This lets
+// hierarchical option bundles map onto hierarchical config.
+//
+// The grouped options are extracted with the same settings as the outer
+// extraction, so under MustExtract an unknown option inside the group, or an
+// unknown group, results in error while Extract skips them.
+//
+// Options that match no field of the outer struct are also routed into groups
+// by their name. An option named With, the group name and the rest, such as
+// WithDBHost for the group DB, is extracted into the group as WithHost. A
+// field of the outer struct always wins over a group, and when the names of
+// several groups match, the longest group name holding the rest wins, so
+// WithDBReplicaHost goes to a DBReplica group before a DB group.
+func WithGroup(name string, options ...interface{}) interface{} {
+	grouped := make([]interface{}, len(options))
+	copy(grouped, options)
+	return groupOption{name: name, options: grouped}
+}
+
+// extractGroup extracts the options of group into its struct field.
+func (x *extractor) extractGroup(optionStruct reflect.Value, group groupOption) error {
+	groups, err := x.mapGroups(optionStruct.Type())
+	if err != nil {
+		return err
+	}
+	field, found := groups[group.name]
+	if !found {
+		if !x.mustFind {
+			return nil
+		}
+		return fmt.Errorf("invalid group %s", group.name)
+	}
+
+	fieldValue, _ := fieldByIndex(optionStruct, field.Index, true)
+	if fieldValue.Kind() == reflect.Ptr {
+		if fieldValue.IsNil() {
+			fieldValue.Set(reflect.New(fieldValue.Type().Elem()))
+		}
+		return x.extract(fieldValue.Interface(), group.options...)
+	}
+	return x.extract(fieldValue.Addr().Interface(), group.options...)
+}
+
+// routeGroup finds the group an option that matched no field is routed to by
+// its name, returning the option to extract in its place.
+func (x *extractor) routeGroup(optionStruct reflect.Value, optname string, optionValue reflect.Value) (groupOption, bool, error) {
+	base := strings.TrimPrefix(optname, "With")
+	if !optionValue.IsValid() || base == optname {
+		return groupOption{}, false, nil
+	}
+	groups, err := x.mapGroups(optionStruct.Type())
+	if err != nil || len(groups) == 0 {
+		return groupOption{}, false, err
+	}
+
+	var routed groupOption
+	for group, field := range groups {
+		rest := strings.TrimPrefix(base, group)
+		if rest == base || rest == "" || len(group) <= len(routed.name) {
+			continue
+		}
+		nested := field.Type
+		if nested.Kind() == reflect.Ptr {
+			nested = nested.Elem()
+		}
+		fieldMap, err := x.mapFields(nested)
+		if err != nil {
+			return groupOption{}, false, err
+		}
+		if _, found := x.lookup(fieldMap, "With"+rest); !found {
+			continue
+		}
+		routed = groupOption{name: group, options: []interface{}{namedOption{name: "With" + rest, value: optionValue.Interface()}}}
+	}
+	return routed, routed.name != "", nil
+}
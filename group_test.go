@@ -0,0 +1,98 @@
+package opts
+
+import (
+	"testing"
+)
+
+func TestWithGroup(t *testing.T) {
+	opts := testgroupoptions{}
+	err := MustExtract(&opts,
+		WithUsername("userbob"),
+		WithGroup("DB", WithHost("db.internal"), WithPort(5432)),
+		WithGroup("Cache", WithHost("cache.internal")),
+		WithHost("app.internal"),
+	)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Username != "userbob" || opts.Host != "app.internal" {
+		t.Fatalf("outer options should have applied, got %+v", opts)
+	}
+	if opts.DB.Host != "db.internal" || opts.DB.Port != 5432 {
+		t.Fatalf("DB should be {db.internal 5432}, got %+v", opts.DB)
+	}
+	if opts.Cache == nil || opts.Cache.Host != "cache.internal" {
+		t.Fatalf("Cache should be {cache.internal 0}, got %+v", opts.Cache)
+	}
+
+	// strictness carries into the group
+	opts = testgroupoptions{}
+	if err := MustExtract(&opts, WithGroup("DB", WithUsername("userbob"))); err == nil {
+		t.Fatalf("MustExtract should have failed on an unknown option in a group, but err is nil")
+	}
+	if err := MustExtract(&opts, WithGroup("Queue")); err == nil {
+		t.Fatalf("MustExtract should have failed on an unknown group, but err is nil")
+	}
+	if err := Extract(&opts, WithGroup("Queue"), WithGroup("DB", WithUsername("userbob"))); err != nil {
+		t.Fatalf("%s", err)
+	}
+}
+
+func TestGroupRouting(t *testing.T) {
+	opts := testroutedoptions{}
+	err := MustExtract(&opts,
+		WithHost("app.internal"),
+		WithDBHost("db.internal"),
+		WithDBPort(5432),
+		WithDBReplicaHost("replica.internal"),
+	)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Host != "app.internal" {
+		t.Fatalf("Host should be 'app.internal', got '%s'", opts.Host)
+	}
+	if opts.DB.Host != "db.internal" || opts.DB.Port != 5432 {
+		t.Fatalf("DB should be {db.internal 5432}, got %+v", opts.DB)
+	}
+	// the longest group name wins
+	if opts.DBReplica == nil || opts.DBReplica.Host != "replica.internal" {
+		t.Fatalf("DBReplica should be {replica.internal 0}, got %+v", opts.DBReplica)
+	}
+
+	// a field of the outer struct wins over a group
+	opts = testroutedoptions{}
+	if err := MustExtract(&opts, WithDBName("appdb")); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.DBName != "appdb" {
+		t.Fatalf("DBName should be 'appdb', got '%s'", opts.DBName)
+	}
+
+	if err := MustExtract(&opts, WithDBUsername("userbob")); err == nil {
+		t.Fatalf("MustExtract should have failed on an option the group doesn't have, but err is nil")
+	}
+	if err := MustConsumeExtract(&testroutedoptions{}, WithDBHost("db.internal")); err != nil {
+		t.Fatalf("%s", err)
+	}
+}
+
+type WithDBHost string
+type WithDBPort int
+type WithDBReplicaHost string
+type WithDBName string
+type WithDBUsername string
+
+type testroutedoptions struct {
+	Host      string      `optname:"WithHost"`
+	DBName    string      `optname:"WithDBName"`
+	DB        testserver  `group:"DB"`
+	DBReplica *testserver `group:"DBReplica"`
+}
+
+type testgroupoptions struct {
+	Username string      `optname:"WithUsername"`
+	Host     string      `optname:"WithHost"`
+	DB       testserver  `group:"DB"`
+	Cache    *testserver `group:"Cache"`
+}
@@ -0,0 +1,47 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+// Package grpcopts extracts gRPC metadata into option structs. It is kept
+// apart from opts so that only programs using it depend on gRPC.
+package grpcopts
+
+import (
+	"strings"
+
+	"github.com/protosam/opts"
+	"google.golang.org/grpc/metadata"
+)
+
+// ExtractMetadata extracts gRPC metadata into dest struct, so a server can turn
+// the metadata of a call into a typed config. Keys not in dest are skipped.
+//
+// gRPC lowercases metadata keys, so a key matches the field tagged metadata
+// with the same name, or otherwise the field whose optname is the same name,
+// after both sides are lowercased. The key withusername matches the optname
+// WithUsername. Values are parsed into the kind of the field as with
+// opts.ExtractWithCoercion. A slice field takes every value of a key in order,
+// any other field takes the first value.
+func ExtractMetadata(dest interface{}, md metadata.MD) error {
+	return opts.ExtractValues(dest, md, "metadata", strings.ToLower)
+}
+
+// MustExtractMetadata is ExtractMetadata where keys not in dest result in
+// error.
+func MustExtractMetadata(dest interface{}, md metadata.MD) error {
+	return opts.MustExtractValues(dest, md, "metadata", strings.ToLower)
+}
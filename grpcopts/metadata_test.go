@@ -0,0 +1,41 @@
+package grpcopts
+
+import (
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+)
+
+func TestExtractMetadata(t *testing.T) {
+	md := metadata.Pairs(
+		"x-request-id", "abc123",
+		"withretries", "3",
+		"x-tag", "a",
+		"x-tag", "b",
+		"x-unknown", "skipped",
+	)
+
+	opts := testmetadataoptions{}
+	if err := ExtractMetadata(&opts, md); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.RequestID != "abc123" {
+		t.Fatalf("RequestID should be 'abc123', got '%s'", opts.RequestID)
+	}
+	if opts.Retries != 3 {
+		t.Fatalf("Retries should be 3, got %d", opts.Retries)
+	}
+	if len(opts.Tags) != 2 || opts.Tags[0] != "a" || opts.Tags[1] != "b" {
+		t.Fatalf("Tags should be [a b], got %v", opts.Tags)
+	}
+
+	if err := MustExtractMetadata(&testmetadataoptions{}, md); err == nil {
+		t.Fatalf("MustExtractMetadata should have failed on x-unknown, but err is nil")
+	}
+}
+
+type testmetadataoptions struct {
+	RequestID string   `optname:"WithRequestID" metadata:"X-Request-ID"`
+	Retries   int      `optname:"WithRetries"`
+	Tags      []string `optname:"WithTag" metadata:"x-tag"`
+}
@@ -0,0 +1,50 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"net/http"
+	"net/textproto"
+)
+
+// ExtractHeaders extracts HTTP headers into dest struct, so middleware can turn
+// a request's headers into a typed config. Headers not in dest are skipped.
+//
+// A header matches the field tagged header with the same name, or otherwise the
+// field whose optname is the same name. Both sides are canonicalized with
+// textproto.CanonicalMIMEHeaderKey first, so header:"x-request-id" matches
+// X-Request-Id, and an optname of WithUsername matches the header Withusername.
+//
+// Values are parsed into the kind of the field as with ExtractWithCoercion. A
+// slice field takes every value of a repeated header in order, any other field
+// takes the first value, the same one http.Header.Get returns.
+func ExtractHeaders(dest interface{}, h http.Header) error {
+	return extractHeaders(dest, h, false)
+}
+
+// MustExtractHeaders is ExtractHeaders where headers not in dest result in
+// error.
+func MustExtractHeaders(dest interface{}, h http.Header) error {
+	return extractHeaders(dest, h, true)
+}
+
+// Underlying headers function.
+func extractHeaders(dest interface{}, h http.Header, mustFind bool) error {
+	return extractValues(dest, h, "header", textproto.CanonicalMIMEHeaderKey, mustFind)
+}
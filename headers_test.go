@@ -0,0 +1,52 @@
+package opts
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestExtractHeaders(t *testing.T) {
+	h := http.Header{}
+	h.Set("x-request-id", "abc123")
+	h.Set("Withphonenum", "8675309")
+	h.Add("X-Forwarded-For", "10.0.0.1")
+	h.Add("X-Forwarded-For", "10.0.0.2")
+	h.Add("X-Retries", "3")
+	h.Add("X-Retries", "4")
+	h.Set("X-Unknown", "skipped")
+
+	opts := testheaderoptions{}
+	if err := ExtractHeaders(&opts, h); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.RequestID != "abc123" {
+		t.Fatalf("RequestID should be 'abc123', got '%s'", opts.RequestID)
+	}
+	if opts.PhoneNum != 8675309 {
+		t.Fatalf("PhoneNum should be 8675309, got %d", opts.PhoneNum)
+	}
+	if len(opts.Forwarded) != 2 || opts.Forwarded[0] != "10.0.0.1" || opts.Forwarded[1] != "10.0.0.2" {
+		t.Fatalf("Forwarded should be [10.0.0.1 10.0.0.2], got %v", opts.Forwarded)
+	}
+	// scalars take the first value
+	if opts.Retries != 3 {
+		t.Fatalf("Retries should be 3, got %d", opts.Retries)
+	}
+
+	if err := MustExtractHeaders(&testheaderoptions{}, h); err == nil {
+		t.Fatalf("MustExtractHeaders should have failed on X-Unknown, but err is nil")
+	}
+
+	h = http.Header{}
+	h.Set("X-Retries", "many")
+	if err := ExtractHeaders(&testheaderoptions{}, h); err == nil {
+		t.Fatalf("ExtractHeaders should have failed parsing X-Retries, but err is nil")
+	}
+}
+
+type testheaderoptions struct {
+	RequestID string   `optname:"WithRequestID" header:"x-request-id"`
+	PhoneNum  int      `optname:"WithPhoneNum"`
+	Forwarded []string `optname:"WithForwarded" header:"X-Forwarded-For"`
+	Retries   int      `optname:"WithRetries" header:"X-Retries"`
+}
@@ -0,0 +1,57 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"reflect"
+)
+
+// FieldHook is called for a tagged field once options have been assigned. set
+// reports whether an option assigned the field, and value is the field itself,
+// so the hook may change it.
+type FieldHook func(fieldName string, set bool, value reflect.Value) error
+
+// ExtractWithFieldHook extracts options into dest struct, then calls hook once
+// for every tagged field in declaration order, whether or not an option
+// touched it. Fields under a nested struct pointer that is still nil are
+// skipped rather than allocated. This is the place for per-field fallbacks or logging. An error
+// from hook stops the extraction and is returned. Options not in dest are
+// skipped.
+func ExtractWithFieldHook(dest interface{}, hook FieldHook, options ...interface{}) error {
+	return (&extractor{fieldHook: hook}).extract(dest, options...)
+}
+
+// runFieldHook calls the extractor's field hook for every tagged field that
+// can be reached without allocating a nil parent.
+func (x *extractor) runFieldHook(optionStruct reflect.Value, fieldMap map[string]reflect.StructField) error {
+	if x.fieldHook == nil {
+		return nil
+	}
+	for _, optname := range orderedFields(fieldMap) {
+		field := fieldMap[optname]
+		fieldValue, ok := fieldByIndex(optionStruct, field.Index, false)
+		if !ok {
+			continue
+		}
+		if err := x.fieldHook(field.Name, x.isSet(fieldValue), fieldValue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package opts
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestExtractWithFieldHook(t *testing.T) {
+	seen := map[string]bool{}
+	var order []string
+	hook := func(fieldName string, set bool, value reflect.Value) error {
+		order = append(order, fieldName)
+		seen[fieldName] = set
+		// fill in unset usernames
+		if fieldName == "Username" && !set {
+			value.SetString("fallback")
+		}
+		return nil
+	}
+
+	opts := testoptions{}
+	err := ExtractWithFieldHook(&opts, hook, WithItem("hello"), WithPhoneNum(8675309))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	expected := []string{"Items", "PhoneNum", "Username", "PtrString", "List", "Boolean"}
+	if !reflect.DeepEqual(order, expected) {
+		t.Fatalf("hook should have been called for %v, got %v", expected, order)
+	}
+	if !seen["Items"] || !seen["PhoneNum"] || seen["Username"] || seen["Boolean"] {
+		t.Fatalf("hook should report which fields were set, got %v", seen)
+	}
+	if opts.Username != "fallback" {
+		t.Fatalf("Username should have been set by the hook, got '%s'", opts.Username)
+	}
+
+	failure := errors.New("hook failure")
+	err = ExtractWithFieldHook(&opts, func(string, bool, reflect.Value) error {
+		return failure
+	})
+	if err != failure {
+		t.Fatalf("ExtractWithFieldHook should have returned the hook error, got '%v'", err)
+	}
+}
+
+func TestFieldHookNilParent(t *testing.T) {
+	var order []string
+	hook := func(fieldName string, set bool, value reflect.Value) error {
+		order = append(order, fieldName)
+		return nil
+	}
+
+	opts := testnestedoptions{}
+	err := ExtractWithFieldHook(&opts, hook, WithUsername("userbob"))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Server != nil {
+		t.Fatalf("Server should not have been allocated by the hook, got %+v", opts.Server)
+	}
+	expected := []string{"Items", "Username"}
+	if !reflect.DeepEqual(order, expected) {
+		t.Fatalf("hook should have been called for %v, got %v", expected, order)
+	}
+}
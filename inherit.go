@@ -0,0 +1,201 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// parseInherit parses an inherit tag such as parent.Timeout into how many
+// structs up the referenced field lives and the name of that field.
+func parseInherit(tag string) (levels int, name string, err error) {
+	segments := strings.Split(tag, ".")
+	for _, segment := range segments[:len(segments)-1] {
+		if segment != "parent" {
+			return 0, "", fmt.Errorf("inherit %q must be parent.Field", tag)
+		}
+		levels++
+	}
+	name = segments[len(segments)-1]
+	if levels == 0 || name == "" {
+		return 0, "", fmt.Errorf("inherit %q must be parent.Field", tag)
+	}
+	return levels, name, nil
+}
+
+// inheritFields copies ancestor fields into the fields tagged inherit that are
+// still at their zero value once options have been assigned. A field tagged
+// inherit:"parent.Timeout" takes the Timeout field of the struct that holds its
+// own struct, and every further parent segment goes one struct higher. A field
+// an option set to its zero value still inherits, as nothing tells it apart
+// from a field no option set.
+//
+// Only the nested structs, and structs pointed to, whose types lead to inherit
+// tags are visited, top down, so a value inherited by a parent cascades to its
+// children. Inheritance runs before normalization, the field hook and
+// conditional requirements, which all see the inherited values. Because a tag
+// may only refer upwards, inheritance can't form a cycle, and a pointer is
+// followed at most once however deep the data it leads to goes.
+func (x *extractor) inheritFields(optionStruct reflect.Value) error {
+	return x.inheritInto(optionStruct, nil, map[uintptr]bool{})
+}
+
+// inheritInto resolves the inherit tags of v and the structs nested in it.
+// ancestors holds the structs on the way down to v, outermost first.
+func (x *extractor) inheritInto(v reflect.Value, ancestors []reflect.Value, visited map[uintptr]bool) error {
+	t := v.Type()
+	plan := inheritPlanOf(t)
+
+	for _, i := range plan.fields {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("inherit")
+		levels, name, err := parseInherit(tag)
+		if err != nil {
+			return fmt.Errorf("field %s: %s", sf.Name, err)
+		}
+		if levels > len(ancestors) {
+			return fmt.Errorf("field %s: inherit %q reaches above the outermost struct", sf.Name, tag)
+		}
+		name = x.remapField(name)
+		source := ancestors[len(ancestors)-levels].FieldByName(name)
+		if !source.IsValid() {
+			return fmt.Errorf("field %s: inherit refers to unknown field %s", sf.Name, name)
+		}
+		if !source.Type().AssignableTo(sf.Type) {
+			return fmt.Errorf("field %s: cannot inherit %s of type %s", sf.Name, name, source.Type().String())
+		}
+		field := v.Field(i)
+		if field.IsZero() && field.CanSet() {
+			field.Set(source)
+		}
+	}
+
+	ancestors = append(ancestors, v)
+	for _, i := range plan.nested {
+		nested := v.Field(i)
+		if nested.Kind() == reflect.Ptr {
+			if nested.IsNil() || visited[nested.Pointer()] {
+				continue
+			}
+			visited[nested.Pointer()] = true
+			nested = nested.Elem()
+		}
+		if err := x.inheritInto(nested, ancestors, visited); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// inheritPlan is where the inherit tags of a struct type are found: the
+// indexes of its fields tagged inherit, and of its struct and struct pointer
+// fields whose types lead to more of them.
+type inheritPlan struct {
+	fields []int
+	nested []int
+}
+
+// inheritPlans caches the inherit plans of struct types, so types are only
+// scanned once and types without inherit tags cost nothing to skip.
+var inheritPlans = struct {
+	mu     sync.RWMutex
+	byType map[reflect.Type]*inheritPlan
+}{byType: make(map[reflect.Type]*inheritPlan)}
+
+// inheritPlanOf returns the inherit plan of struct type t. The plans of every
+// struct type reachable from t are worked out together, so that types nested
+// in each other, such as a list node pointing to the next, are planned right.
+func inheritPlanOf(t reflect.Type) *inheritPlan {
+	inheritPlans.mu.RLock()
+	plan, found := inheritPlans.byType[t]
+	inheritPlans.mu.RUnlock()
+	if found {
+		return plan
+	}
+
+	// find every struct type reachable from t and those with inherit tags
+	reachable := map[reflect.Type]bool{}
+	tagged := map[reflect.Type]bool{}
+	pending := []reflect.Type{t}
+	for len(pending) > 0 {
+		s := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if reachable[s] {
+			continue
+		}
+		reachable[s] = true
+		for i := 0; i < s.NumField(); i++ {
+			sf := s.Field(i)
+			if _, found := sf.Tag.Lookup("inherit"); found {
+				tagged[s] = true
+			}
+			if nested := structOf(sf.Type); nested != nil {
+				pending = append(pending, nested)
+			}
+		}
+	}
+
+	// a type leads to inherit tags when it has some or nests a type that does
+	leads := tagged
+	for changed := true; changed; {
+		changed = false
+		for s := range reachable {
+			if leads[s] {
+				continue
+			}
+			for i := 0; i < s.NumField(); i++ {
+				if nested := structOf(s.Field(i).Type); nested != nil && leads[nested] {
+					leads[s], changed = true, true
+					break
+				}
+			}
+		}
+	}
+
+	inheritPlans.mu.Lock()
+	defer inheritPlans.mu.Unlock()
+	for s := range reachable {
+		plan := &inheritPlan{}
+		for i := 0; i < s.NumField(); i++ {
+			sf := s.Field(i)
+			if _, found := sf.Tag.Lookup("inherit"); found {
+				plan.fields = append(plan.fields, i)
+			}
+			if nested := structOf(sf.Type); nested != nil && leads[nested] {
+				plan.nested = append(plan.nested, i)
+			}
+		}
+		inheritPlans.byType[s] = plan
+	}
+	return inheritPlans.byType[t]
+}
+
+// structOf returns the struct type t is or points to, or nil.
+func structOf(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	return t
+}
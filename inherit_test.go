@@ -0,0 +1,95 @@
+package opts
+
+import (
+	"testing"
+)
+
+func TestInheritFields(t *testing.T) {
+	opts := testinheritoptions{}
+	err := Extract(&opts, WithTimeout(30), WithHost("example.com"))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Admin.Timeout != 30 {
+		t.Fatalf("Admin.Timeout should be inherited as 30, got %d", opts.Admin.Timeout)
+	}
+	// inherited values cascade to grandchildren
+	if opts.Admin.Audit.Timeout != 30 {
+		t.Fatalf("Admin.Audit.Timeout should be inherited as 30, got %d", opts.Admin.Audit.Timeout)
+	}
+	if opts.Admin.Audit.Host != "example.com" {
+		t.Fatalf("Admin.Audit.Host should be inherited as 'example.com', got '%s'", opts.Admin.Audit.Host)
+	}
+
+	// set fields keep their own value
+	opts = testinheritoptions{}
+	err = Extract(&opts, WithTimeout(30), WithAdminTimeout(5))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Admin.Timeout != 5 || opts.Admin.Audit.Timeout != 5 {
+		t.Fatalf("Admin timeouts should be 5, got %d and %d", opts.Admin.Timeout, opts.Admin.Audit.Timeout)
+	}
+
+	// a field set to its zero value is still inherited into
+	opts = testinheritoptions{}
+	err = Extract(&opts, WithTimeout(30), WithAdminTimeout(0))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Admin.Timeout != 30 {
+		t.Fatalf("Admin.Timeout should be inherited as 30, got %d", opts.Admin.Timeout)
+	}
+
+	// however long a list is, every node inherits down it
+	list := testinheritlistoptions{}
+	for i := 0; i < 40; i++ {
+		list.Head = &testinheritnode{Next: list.Head}
+	}
+	if err := Extract(&list, WithTimeout(30)); err != nil {
+		t.Fatalf("%s", err)
+	}
+	for node := list.Head; node != nil; node = node.Next {
+		if node.Timeout != 30 {
+			t.Fatalf("every node should inherit a Timeout of 30, got %d", node.Timeout)
+		}
+	}
+
+	bad := testbadinheritoptions{}
+	if err := Extract(&bad); err == nil {
+		t.Fatalf("Extract should have failed inheriting above the outermost struct, but err is nil")
+	}
+}
+
+type WithTimeout int
+type WithAdminTimeout int
+
+type testinheritoptions struct {
+	Timeout int    `optname:"WithTimeout"`
+	Host    string `optname:"WithHost"`
+	Admin   testinheritadmin
+}
+
+type testinheritadmin struct {
+	Timeout int `optname:"WithAdminTimeout" inherit:"parent.Timeout"`
+	Audit   testinheritaudit
+}
+
+type testinheritaudit struct {
+	Timeout int    `inherit:"parent.Timeout"`
+	Host    string `inherit:"parent.parent.Host"`
+}
+
+type testbadinheritoptions struct {
+	Timeout int `inherit:"parent.Timeout"`
+}
+
+type testinheritlistoptions struct {
+	Timeout int              `optname:"WithTimeout"`
+	Head    *testinheritnode `optname:"WithHead"`
+}
+
+type testinheritnode struct {
+	Timeout int `inherit:"parent.Timeout"`
+	Next    *testinheritnode
+}
@@ -0,0 +1,70 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"reflect"
+)
+
+// ExtractWithInterning extracts options into dest struct, then interns the
+// tagged string fields and the elements of string slice fields, so that equal
+// strings share one backing array. Options not in dest are skipped.
+//
+// Strings are interned through a map that lives for the one extraction, which
+// costs an entry per distinct string. This pays off for very large option sets
+// that repeat the same values, such as a hostname across hundreds of entries,
+// and is wasted on small ones.
+func ExtractWithInterning(dest interface{}, options ...interface{}) error {
+	return (&extractor{interned: make(map[string]string)}).extract(dest, options...)
+}
+
+// internStrings interns the string fields and string slice elements of
+// optionStruct.
+func (x *extractor) internStrings(optionStruct reflect.Value, fieldMap map[string]reflect.StructField) {
+	if x.interned == nil {
+		return
+	}
+	for _, field := range fieldMap {
+		fieldValue, ok := fieldByIndex(optionStruct, field.Index, false)
+		if !ok {
+			continue
+		}
+		switch {
+		case fieldValue.Kind() == reflect.String:
+			x.intern(fieldValue)
+		case fieldValue.Kind() == reflect.Slice && fieldValue.Type().Elem().Kind() == reflect.String:
+			for i := 0; i < fieldValue.Len(); i++ {
+				x.intern(fieldValue.Index(i))
+			}
+		}
+	}
+}
+
+// intern replaces a settable string with the first equal string seen.
+func (x *extractor) intern(v reflect.Value) {
+	if !v.CanSet() {
+		return
+	}
+	s := v.String()
+	if interned, found := x.interned[s]; found {
+		v.SetString(interned)
+		return
+	}
+	x.interned[s] = s
+}
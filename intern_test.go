@@ -0,0 +1,36 @@
+package opts
+
+import (
+	"testing"
+	"unsafe"
+)
+
+func TestExtractWithInterning(t *testing.T) {
+	// build equal strings with distinct backing arrays
+	host := func() string { return string([]byte("db.internal")) }
+
+	opts := testinternoptions{}
+	err := ExtractWithInterning(&opts, WithHost(host()), WithItem(host()), WithItem(host()), WithItem("other"))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Host != "db.internal" || len(opts.Items) != 3 || opts.Items[2] != "other" {
+		t.Fatalf("options should have applied, got %+v", opts)
+	}
+	if unsafe.StringData(opts.Host) != unsafe.StringData(opts.Items[0]) || unsafe.StringData(opts.Items[0]) != unsafe.StringData(opts.Items[1]) {
+		t.Fatalf("equal strings should share storage")
+	}
+
+	opts = testinternoptions{}
+	if err := Extract(&opts, WithHost(host()), WithItem(host())); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if unsafe.StringData(opts.Host) == unsafe.StringData(opts.Items[0]) {
+		t.Fatalf("strings should not be interned without asking")
+	}
+}
+
+type testinternoptions struct {
+	Host  string   `optname:"WithHost"`
+	Items []string `optname:"WithItem"`
+}
@@ -0,0 +1,43 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// fitJSON assigns optionValue into field by marshaling it to JSON and
+// unmarshaling the result into the field's type. Every call allocates and
+// walks the value twice, so it is only used for fields tagged jsonfit:"true"
+// once the cheaper fits have failed.
+func fitJSON(field reflect.Value, sf reflect.StructField, optname string, optionValue reflect.Value) error {
+	data, err := json.Marshal(optionValue.Interface())
+	if err != nil {
+		return fmt.Errorf("failed to set %s, could not marshal for field %s: %s", optname, sf.Name, err)
+	}
+
+	target := reflect.New(field.Type())
+	if err := json.Unmarshal(data, target.Interface()); err != nil {
+		return fmt.Errorf("failed to set %s, could not unmarshal into field %s: %s", optname, sf.Name, err)
+	}
+	field.Set(target.Elem())
+	return nil
+}
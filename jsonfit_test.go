@@ -0,0 +1,66 @@
+package opts
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestJSONFit(t *testing.T) {
+	opts := testjsonoptions{}
+	err := Extract(&opts,
+		WithServer(testjsonsource{Host: "localhost", Port: 8080}),
+		WithLimits(map[string]interface{}{"Max": 10, "Min": 1}),
+	)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Server.Host != "localhost" || opts.Server.Port != 8080 {
+		t.Fatalf("Server should be {localhost 8080}, got %+v", opts.Server)
+	}
+	if opts.Limits.Max != 10 || opts.Limits.Min != 1 {
+		t.Fatalf("Limits should be {1 10}, got %+v", opts.Limits)
+	}
+
+	// without the tag the same option fails to fit
+	untagged := testnojsonoptions{}
+	err = Extract(&untagged, WithServer(testjsonsource{Host: "localhost", Port: 8080}))
+	if err == nil {
+		t.Fatalf("Extract should have failed without jsonfit, but err is nil")
+	}
+
+	opts = testjsonoptions{}
+	err = Extract(&opts, WithLimits(map[string]interface{}{"Max": "ten"}))
+	if err == nil {
+		t.Fatalf("Extract should have failed to unmarshal, but err is nil")
+	}
+	if !strings.Contains(err.Error(), "Limits") {
+		t.Fatalf("error should name the field Limits, got '%s'", err)
+	}
+}
+
+type WithServer testjsonsource
+type WithLimits map[string]interface{}
+
+type testjsonsource struct {
+	Host string
+	Port int
+}
+
+type testjsonserver struct {
+	Host string
+	Port int64
+}
+
+type testjsonlimits struct {
+	Min int
+	Max int
+}
+
+type testjsonoptions struct {
+	Server testjsonserver `optname:"WithServer" jsonfit:"true"`
+	Limits testjsonlimits `optname:"WithLimits" jsonfit:"true"`
+}
+
+type testnojsonoptions struct {
+	Server testjsonserver `optname:"WithServer"`
+}
@@ -0,0 +1,91 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+)
+
+// jsonNumberType is the type of the numbers ExtractJSON decodes.
+var jsonNumberType = reflect.TypeOf(json.Number(""))
+
+// ExtractJSON decodes a JSON object from r and extracts it into dest struct as
+// ExtractNestedMap does. Keys not in dest are skipped.
+//
+// Numbers are decoded as json.Number rather than float64, so integers beyond
+// 2^53, such as 19 digit IDs and timestamps in nanoseconds, keep every digit.
+// A json.Number fits an integer field exactly, and a float field through
+// Float64, while one that doesn't fit its field, such as 300 for an int8 or a
+// fraction for an int, results in an *OverflowError. Integers written with a
+// fraction or an exponent, such as 1e3, go through float64 first. Fields of
+// type interface{} receive the json.Number itself, where decoding into a map
+// yourself would have given them a float64.
+func ExtractJSON(dest interface{}, r io.Reader) error {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	var data map[string]interface{}
+	if err := decoder.Decode(&data); err != nil {
+		return fmt.Errorf("failed to decode json: %w", err)
+	}
+	return ExtractNestedMap(dest, data)
+}
+
+// fitJSONNumber fits a json.Number into a numeric field, parsing it exactly
+// rather than through float64 where the field is an integer. ok reports
+// whether the option is a json.Number bound for a numeric field.
+func fitJSONNumber(field reflect.Value, sf reflect.StructField, optname string, optionValue reflect.Value) (ok bool, err error) {
+	if optionValue.Type() != jsonNumberType || !numericKind(field.Kind()) {
+		return false, nil
+	}
+	number := optionValue.Interface().(json.Number)
+	var parsed interface{}
+	switch {
+	case signedKind(field.Kind()):
+		if n, err := number.Int64(); err == nil {
+			parsed = n
+		}
+	case unsignedKind(field.Kind()):
+		if n, err := strconv.ParseUint(number.String(), 10, 64); err == nil {
+			parsed = n
+		}
+	}
+	if parsed == nil {
+		f, err := number.Float64()
+		if err != nil {
+			return true, fmt.Errorf("failed to set %s, could not parse %s for field %s", optname, number, sf.Name)
+		}
+		parsed = f
+	}
+	fitted, _, err := convertNumber(field.Type(), sf, optname, reflect.ValueOf(parsed))
+	if err != nil {
+		// report the number as written rather than as parsed
+		var overflow *OverflowError
+		if errors.As(err, &overflow) {
+			overflow.Value = number
+		}
+		return true, err
+	}
+	field.Set(fitted)
+	return true, nil
+}
@@ -0,0 +1,62 @@
+package opts
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestExtractJSON(t *testing.T) {
+	opts := testjsonnumberoptions{}
+	err := ExtractJSON(&opts, strings.NewReader(`{
+		"WithID": 1234567890123456789,
+		"WithSerial": 18446744073709551615,
+		"WithRatio": 0.25,
+		"WithSmall": 1e2,
+		"WithIDs": [9007199254740993, 2],
+		"WithAny": 9007199254740993
+	}`))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.ID != 1234567890123456789 {
+		t.Fatalf("ID should be 1234567890123456789, got %d", opts.ID)
+	}
+	if opts.Serial != 18446744073709551615 {
+		t.Fatalf("Serial should be 18446744073709551615, got %d", opts.Serial)
+	}
+	if opts.Ratio != 0.25 || opts.Small != 100 {
+		t.Fatalf("Ratio and Small should be 0.25 and 100, got %v and %d", opts.Ratio, opts.Small)
+	}
+	if len(opts.IDs) != 2 || opts.IDs[0] != 9007199254740993 {
+		t.Fatalf("IDs should be [9007199254740993 2], got %v", opts.IDs)
+	}
+	if opts.Any != json.Number("9007199254740993") {
+		t.Fatalf("Any should be the json.Number 9007199254740993, got %#v", opts.Any)
+	}
+
+	var overflow *OverflowError
+	for _, doc := range []string{`{"WithSmall": 300}`, `{"WithID": 99999999999999999999}`, `{"WithSmall": 1.5}`, `{"WithSerial": -1}`} {
+		err := ExtractJSON(&testjsonnumberoptions{}, strings.NewReader(doc))
+		if !errors.As(err, &overflow) {
+			t.Fatalf("%s should result in an *OverflowError, got %v", doc, err)
+		}
+	}
+	if !strings.Contains(overflow.Error(), "-1") {
+		t.Fatalf("err should quote the number as written, got %s", overflow)
+	}
+
+	if err := ExtractJSON(&opts, strings.NewReader(`{"WithID": `)); err == nil {
+		t.Fatalf("ExtractJSON should have failed decoding malformed json, but err is nil")
+	}
+}
+
+type testjsonnumberoptions struct {
+	ID     int64       `optname:"WithID"`
+	Serial uint64      `optname:"WithSerial"`
+	Ratio  float64     `optname:"WithRatio"`
+	Small  int8        `optname:"WithSmall"`
+	IDs    []int64     `optname:"WithIDs"`
+	Any    interface{} `optname:"WithAny"`
+}
@@ -0,0 +1,86 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// ExtractKeyValueFile extracts lines of Name=Value read from r into dest
+// struct, matching each Name against the optname tags. Values are parsed for
+// the kind of their field, and repeated names append to slice fields.
+//
+// Everything after a # is a comment, whitespace around names and values is
+// trimmed and blank lines are skipped. Names not in dest are skipped. Lines
+// without an = result in error naming the line number.
+func ExtractKeyValueFile(dest interface{}, r io.Reader) error {
+	optionStruct, err := destStruct(dest)
+	if err != nil {
+		return err
+	}
+	x := &extractor{coerce: true}
+	fieldMap, err := x.prepare(optionStruct)
+	if err != nil {
+		return err
+	}
+
+	pairs, err := readKeyValues(r)
+	if err != nil {
+		return err
+	}
+	for _, pair := range pairs {
+		if err := x.assign(optionStruct, fieldMap, pair.name, reflect.ValueOf(pair.value)); err != nil {
+			return fmt.Errorf("line %d: %w", pair.line, err)
+		}
+	}
+	return x.finish(optionStruct, fieldMap)
+}
+
+// keyValue is a Name=Value line of a key value file.
+type keyValue struct {
+	line        int
+	name, value string
+}
+
+// readKeyValues reads the Name=Value lines of a key value file in order.
+func readKeyValues(r io.Reader) ([]keyValue, error) {
+	var pairs []keyValue
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if comment := strings.Index(text, "#"); comment >= 0 {
+			text = text[:comment]
+		}
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+
+		pair := strings.SplitN(text, "=", 2)
+		if len(pair) != 2 {
+			return nil, fmt.Errorf("line %d: expected Name=Value", line)
+		}
+		pairs = append(pairs, keyValue{line: line, name: strings.TrimSpace(pair[0]), value: strings.TrimSpace(pair[1])})
+	}
+	return pairs, scanner.Err()
+}
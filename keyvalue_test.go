@@ -0,0 +1,50 @@
+package opts
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExtractKeyValueFile(t *testing.T) {
+	file := `
+# service settings
+WithUsername = userbob
+WithPhoneNum=8675309   # trailing comment
+WithBool=true
+
+WithItem=hello
+WithItem=world
+WithUnknown=skipped
+`
+	opts := testoptions{}
+	if err := ExtractKeyValueFile(&opts, strings.NewReader(file)); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Username != "userbob" {
+		t.Fatalf("Username should be 'userbob', got '%s'", opts.Username)
+	}
+	if opts.PhoneNum != 8675309 {
+		t.Fatalf("PhoneNum should be 8675309, got %d", opts.PhoneNum)
+	}
+	if !opts.Boolean {
+		t.Fatalf("Boolean should be true")
+	}
+	if len(opts.Items) != 2 || opts.Items[0] != "hello" || opts.Items[1] != "world" {
+		t.Fatalf("Items should be [hello world], got %v", opts.Items)
+	}
+
+	opts = testoptions{}
+	err := ExtractKeyValueFile(&opts, strings.NewReader("WithUsername=userbob\nmalformed\n"))
+	if err == nil {
+		t.Fatalf("ExtractKeyValueFile should have failed on a malformed line, but err is nil")
+	}
+	if !strings.HasPrefix(err.Error(), "line 2:") {
+		t.Fatalf("error should name line 2, got '%s'", err)
+	}
+
+	opts = testoptions{}
+	err = ExtractKeyValueFile(&opts, strings.NewReader("WithPhoneNum=abc\n"))
+	if err == nil {
+		t.Fatalf("ExtractKeyValueFile should have failed to parse, but err is nil")
+	}
+}
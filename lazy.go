@@ -0,0 +1,101 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Lazy holds a value of type T converted from an option on first use rather
+// than during extraction, so expensive conversions, such as compiling a
+// pattern, are only paid for fields that are read.
+//
+// An option fits into a Lazy field when it fits T as is, or when a converter
+// from its type to T has been registered with RegisterConverter. Get runs the
+// conversion once, guarded by a sync.Once, so it is safe to call from many
+// goroutines. Copies of a Lazy share its conversion.
+type Lazy[T any] struct {
+	state *lazyState[T]
+}
+
+// lazyState is the conversion shared by copies of a Lazy.
+type lazyState[T any] struct {
+	once    sync.Once
+	convert func() (reflect.Value, error)
+	value   T
+	err     error
+}
+
+// Get returns the converted value, converting it on the first call. A Lazy
+// no option was fitted into holds the zero value of T.
+func (l Lazy[T]) Get() (T, error) {
+	if l.state == nil {
+		var zero T
+		return zero, nil
+	}
+	l.state.once.Do(func() {
+		converted, err := l.state.convert()
+		if err != nil {
+			l.state.err = err
+			return
+		}
+		l.state.value = converted.Interface().(T)
+	})
+	return l.state.value, l.state.err
+}
+
+// lazyField is implemented by *Lazy so fit can defer a conversion.
+type lazyField interface {
+	lazyType() reflect.Type
+	setLazy(convert func() (reflect.Value, error))
+}
+
+func (l *Lazy[T]) lazyType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+func (l *Lazy[T]) setLazy(convert func() (reflect.Value, error)) {
+	l.state = &lazyState[T]{convert: convert}
+}
+
+// fitLazy defers fitting optionValue into the value of lazy until it is read.
+// Options with no way to fit are refused straight away.
+func fitLazy(lazy lazyField, sf reflect.StructField, optname string, optionValue reflect.Value) error {
+	target := lazy.lazyType()
+	if optionValue.Kind() == target.Kind() && optionValue.Type().ConvertibleTo(target) {
+		converted := optionValue.Convert(target)
+		lazy.setLazy(func() (reflect.Value, error) { return converted, nil })
+		return nil
+	}
+	convert, found := lookupConverter(optionValue.Type(), target)
+	if !found {
+		return fmt.Errorf("failed to set %s, no converter from %s to %s for field %s", optname, optionValue.Type().String(), target.String(), sf.Name)
+	}
+	lazy.setLazy(func() (reflect.Value, error) {
+		converted, err := convert(context.Background(), sf, optionValue)
+		if err != nil {
+			return converted, fmt.Errorf("failed to convert %s for field %s: %s", optname, sf.Name, err)
+		}
+		return converted, nil
+	})
+	return nil
+}
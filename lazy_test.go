@@ -0,0 +1,81 @@
+package opts
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestLazyFields(t *testing.T) {
+	calls := 0
+	RegisterConverter(testcountconverter(&calls))
+
+	opts := testlazyoptions{}
+	if err := Extract(&opts, WithRule("allow"), WithFilter("deny"), WithUsername("userbob")); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if calls != 0 {
+		t.Fatalf("nothing should have converted during extraction, got %d calls", calls)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			opts.Rule.Get()
+		}()
+	}
+	wg.Wait()
+	rule, err := opts.Rule.Get()
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if rule.source != "allow" || calls != 1 {
+		t.Fatalf("Rule should have converted once, got %+v after %d calls", rule, calls)
+	}
+
+	// options fitting as is need no converter
+	if username, _ := opts.Username.Get(); username != "userbob" {
+		t.Fatalf("Username should be 'userbob', got '%s'", username)
+	}
+	// unset lazies hold the zero value
+	if pin, err := opts.Pin.Get(); pin != 0 || err != nil {
+		t.Fatalf("Pin should be zero, got %d and %v", pin, err)
+	}
+
+	opts = testlazyoptions{}
+	if err := Extract(&opts, WithRule("")); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if _, err := opts.Rule.Get(); err == nil {
+		t.Fatalf("Get should have failed converting an empty rule, but err is nil")
+	}
+	if err := Extract(&opts, WithPin("1234")); err == nil {
+		t.Fatalf("Extract should have failed with no converter for Pin, but err is nil")
+	}
+}
+
+type WithRule string
+type WithFilter string
+
+type testlazyoptions struct {
+	Rule     Lazy[testcompiled] `optname:"WithRule"`
+	Filter   Lazy[testcompiled] `optname:"WithFilter"`
+	Username Lazy[string]       `optname:"WithUsername"`
+	Pin      Lazy[int]          `optname:"WithPin"`
+}
+
+func testcountconverter(calls *int) func(string) (testcompiled, error) {
+	return func(s string) (testcompiled, error) {
+		*calls++
+		if s == "" {
+			return testcompiled{}, fmt.Errorf("empty source")
+		}
+		return testcompiled{source: s}, nil
+	}
+}
+
+type testcompiled struct {
+	source string
+}
@@ -0,0 +1,104 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"unicode/utf8"
+)
+
+// parseLength parses the minlen or maxlen tag of a field, reporting whether
+// the field has it.
+func parseLength(sf reflect.StructField, tag string) (int, bool, error) {
+	value, found := sf.Tag.Lookup(tag)
+	if !found {
+		return 0, false, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, false, fmt.Errorf("%s %q is not a length", tag, value)
+	}
+	return n, true, nil
+}
+
+// checkLengthTags validates the length tags of a field.
+func checkLengthTags(sf reflect.StructField) error {
+	for _, tag := range []string{"minlen", "maxlen", "truncatelen"} {
+		if _, _, err := parseLength(sf, tag); err != nil {
+			return err
+		}
+	}
+	if mode := sf.Tag.Get("lenmode"); mode != "" && mode != "runes" && mode != "bytes" {
+		return fmt.Errorf("unknown lenmode %q", mode)
+	}
+	return nil
+}
+
+// checkLengths enforces the minlen and maxlen tags of string and slice fields
+// once options have been assigned and normalized. The length of a string is
+// its number of runes, or of bytes when the field is tagged lenmode:"bytes",
+// and the length of a slice is its number of elements. Empty fields no option
+// set are left alone, so the tags only constrain values that were given, but
+// an option setting a field empty must meet its minlen like any other. Every
+// violation is reported.
+func (x *extractor) checkLengths(optionStruct reflect.Value, fieldMap map[string]reflect.StructField) error {
+	var errs []error
+	for _, optname := range orderedFields(fieldMap) {
+		field := fieldMap[optname]
+		min, hasMin, err := parseLength(field, "minlen")
+		if err != nil {
+			return fmt.Errorf("field %s: %s", field.Name, err)
+		}
+		max, hasMax, err := parseLength(field, "maxlen")
+		if err != nil {
+			return fmt.Errorf("field %s: %s", field.Name, err)
+		}
+		if !hasMin && !hasMax {
+			continue
+		}
+
+		fieldValue, ok := fieldByIndex(optionStruct, field.Index, false)
+		if !ok {
+			continue
+		}
+		var length int
+		switch fieldValue.Kind() {
+		case reflect.String:
+			length = utf8.RuneCountInString(fieldValue.String())
+			if field.Tag.Get("lenmode") == "bytes" {
+				length = fieldValue.Len()
+			}
+		case reflect.Slice:
+			length = fieldValue.Len()
+		default:
+			continue
+		}
+		switch {
+		case length == 0 && !x.isSet(fieldValue):
+		case hasMin && length < min:
+			errs = append(errs, fmt.Errorf("field %s has length %d, shorter than the minlen of %d", field.Name, length, min))
+		case hasMax && length > max:
+			errs = append(errs, fmt.Errorf("field %s has length %d, longer than the maxlen of %d", field.Name, length, max))
+		}
+	}
+	return errors.Join(errs...)
+}
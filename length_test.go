@@ -0,0 +1,48 @@
+package opts
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLengthTags(t *testing.T) {
+	opts := testlengthoptions{}
+	err := Extract(&opts, WithUsername("  héllo  "), WithItem("a"), WithItem("b"))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	// lengths are checked after normalization, in runes by default
+	err = Extract(&testlengthoptions{}, WithUsername(" héllo wörld "))
+	if err == nil || !strings.Contains(err.Error(), "field Username has length 11") {
+		t.Fatalf("err should report the rune length of Username, got %v", err)
+	}
+	err = Extract(&testlengthoptions{}, WithHost("héllo"))
+	if err == nil || !strings.Contains(err.Error(), "field Host has length 6") {
+		t.Fatalf("err should report the byte length of Host, got %v", err)
+	}
+	err = Extract(&testlengthoptions{}, WithItem("a"), WithItem("b"), WithItem("c"), WithUsername("ab"))
+	if err == nil || !strings.Contains(err.Error(), "field Items has length 3") || !strings.Contains(err.Error(), "minlen of 3") {
+		t.Fatalf("err should report both violations, got %v", err)
+	}
+
+	// an empty value given by an option must meet the minlen
+	err = Extract(&testlengthoptions{}, WithUsername(""))
+	if err == nil || !strings.Contains(err.Error(), "field Username has length 0") {
+		t.Fatalf("err should report the empty Username, got %v", err)
+	}
+
+	if err := Extract(&testbadlengthoptions{}); err == nil {
+		t.Fatalf("Extract should have failed on a maxlen that isn't a length, but err is nil")
+	}
+}
+
+type testlengthoptions struct {
+	Username string   `optname:"WithUsername" normalize:"trim" minlen:"3" maxlen:"10"`
+	Host     string   `optname:"WithHost" maxlen:"5" lenmode:"bytes"`
+	Items    []string `optname:"WithItem" maxlen:"2"`
+}
+
+type testbadlengthoptions struct {
+	Items []string `optname:"WithItem" maxlen:"-1"`
+}
@@ -0,0 +1,96 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// TooManyOptionsError is returned when an extraction is given more options
+// than its limit allows.
+type TooManyOptionsError struct {
+	Limit int
+	Got   int
+}
+
+func (e *TooManyOptionsError) Error() string {
+	return fmt.Sprintf("too many options, got %d but the limit is %d", e.Got, e.Limit)
+}
+
+// ExtractWithMaxOptions extracts options into dest struct, refusing more than
+// limit options. Bundles from Combine, groups from WithGroup and structs from
+// Spread count as the options they carry. When the limit is exceeded a
+// *TooManyOptionsError is returned and no option is applied. Options not in
+// dest are skipped.
+func ExtractWithMaxOptions(dest interface{}, limit int, options ...interface{}) error {
+	if got := countOptions(options); got > limit {
+		return &TooManyOptionsError{Limit: limit, Got: got}
+	}
+	return extract(dest, false, options...)
+}
+
+// countOptions counts the options that extracting options would apply.
+func countOptions(options []interface{}) int {
+	count := 0
+	for _, option := range expandOptions(options) {
+		switch carrier := option.(type) {
+		case groupOption:
+			count += countOptions(carrier.options)
+		case spreadOption:
+			count += countSpread(carrier)
+		case prioritizedOption:
+			count += countOptions([]interface{}{carrier.option})
+		case indexedOption:
+			count += countOptions([]interface{}{carrier.option})
+		case orderedOption:
+			count += countOptions([]interface{}{carrier.option})
+		default:
+			count++
+		}
+	}
+	return count
+}
+
+// countSpread counts the non-zero tagged fields a spread struct extracts as.
+// Anything that isn't a struct counts as one option and fails on extraction.
+func countSpread(carrier spreadOption) int {
+	source := reflect.ValueOf(carrier.value)
+	if source.Kind() == reflect.Ptr {
+		if source.IsNil() {
+			return 0
+		}
+		source = source.Elem()
+	}
+	if source.Kind() != reflect.Struct {
+		return 1
+	}
+	sourceMap, err := (&extractor{}).mapFields(source.Type())
+	if err != nil {
+		return 1
+	}
+	count := 0
+	for _, field := range sourceMap {
+		fieldValue, ok := fieldByIndex(source, field.Index, false)
+		if ok && !fieldValue.IsZero() {
+			count++
+		}
+	}
+	return count
+}
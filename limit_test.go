@@ -0,0 +1,41 @@
+package opts
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestExtractWithMaxOptions(t *testing.T) {
+	opts := testoptions{}
+	err := ExtractWithMaxOptions(&opts, 2, WithUsername("userbob"), WithItem("hello"))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	opts = testoptions{}
+	err = ExtractWithMaxOptions(&opts, 2, WithUsername("userbob"), Combine(WithItem("hello"), WithItem("world")))
+	var tooMany *TooManyOptionsError
+	if !errors.As(err, &tooMany) {
+		t.Fatalf("ExtractWithMaxOptions should have failed with TooManyOptionsError, got '%v'", err)
+	}
+	if tooMany.Limit != 2 || tooMany.Got != 3 {
+		t.Fatalf("TooManyOptionsError should be {2 3}, got %+v", tooMany)
+	}
+	// nothing applies once the limit is exceeded
+	if opts.Username != "" || len(opts.Items) != 0 {
+		t.Fatalf("no option should have applied, got %+v", opts)
+	}
+
+	// groups and spread structs count as the options they carry
+	tests := [][]interface{}{
+		{WithGroup("server", WithUsername("userbob"), WithItem("hello"), WithItem("world"))},
+		{Spread(testoptions{Username: "userbob", PhoneNum: 5, Boolean: true})},
+		{Prioritized(1, Combine(WithItem("hello"), WithItem("world"), WithUsername("userbob")))},
+	}
+	for _, options := range tests {
+		opts = testoptions{}
+		if err := ExtractWithMaxOptions(&opts, 2, options...); !errors.As(err, &tooMany) {
+			t.Fatalf("ExtractWithMaxOptions(%v) should have failed with TooManyOptionsError, got '%v'", options, err)
+		}
+	}
+}
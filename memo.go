@@ -0,0 +1,142 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"reflect"
+	"sync"
+)
+
+// defaultMemoSize is how many configs ExtractMemo keeps until SetMemoSize
+// says otherwise.
+const defaultMemoSize = 128
+
+// memoKey identifies a memoized config by its type and a hash of the options
+// it was extracted from.
+type memoKey struct {
+	t   reflect.Type
+	sum [sha256.Size]byte
+}
+
+type memoEntry struct {
+	key   memoKey
+	value reflect.Value
+}
+
+// memo holds the configs extracted by ExtractMemo, least recently used last.
+var memo = struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[memoKey]*list.Element
+}{size: defaultMemoSize, order: list.New(), entries: make(map[memoKey]*list.Element)}
+
+// SetMemoSize sets how many configs ExtractMemo keeps, evicting the least
+// recently used ones beyond size straight away. A size of zero or less turns
+// memoization off and empties the cache. The default size is 128.
+func SetMemoSize(size int) {
+	memo.mu.Lock()
+	defer memo.mu.Unlock()
+	memo.size = size
+	evictMemo()
+}
+
+// ExtractMemo extracts options into a fresh T as ExtractInto does, caching the
+// result so that a later call with an identical set of options and the same T
+// returns it without extracting again. The cache is a least recently used one
+// of the size set with SetMemoSize, and is safe to use from many goroutines.
+// Failed extractions aren't cached.
+//
+// Options are identical when they have the same types and hold the same
+// values in the same order, as read by reflection, so the methods of an option
+// such as String, Format or GoString never decide it and a Secret is told
+// apart by the value it holds. Options holding funcs or channels can't be told
+// apart and are extracted without the cache. Every call returns a deep copy of
+// the cached config, so changing it never changes what later calls return.
+// Defaults resolved with RegisterDefaultFunc are cached along with the rest of
+// the config.
+func ExtractMemo[T any](options ...interface{}) (T, error) {
+	key, ok := memoKeyOf[T](options)
+	if !ok {
+		return ExtractInto[T](options...)
+	}
+
+	memo.mu.Lock()
+	if element, found := memo.entries[key]; found {
+		memo.order.MoveToFront(element)
+		cached := element.Value.(*memoEntry).value
+		memo.mu.Unlock()
+		return deepCopy(cached).Interface().(T), nil
+	}
+	memo.mu.Unlock()
+
+	dest, err := ExtractInto[T](options...)
+	if err != nil {
+		return dest, err
+	}
+
+	memo.mu.Lock()
+	defer memo.mu.Unlock()
+	if memo.size <= 0 {
+		return dest, nil
+	}
+	if element, found := memo.entries[key]; found {
+		memo.order.MoveToFront(element)
+	} else {
+		entry := &memoEntry{key: key, value: deepCopy(reflect.ValueOf(dest))}
+		memo.entries[key] = memo.order.PushFront(entry)
+		evictMemo()
+	}
+	return dest, nil
+}
+
+// memoKeyOf returns the key of extracting options into a T, reporting false
+// when an option can't be encoded.
+func memoKeyOf[T any](options []interface{}) (memoKey, bool) {
+	hash := sha256.New()
+	for _, option := range options {
+		var encoded bytes.Buffer
+		if t := reflect.TypeOf(option); t != nil {
+			encoded.WriteString(typeName(t))
+		}
+		encoded.WriteByte(':')
+		encoder := &valueEncoder{visiting: map[uintptr]bool{}}
+		if err := encoder.encode(&encoded, reflect.ValueOf(option)); err != nil {
+			return memoKey{}, false
+		}
+		encoded.WriteByte('\n')
+		hash.Write(encoded.Bytes())
+	}
+	key := memoKey{t: reflect.TypeOf((*T)(nil)).Elem()}
+	hash.Sum(key.sum[:0])
+	return key, true
+}
+
+// evictMemo drops the least recently used configs beyond the memo size. The
+// caller holds memo.mu.
+func evictMemo() {
+	for memo.order.Len() > 0 && memo.order.Len() > memo.size {
+		oldest := memo.order.Back()
+		memo.order.Remove(oldest)
+		delete(memo.entries, oldest.Value.(*memoEntry).key)
+	}
+}
@@ -0,0 +1,90 @@
+package opts
+
+import (
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestExtractMemo(t *testing.T) {
+	SetMemoSize(2)
+	defer SetMemoSize(defaultMemoSize)
+
+	opts, err := ExtractMemo[testoptions](WithUsername("userbob"), WithItem("a"))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Username != "userbob" || len(opts.Items) != 1 {
+		t.Fatalf("options should have applied, got %+v", opts)
+	}
+	if len(memo.entries) != 1 {
+		t.Fatalf("memo should hold 1 config, got %d", len(memo.entries))
+	}
+
+	// changing a returned config doesn't change the cached one
+	opts.Items[0] = "changed"
+	again, err := ExtractMemo[testoptions](WithUsername("userbob"), WithItem("a"))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if again.Items[0] != "a" {
+		t.Fatalf("Items should be [a], got %v", again.Items)
+	}
+	if len(memo.entries) != 1 {
+		t.Fatalf("identical options should have hit the memo, got %d configs", len(memo.entries))
+	}
+
+	// the least recently used config is evicted
+	if _, err := ExtractMemo[testoptions](WithUsername("useralice")); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if _, err := ExtractMemo[testoptions](WithUsername("usercarol")); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(memo.entries) != 2 {
+		t.Fatalf("memo should hold 2 configs, got %d", len(memo.entries))
+	}
+	first := memo.order.Back().Value.(*memoEntry)
+	if first.value.Interface().(testoptions).Username != "useralice" {
+		t.Fatalf("the userbob config should have been evicted")
+	}
+
+	if _, err := ExtractMemo[testoptions](Opt[string]{Name: "WithPhoneNum", Value: "not a number"}); err == nil {
+		t.Fatalf("ExtractMemo should have failed, but err is nil")
+	}
+	if len(memo.entries) != 2 {
+		t.Fatalf("failed extractions shouldn't be cached, got %d configs", len(memo.entries))
+	}
+
+	// options that format alike are told apart by their values
+	bob, err := ExtractMemo[testmaskedoptions](WithMasked("userbob"))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	alice, err := ExtractMemo[testmaskedoptions](WithMasked("useralice"))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if bob.Username != "userbob" || alice.Username != "useralice" {
+		t.Fatalf("masked options shouldn't share a config, got %q and %q", bob.Username, alice.Username)
+	}
+
+	SetMemoSize(0)
+	if len(memo.entries) != 0 {
+		t.Fatalf("memo should be empty, got %d configs", len(memo.entries))
+	}
+}
+
+type WithMasked string
+
+func (WithMasked) Format(f fmt.State, verb rune) {
+	io.WriteString(f, "****")
+}
+
+func (WithMasked) GoString() string {
+	return "****"
+}
+
+type testmaskedoptions struct {
+	Username string `optname:"WithMasked"`
+}
@@ -0,0 +1,164 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// MergeStructs merges src into dest, two structs that share optnames but may
+// lay their fields out differently. Every tagged field of src that isn't at
+// its zero value is fitted into the field of dest with the same optname, as an
+// option would be. Single value fields of dest are overwritten, while the
+// elements of a src slice are appended to a dest slice rather than replacing
+// it. Zero fields of src are skipped and leave dest alone, so a false or 0 in
+// src can't clear dest. Optnames of src that dest doesn't have are skipped.
+//
+// Maps are merged deeply: every key of a src map is set in the dest map,
+// overriding the value already there, and keys only dest has are kept. Map
+// values that are maps themselves are merged the same way, and map values
+// that are slices replace the slice already there, unless the dest field is
+// tagged slicemode:"append" in which case they are appended to it. A nil src
+// map is a zero field and skipped, while an empty one allocates a nil dest map
+// without adding keys.
+//
+// A src field whose value doesn't fit its dest field results in error naming
+// the optname and both fields. Fields merged before it remain merged. dest is
+// assumed to be extracted already, so its defaults and the checks run after
+// extraction are left out.
+func MergeStructs(dest, src interface{}) error {
+	x := &extractor{}
+	optionStruct, err := destStruct(dest)
+	if err != nil {
+		return err
+	}
+	fieldMap, err := x.mapFields(optionStruct.Type())
+	if err != nil {
+		return err
+	}
+
+	source := reflect.ValueOf(src)
+	if source.Kind() == reflect.Ptr {
+		if source.IsNil() {
+			return nil
+		}
+		source = source.Elem()
+	}
+	if source.Kind() != reflect.Struct {
+		return fmt.Errorf("src must be a struct")
+	}
+	sourceMap, err := x.mapFields(source.Type())
+	if err != nil {
+		return err
+	}
+
+	for _, optname := range orderedFields(sourceMap) {
+		value, ok := fieldByIndex(source, sourceMap[optname].Index, false)
+		if !ok || value.IsZero() {
+			continue
+		}
+		field, found := x.lookup(fieldMap, optname)
+		if !found {
+			continue
+		}
+		if value.Kind() == reflect.Map && field.Type.Kind() == reflect.Map {
+			fieldValue, _ := fieldByIndex(optionStruct, field.Index, true)
+			if err := x.mergeMap(fieldValue, field, optname, value); err != nil {
+				return fmt.Errorf("merge %s from field %s into field %s: %s", optname, sourceMap[optname].Name, field.Name, err)
+			}
+			x.markSet(fieldValue)
+			continue
+		}
+		values := []reflect.Value{value}
+		if value.Kind() == reflect.Slice && field.Type.Kind() == reflect.Slice {
+			values = values[:0]
+			for i := 0; i < value.Len(); i++ {
+				values = append(values, value.Index(i))
+			}
+		}
+		for _, value := range values {
+			if err := x.assign(optionStruct, fieldMap, optname, value); err != nil {
+				return fmt.Errorf("merge %s from field %s into field %s: %s", optname, sourceMap[optname].Name, field.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// MergeExtract merges every layer into dest in order with MergeStructs, so
+// later layers override the single values and map keys of earlier ones and
+// add to their slices. Layers are structs, or pointers to structs, sharing
+// optnames with dest. The first layer that fails to merge results in error
+// naming its position, and the layers before it remain merged.
+func MergeExtract(dest interface{}, layers ...interface{}) error {
+	for i, layer := range layers {
+		if err := MergeStructs(dest, layer); err != nil {
+			return fmt.Errorf("layer %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// mergeMap sets every entry of the src map in the dest map field, merging
+// nested maps and combining slices by the field's slicemode.
+func (x *extractor) mergeMap(dest reflect.Value, sf reflect.StructField, optname string, src reflect.Value) error {
+	if dest.IsNil() {
+		dest.Set(reflect.MakeMap(dest.Type()))
+	}
+	elemType := dest.Type().Elem()
+	iter := src.MapRange()
+	for iter.Next() {
+		key := reflect.New(dest.Type().Key()).Elem()
+		if err := x.fit(key, sf, optname, iter.Key()); err != nil {
+			return err
+		}
+		value := iter.Value()
+		for value.Kind() == reflect.Interface && !value.IsNil() {
+			value = value.Elem()
+		}
+		// dest mustn't share nested maps and slices with src
+		value = deepCopy(value)
+
+		merged := reflect.New(elemType).Elem()
+		existing := dest.MapIndex(key)
+		switch {
+		case existing.IsValid() && elemType.Kind() == reflect.Map && value.Kind() == reflect.Map:
+			merged.Set(existing)
+			if err := x.mergeMap(merged, sf, optname, value); err != nil {
+				return err
+			}
+		case elemType.Kind() == reflect.Slice && value.Kind() == reflect.Slice && sf.Tag.Get("slicemode") == "append":
+			if existing.IsValid() {
+				merged.Set(existing)
+			}
+			for i := 0; i < value.Len(); i++ {
+				if err := x.fit(merged, sf, optname, value.Index(i)); err != nil {
+					return err
+				}
+			}
+		default:
+			if err := x.fit(merged, sf, optname, value); err != nil {
+				return err
+			}
+		}
+		dest.SetMapIndex(key, merged)
+	}
+	return nil
+}
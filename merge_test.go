@@ -0,0 +1,107 @@
+package opts
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMergeStructs(t *testing.T) {
+	dest := testoptions{Username: "userbob", PhoneNum: 1, Items: []string{"a"}}
+	src := testmergeoptions{Name: "useralice", Items: []string{"b", "c"}, Unknown: "skipped"}
+	if err := MergeStructs(&dest, &src); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if dest.Username != "useralice" {
+		t.Fatalf("Username should be overwritten with 'useralice', got '%s'", dest.Username)
+	}
+	// zero fields of src don't clear dest
+	if dest.PhoneNum != 1 {
+		t.Fatalf("PhoneNum should be left at 1, got %d", dest.PhoneNum)
+	}
+	if len(dest.Items) != 3 || dest.Items[0] != "a" || dest.Items[2] != "c" {
+		t.Fatalf("Items should be [a b c], got %v", dest.Items)
+	}
+
+	bad := testmergemismatch{PhoneNum: "not a number"}
+	if err := MergeStructs(&dest, bad); err == nil {
+		t.Fatalf("MergeStructs should have failed on a mismatched type, but err is nil")
+	}
+	if err := MergeStructs(&dest, "not a struct"); err == nil {
+		t.Fatalf("MergeStructs should have failed on a src that isn't a struct, but err is nil")
+	}
+}
+
+type testmergeoptions struct {
+	Name     string   `optname:"WithUsername"`
+	PhoneNum int      `optname:"WithPhoneNum"`
+	Items    []string `optname:"WithItem"`
+	Unknown  string   `optname:"WithUnknown"`
+}
+
+type testmergemismatch struct {
+	PhoneNum string `optname:"WithPhoneNum"`
+}
+
+func TestMergeExtract(t *testing.T) {
+	base := testmergemapoptions{
+		Labels: map[string]string{"env": "dev", "team": "core"},
+		Limits: map[string]map[string]int{"cpu": {"max": 2}},
+		Hosts:  map[string][]string{"db": {"a"}},
+	}
+	override := testmergemapoptions{
+		Labels: map[string]string{"env": "prod"},
+		Limits: map[string]map[string]int{"cpu": {"min": 1}, "mem": {"max": 512}},
+		Hosts:  map[string][]string{"db": {"b"}, "cache": {"c"}},
+	}
+	dest := testmergemapoptions{}
+	if err := MergeExtract(&dest, base, &override); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if want := map[string]string{"env": "prod", "team": "core"}; !reflect.DeepEqual(dest.Labels, want) {
+		t.Fatalf("Labels should be %v, got %v", want, dest.Labels)
+	}
+	if want := map[string]map[string]int{"cpu": {"max": 2, "min": 1}, "mem": {"max": 512}}; !reflect.DeepEqual(dest.Limits, want) {
+		t.Fatalf("Limits should be %v, got %v", want, dest.Limits)
+	}
+	if want := map[string][]string{"db": {"a", "b"}, "cache": {"c"}}; !reflect.DeepEqual(dest.Hosts, want) {
+		t.Fatalf("Hosts should be %v, got %v", want, dest.Hosts)
+	}
+	// layers are left as they were
+	if len(base.Limits["cpu"]) != 1 || len(base.Hosts["db"]) != 1 {
+		t.Fatalf("base should not have changed, got %+v", base)
+	}
+
+	// slices in maps are replaced without slicemode append
+	replaced := testmergemapoptions{}
+	err := MergeExtract(&replaced,
+		testmergemapoptions{Replaced: map[string][]string{"db": {"a"}}},
+		testmergemapoptions{Replaced: map[string][]string{"db": {"b"}}},
+	)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if want := map[string][]string{"db": {"b"}}; !reflect.DeepEqual(replaced.Replaced, want) {
+		t.Fatalf("Replaced should be %v, got %v", want, replaced.Replaced)
+	}
+
+	// an empty map allocates without adding keys
+	empty := testmergemapoptions{}
+	if err := MergeExtract(&empty, testmergemapoptions{Labels: map[string]string{}}); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if empty.Labels == nil || len(empty.Labels) != 0 {
+		t.Fatalf("Labels should be an empty map, got %v", empty.Labels)
+	}
+
+	if err := MergeExtract(&dest, base, "not a struct"); err == nil || !strings.HasPrefix(err.Error(), "layer 1: ") {
+		t.Fatalf("err should name layer 1, got %v", err)
+	}
+}
+
+type testmergemapoptions struct {
+	Labels   map[string]string         `optname:"WithLabels"`
+	Limits   map[string]map[string]int `optname:"WithLimits"`
+	Hosts    map[string][]string       `optname:"WithHosts" slicemode:"append"`
+	Replaced map[string][]string       `optname:"WithReplaced"`
+}
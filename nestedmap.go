@@ -0,0 +1,185 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ExtractNestedMap extracts a generic nested map, such as the settings of
+// Viper or decoded JSON, into dest struct. Keys not in dest are skipped.
+//
+// A key matches the field with the same optname, and a key holding a map
+// matches a nested struct field or group with the same name, whose fields the
+// map is extracted into in turn. Keys are matched exactly first and then
+// regardless of case, since such maps often lowercase their keys. A list is
+// extracted into a slice field element by element.
+//
+// Values are converted as with ExtractWithCoercion, and between numeric kinds
+// when they fit, so the float64 numbers decoded from JSON fill integer fields
+// as long as they hold whole numbers. Maps decoded with json.Decoder.UseNumber,
+// as ExtractJSON does, hold json.Number values, which fill integer fields
+// exactly even beyond 2^53.
+func ExtractNestedMap(dest interface{}, data map[string]interface{}) error {
+	return extractNestedMap(dest, data, false)
+}
+
+// MustExtractNestedMap is ExtractNestedMap where keys not in dest result in
+// error.
+func MustExtractNestedMap(dest interface{}, data map[string]interface{}) error {
+	return extractNestedMap(dest, data, true)
+}
+
+// Underlying nested map function.
+func extractNestedMap(dest interface{}, data map[string]interface{}, mustFind bool) error {
+	optionStruct, err := destStruct(dest)
+	if err != nil {
+		return err
+	}
+	return (&extractor{mustFind: mustFind, coerce: true, convertNumbers: true}).extractMap(optionStruct, data, "")
+}
+
+// extractMap extracts data into optionStruct. path is the dotted path of keys
+// leading to data, for errors.
+func (x *extractor) extractMap(optionStruct reflect.Value, data map[string]interface{}, path string) error {
+	fieldMap, err := x.prepare(optionStruct)
+	if err != nil {
+		return err
+	}
+	if err := x.assignMap(optionStruct, fieldMap, data, path); err != nil {
+		return err
+	}
+	return x.finish(optionStruct, fieldMap)
+}
+
+// assignMap assigns data into optionStruct, descending into nested structs
+// that share the namespace of optionStruct.
+func (x *extractor) assignMap(optionStruct reflect.Value, fieldMap map[string]reflect.StructField, data map[string]interface{}, path string) error {
+	// apply in a stable order so errors are deterministic
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		keyPath := key
+		if path != "" {
+			keyPath = path + "." + key
+		}
+		value := data[key]
+		nested, isMap := value.(map[string]interface{})
+
+		if optname, found := lookupFold(fieldMap, key); found {
+			field := fieldMap[optname]
+			// a map for a struct field extracts into it as a namespace
+			if isMap && isStruct(field.Type) {
+				fieldValue, _ := fieldByIndex(optionStruct, field.Index, true)
+				if err := x.extractMap(structValue(fieldValue), nested, keyPath); err != nil {
+					return err
+				}
+				continue
+			}
+			if list, ok := value.([]interface{}); ok && field.Type.Kind() == reflect.Slice {
+				for i, elem := range list {
+					if err := x.assign(optionStruct, fieldMap, optname, reflect.ValueOf(elem)); err != nil {
+						return fmt.Errorf("key %s[%d]: %w", keyPath, i, err)
+					}
+				}
+				continue
+			}
+			if err := x.assign(optionStruct, fieldMap, optname, reflect.ValueOf(value)); err != nil {
+				return fmt.Errorf("key %s: %w", keyPath, err)
+			}
+			continue
+		}
+
+		if isMap {
+			if sf, found := nestedStructField(optionStruct.Type(), key); found {
+				if _, grouped := sf.Tag.Lookup("group"); grouped {
+					fieldValue, _ := fieldByIndex(optionStruct, sf.Index, true)
+					if err := x.extractMap(structValue(fieldValue), nested, keyPath); err != nil {
+						return err
+					}
+					continue
+				}
+				// nested structs share the namespace of optionStruct
+				if err := x.assignMap(optionStruct, fieldMap, nested, keyPath); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		if x.mustFind {
+			return fmt.Errorf("invalid key %s", keyPath)
+		}
+	}
+	return nil
+}
+
+// lookupFold finds the optname of fieldMap matching key, exactly or otherwise
+// regardless of case.
+func lookupFold(fieldMap map[string]reflect.StructField, key string) (string, bool) {
+	if _, found := fieldMap[key]; found {
+		return key, true
+	}
+	for _, optname := range orderedFields(fieldMap) {
+		if strings.EqualFold(optname, key) {
+			return optname, true
+		}
+	}
+	return "", false
+}
+
+// nestedStructField finds the untagged struct field or group of t named by
+// key, exactly or otherwise regardless of case.
+func nestedStructField(t reflect.Type, key string) (reflect.StructField, bool) {
+	for _, fold := range []bool{false, true} {
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if !isStruct(sf.Type) || sf.Tag.Get("optname") != "" || !sf.IsExported() {
+				continue
+			}
+			name := sf.Name
+			if group := sf.Tag.Get("group"); group != "" {
+				name = group
+			}
+			if name == key || (fold && strings.EqualFold(name, key)) {
+				return sf, true
+			}
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// structValue returns the struct a struct or pointer field holds, allocating
+// nil pointers.
+func structValue(fieldValue reflect.Value) reflect.Value {
+	if fieldValue.Kind() != reflect.Ptr {
+		return fieldValue
+	}
+	if fieldValue.IsNil() {
+		fieldValue.Set(reflect.New(fieldValue.Type().Elem()))
+	}
+	return fieldValue.Elem()
+}
@@ -0,0 +1,63 @@
+package opts
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestExtractNestedMap(t *testing.T) {
+	var data map[string]interface{}
+	err := json.Unmarshal([]byte(`{
+		"withusername": "userbob",
+		"WithPhoneNum": 8675309,
+		"withitem": ["a", "b"],
+		"server": {"withhost": "app.internal", "withport": 8080},
+		"db": {"WithHost": "db.internal", "WithPort": "5432"},
+		"WithAdmin": {"WithUser": "root"},
+		"unknown": 1
+	}`), &data)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	opts := testnestedmapoptions{}
+	if err := ExtractNestedMap(&opts, data); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Username != "userbob" || opts.PhoneNum != 8675309 {
+		t.Fatalf("top level keys should have applied, got %+v", opts)
+	}
+	if len(opts.Items) != 2 || opts.Items[0] != "a" || opts.Items[1] != "b" {
+		t.Fatalf("Items should be [a b], got %v", opts.Items)
+	}
+	if opts.Server == nil || opts.Server.Host != "app.internal" || opts.Server.Port != 8080 {
+		t.Fatalf("Server should be {app.internal 8080}, got %+v", opts.Server)
+	}
+	if opts.DB.Host != "db.internal" || opts.DB.Port != 5432 {
+		t.Fatalf("DB should be {db.internal 5432}, got %+v", opts.DB)
+	}
+	if opts.Admin.User != "root" {
+		t.Fatalf("Admin.User should be 'root', got '%s'", opts.Admin.User)
+	}
+
+	if err := MustExtractNestedMap(&testnestedmapoptions{}, data); err == nil {
+		t.Fatalf("MustExtractNestedMap should have failed on an unknown key, but err is nil")
+	}
+	err = ExtractNestedMap(&testnestedmapoptions{}, map[string]interface{}{"WithPhoneNum": 1.5})
+	if err == nil {
+		t.Fatalf("ExtractNestedMap should have failed on a fractional PhoneNum, but err is nil")
+	}
+}
+
+type testnestedmapadmin struct {
+	User string `optname:"WithUser"`
+}
+
+type testnestedmapoptions struct {
+	Username string   `optname:"WithUsername"`
+	PhoneNum int      `optname:"WithPhoneNum"`
+	Items    []string `optname:"WithItem"`
+	Server   *testserver
+	DB       testserver         `group:"DB"`
+	Admin    testnestedmapadmin `optname:"WithAdmin"`
+}
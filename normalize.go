@@ -0,0 +1,103 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// normalizers are the transforms a normalize tag can list.
+//
+//	trim   remove leading and trailing white space
+//	lower  map to lower case
+//	upper  map to upper case
+//	nfc    Unicode normalization form C
+//	nfd    Unicode normalization form D
+var normalizers = map[string]func(string) string{
+	"trim":  strings.TrimSpace,
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"nfc":   norm.NFC.String,
+	"nfd":   norm.NFD.String,
+}
+
+// parseNormalize parses a comma separated normalize tag into its transforms.
+func parseNormalize(tag string) ([]func(string) string, error) {
+	if tag == "" {
+		return nil, nil
+	}
+	var transforms []func(string) string
+	for _, name := range strings.Split(tag, ",") {
+		transform, found := normalizers[strings.TrimSpace(name)]
+		if !found {
+			return nil, fmt.Errorf("unknown normalize transform %q", name)
+		}
+		transforms = append(transforms, transform)
+	}
+	return transforms, nil
+}
+
+// normalizable reports whether a field of type t can carry a normalize tag,
+// which is checked when the fields of a struct are mapped.
+func normalizable(t reflect.Type) bool {
+	return t.Kind() == reflect.String || (t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.String)
+}
+
+// normalizeFields canonicalizes the string fields, and string elements of
+// slice fields, that carry a normalize tag such as normalize:"trim,lower". The
+// transforms apply left to right once options have been assigned, in
+// declaration order. Tags on fields of other kinds are refused when the fields
+// are mapped, before any option is assigned.
+func normalizeFields(optionStruct reflect.Value, fieldMap map[string]reflect.StructField) error {
+	for _, optname := range orderedFields(fieldMap) {
+		field := fieldMap[optname]
+		transforms, err := parseNormalize(field.Tag.Get("normalize"))
+		if err != nil || transforms == nil {
+			continue
+		}
+		fieldValue, ok := fieldByIndex(optionStruct, field.Index, false)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case fieldValue.Kind() == reflect.String:
+			fieldValue.SetString(applyNormalize(transforms, fieldValue.String()))
+		case normalizable(fieldValue.Type()):
+			for i := 0; i < fieldValue.Len(); i++ {
+				fieldValue.Index(i).SetString(applyNormalize(transforms, fieldValue.Index(i).String()))
+			}
+		default:
+			return fmt.Errorf("field %s: normalize only applies to strings", field.Name)
+		}
+	}
+	return nil
+}
+
+// applyNormalize runs s through transforms in order.
+func applyNormalize(transforms []func(string) string, s string) string {
+	for _, transform := range transforms {
+		s = transform(s)
+	}
+	return s
+}
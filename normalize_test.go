@@ -0,0 +1,59 @@
+package opts
+
+import (
+	"testing"
+)
+
+func TestNormalizeFields(t *testing.T) {
+	opts := testnormalizeoptions{}
+	err := Extract(&opts,
+		WithUsername("  UserBob \n"),
+		WithItem(" Hello "),
+		WithItem("WORLD"),
+		WithName(7),
+		WithHost("cafe\u0301"),
+	)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Username != "userbob" {
+		t.Fatalf("Username should be 'userbob', got '%s'", opts.Username)
+	}
+	if len(opts.Items) != 2 || opts.Items[0] != "HELLO" || opts.Items[1] != "WORLD" {
+		t.Fatalf("Items should be [HELLO WORLD], got %v", opts.Items)
+	}
+	if opts.Host != "caf\u00e9" {
+		t.Fatalf("Host should be NFC normalized, got %q", opts.Host)
+	}
+
+	bad := testbadnormalizeoptions{}
+	if err := Extract(&bad); err == nil {
+		t.Fatalf("Extract should have failed on an unknown transform, but err is nil")
+	}
+}
+
+type testnormalizeoptions struct {
+	Username string   `optname:"WithUsername" normalize:"trim,lower"`
+	Items    []string `optname:"WithItem" normalize:"trim,upper"`
+	Host     string   `optname:"WithHost" normalize:"nfc"`
+}
+
+type testbadnormalizeoptions struct {
+	Username string `optname:"WithUsername" normalize:"trim,shout"`
+}
+
+func TestNormalizeTagKinds(t *testing.T) {
+	opts := testmixednormalizeoptions{}
+	if err := Extract(&opts, WithUsername(" UserBob "), WithPhoneNum(5)); err == nil {
+		t.Fatalf("Extract should have failed on a normalized int field, but err is nil")
+	}
+	// the tag is refused before any option is assigned
+	if opts.Username != "" || opts.PhoneNum != 0 {
+		t.Fatalf("no option should have been assigned, got %+v", opts)
+	}
+}
+
+type testmixednormalizeoptions struct {
+	Username string `optname:"WithUsername" normalize:"trim"`
+	PhoneNum int    `optname:"WithPhoneNum" normalize:"trim"`
+}
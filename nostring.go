@@ -0,0 +1,52 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"reflect"
+)
+
+// ExtractNoStringCoercion extracts options into dest struct, refusing to turn
+// strings into numbers or numbers into strings, for configs where a number
+// arriving in place of a string, or the other way around, is a bug. It is the
+// opposite of ExtractWithCoercion. A string option bound for a numeric field,
+// or for a slice or array of numbers, results in a *KindMismatchError rather
+// than being parsed by a quantity or runechar tag or a registered converter,
+// and so does a numeric option bound for a string field. Strings decoded into
+// bytes by an encoding tag aren't numbers and still fit. Options not in dest
+// are skipped.
+func ExtractNoStringCoercion(dest interface{}, options ...interface{}) error {
+	return (&extractor{noStringCoercion: true}).extract(dest, options...)
+}
+
+// checkStringCoercion reports a *KindMismatchError when fitting the option
+// would turn a string into a number or a number into a string.
+func checkStringCoercion(field reflect.Value, sf reflect.StructField, optname string, optionValue reflect.Value) error {
+	kind := field.Kind()
+	if kind == reflect.Slice || kind == reflect.Array {
+		if _, found := sf.Tag.Lookup("encoding"); found {
+			return nil
+		}
+		kind = field.Type().Elem().Kind()
+	}
+	if (kind == reflect.String && numericKind(optionValue.Kind())) || (numericKind(kind) && optionValue.Kind() == reflect.String) {
+		return &KindMismatchError{OptName: optname, Want: kind.String(), Got: optionValue.Kind().String()}
+	}
+	return nil
+}
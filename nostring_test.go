@@ -0,0 +1,42 @@
+package opts
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestExtractNoStringCoercion(t *testing.T) {
+	opts := testnostringoptions{}
+	err := ExtractNoStringCoercion(&opts, WithUsername("userbob"), WithPhoneNum(8675309), WithCert("aGk="))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Username != "userbob" || opts.PhoneNum != 8675309 || string(opts.Cert) != "hi" {
+		t.Fatalf("matching kinds should have applied, got %+v", opts)
+	}
+
+	// quantities parse strings with Extract, but not here
+	if err := Extract(&testnostringoptions{}, WithMemory("1Ki")); err != nil {
+		t.Fatalf("%s", err)
+	}
+	var mismatch *KindMismatchError
+	if err := ExtractNoStringCoercion(&testnostringoptions{}, WithMemory("1Ki")); !errors.As(err, &mismatch) {
+		t.Fatalf("err should be a *KindMismatchError, got %v", err)
+	}
+	if mismatch.Want != "int64" || mismatch.Got != "string" {
+		t.Fatalf("err should want int64 and got string, got %+v", mismatch)
+	}
+	if err := ExtractNoStringCoercion(&testnostringoptions{}, WithNick(7)); !errors.As(err, &mismatch) {
+		t.Fatalf("err should be a *KindMismatchError, got %v", err)
+	}
+}
+
+type WithNick int
+
+type testnostringoptions struct {
+	Username string `optname:"WithUsername"`
+	PhoneNum int    `optname:"WithPhoneNum"`
+	Memory   int64  `optname:"WithMemory" quantity:"bytes"`
+	Cert     []byte `optname:"WithCert" encoding:"base64"`
+	Nick     string `optname:"WithNick" jsonfit:"true"`
+}
@@ -0,0 +1,136 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// OverflowError is returned when a number doesn't survive the conversion into
+// the type of its field, such as 300 bound for an int8 or -1 for a uint,
+// rather than assigning a wrapped value.
+type OverflowError struct {
+	OptName    string
+	Field      string
+	Value      interface{}
+	TargetType reflect.Type
+}
+
+func (e *OverflowError) Error() string {
+	return fmt.Sprintf("failed to set %s, %v does not fit into field %s of type %s", e.OptName, e.Value, e.Field, e.TargetType.String())
+}
+
+// numericKind reports whether k is an integer or floating point kind.
+func numericKind(k reflect.Kind) bool {
+	return signedKind(k) || unsignedKind(k) || floatKind(k)
+}
+
+func signedKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
+
+func unsignedKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+func floatKind(k reflect.Kind) bool {
+	return k == reflect.Float32 || k == reflect.Float64
+}
+
+// convertNumber converts a numeric optionValue into numeric type t. Values that
+// don't survive the conversion, such as overflowing or fractional numbers
+// bound for an integer, result in an *OverflowError. ok reports whether both kinds are
+// numeric.
+func convertNumber(t reflect.Type, sf reflect.StructField, optname string, optionValue reflect.Value) (fitted reflect.Value, ok bool, err error) {
+	if !numericKind(t.Kind()) || !numericKind(optionValue.Kind()) {
+		return reflect.Value{}, false, nil
+	}
+	fitted = reflect.New(t).Elem()
+	lost := &OverflowError{OptName: optname, Field: sf.Name, Value: optionValue.Interface(), TargetType: t}
+
+	switch {
+	case signedKind(t.Kind()):
+		var n int64
+		switch {
+		case signedKind(optionValue.Kind()):
+			n = optionValue.Int()
+		case unsignedKind(optionValue.Kind()):
+			if optionValue.Uint() > math.MaxInt64 {
+				return fitted, true, lost
+			}
+			n = int64(optionValue.Uint())
+		default:
+			f := optionValue.Float()
+			if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+				return fitted, true, lost
+			}
+			n = int64(f)
+		}
+		if fitted.OverflowInt(n) {
+			return fitted, true, lost
+		}
+		fitted.SetInt(n)
+	case unsignedKind(t.Kind()):
+		var n uint64
+		switch {
+		case signedKind(optionValue.Kind()):
+			if optionValue.Int() < 0 {
+				return fitted, true, lost
+			}
+			n = uint64(optionValue.Int())
+		case unsignedKind(optionValue.Kind()):
+			n = optionValue.Uint()
+		default:
+			f := optionValue.Float()
+			if f != math.Trunc(f) || f < 0 || f >= math.MaxUint64 {
+				return fitted, true, lost
+			}
+			n = uint64(f)
+		}
+		if fitted.OverflowUint(n) {
+			return fitted, true, lost
+		}
+		fitted.SetUint(n)
+	default:
+		var f float64
+		switch {
+		case signedKind(optionValue.Kind()):
+			f = float64(optionValue.Int())
+		case unsignedKind(optionValue.Kind()):
+			f = float64(optionValue.Uint())
+		default:
+			f = optionValue.Float()
+		}
+		if fitted.OverflowFloat(f) {
+			return fitted, true, lost
+		}
+		fitted.SetFloat(f)
+	}
+	return fitted, true, nil
+}
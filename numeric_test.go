@@ -0,0 +1,63 @@
+package opts
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestConvertNumberOverflow(t *testing.T) {
+	sf := reflect.StructField{Name: "Field"}
+	tests := []struct {
+		target reflect.Type
+		value  interface{}
+	}{
+		{reflect.TypeOf(int8(0)), int64(300)},
+		{reflect.TypeOf(uint(0)), -1},
+		{reflect.TypeOf(int64(0)), uint64(math.MaxUint64)},
+		{reflect.TypeOf(uint16(0)), 1.5},
+	}
+	for _, test := range tests {
+		_, ok, err := convertNumber(test.target, sf, "WithValue", reflect.ValueOf(test.value))
+		var overflow *OverflowError
+		if !ok || !errors.As(err, &overflow) {
+			t.Fatalf("converting %v into %s should result in an *OverflowError, got %v", test.value, test.target, err)
+		}
+		if overflow.OptName != "WithValue" || overflow.Value != test.value || overflow.TargetType != test.target {
+			t.Fatalf("err should describe %v into %s, got %+v", test.value, test.target, overflow)
+		}
+	}
+
+	fitted, _, err := convertNumber(reflect.TypeOf(int8(0)), sf, "WithValue", reflect.ValueOf(int64(-128)))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if fitted.Int() != -128 {
+		t.Fatalf("fitted should be -128, got %d", fitted.Int())
+	}
+}
+
+func TestArrayOverflow(t *testing.T) {
+	opts := testoverflowoptions{}
+	if err := Extract(&opts, WithLevels{1, 2}); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Levels != [2]int8{1, 2} {
+		t.Fatalf("Levels should be [1 2], got %v", opts.Levels)
+	}
+
+	var overflow *OverflowError
+	if err := Extract(&opts, WithLevels{1, 300}); !errors.As(err, &overflow) {
+		t.Fatalf("err should be an *OverflowError, got %v", err)
+	}
+	if opts.Levels != [2]int8{1, 2} {
+		t.Fatalf("Levels should be left at [1 2], got %v", opts.Levels)
+	}
+}
+
+type WithLevels []int
+
+type testoverflowoptions struct {
+	Levels [2]int8 `optname:"WithLevels"`
+}
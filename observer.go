@@ -0,0 +1,108 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"reflect"
+)
+
+// ExtractEvent is what happened to one option, as told to an observer.
+type ExtractEvent struct {
+	// OptName is the name the option was looked up by.
+	OptName string
+	// Field is the dotted path of the field the option was fitted into, or
+	// empty when it was skipped.
+	Field  string
+	Action Action
+	// Value is the value of the field after the option was assigned, or nil
+	// when it was skipped or failed.
+	Value interface{}
+	// Err is why an option failed.
+	Err error
+}
+
+// ExtractWithObserver extracts options into dest struct, calling obs with an
+// event for every option as it is applied. obs is called on the goroutine of
+// the extraction, before the next option is applied. Options not in dest are
+// skipped and observed as such.
+func ExtractWithObserver(dest interface{}, obs func(ExtractEvent), options ...interface{}) error {
+	return ExtractWithObserverFilter(dest, nil, obs, options...)
+}
+
+// ExtractWithObserverFilter is ExtractWithObserver where obs is only called
+// for the options named in names, which keeps tracing a single misbehaving
+// option cheap in large option sets. Empty names observe every option.
+func ExtractWithObserverFilter(dest interface{}, names []string, obs func(ExtractEvent), options ...interface{}) error {
+	var observed map[string]bool
+	if len(names) > 0 {
+		observed = make(map[string]bool, len(names))
+		for _, name := range names {
+			observed[name] = true
+		}
+	}
+	x := &extractor{}
+	x.recordApply = func(record ApplyRecord) {
+		if observed != nil && !observed[record.OptName] {
+			return
+		}
+		obs(eventOf(record))
+	}
+	return x.extract(dest, options...)
+}
+
+// eventOf tells an observer what an apply record says happened.
+func eventOf(record ApplyRecord) ExtractEvent {
+	return ExtractEvent{OptName: record.OptName, Field: record.Field, Action: record.Action, Value: record.After, Err: record.Err}
+}
+
+// ExtractWithEventChannel is ExtractWithObserver with every event sent to
+// events instead, for consumers such as UIs and loggers that already read
+// channels on a goroutine of their own. Sends block, so no event is dropped
+// and a full channel holds up the extraction until it is read from; an
+// unbuffered channel needs a receiver running before the call. events is
+// owned by the caller and is never closed, so it can be reused across
+// extractions.
+func ExtractWithEventChannel(dest interface{}, events chan<- ExtractEvent, options ...interface{}) error {
+	return ExtractWithObserver(dest, func(event ExtractEvent) { events <- event }, options...)
+}
+
+// ExtractWithChangeOnly is ExtractWithObserver for re-extracting a config that
+// is mostly unchanged, such as on reload. An option that leaves its field equal
+// to what it held before, as reflect.DeepEqual compares them, is left out: the
+// field keeps its previous value, pointers included, and obs isn't told about
+// it. The option still counts as having set the field for requiredif and
+// requiredunless tags. Options appended into slice fields always count as
+// changes.
+func ExtractWithChangeOnly(dest interface{}, obs func(ExtractEvent), options ...interface{}) error {
+	x := &extractor{changeOnly: true}
+	x.recordApply = func(record ApplyRecord) {
+		obs(eventOf(record))
+	}
+	return x.extract(dest, options...)
+}
+
+// unchanged reports whether fitting an option under ExtractWithChangeOnly left
+// a single value field equal to previous, restoring previous when it did.
+func (x *extractor) unchanged(field, previous reflect.Value) bool {
+	if !x.changeOnly || field.Kind() == reflect.Slice || !field.CanInterface() || !reflect.DeepEqual(field.Interface(), previous.Interface()) {
+		return false
+	}
+	field.Set(previous)
+	return true
+}
@@ -0,0 +1,101 @@
+package opts
+
+import (
+	"testing"
+)
+
+func TestExtractWithObserver(t *testing.T) {
+	var events []ExtractEvent
+	obs := func(event ExtractEvent) {
+		events = append(events, event)
+	}
+
+	opts := testoptions{}
+	err := ExtractWithObserver(&opts, obs, WithUsername("userbob"), WithItem("a"), WithInvalidOption(true))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("every option should have been observed, got %+v", events)
+	}
+	if events[0].Action != ActionSet || events[0].Field != "Username" || events[0].Value != "userbob" {
+		t.Fatalf("WithUsername should have been set, got %+v", events[0])
+	}
+	if events[1].Action != ActionAppend || events[2].Action != ActionSkip {
+		t.Fatalf("WithItem should have appended and WithInvalidOption skipped, got %+v", events[1:])
+	}
+
+	events = nil
+	err = ExtractWithObserverFilter(&testoptions{}, []string{"WithItem"}, obs, WithUsername("userbob"), WithItem("a"), WithItem("b"))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(events) != 2 || events[0].OptName != "WithItem" || events[1].OptName != "WithItem" {
+		t.Fatalf("only WithItem should have been observed, got %+v", events)
+	}
+}
+
+func TestExtractWithEventChannel(t *testing.T) {
+	events := make(chan ExtractEvent)
+	received := make(chan []ExtractEvent)
+	go func() {
+		var all []ExtractEvent
+		for event := range events {
+			all = append(all, event)
+		}
+		received <- all
+	}()
+
+	opts := testoptions{}
+	err := ExtractWithEventChannel(&opts, events, WithUsername("userbob"), WithHost("localhost"))
+	close(events)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	all := <-received
+	if len(all) != 2 {
+		t.Fatalf("there should be 2 events, got %+v", all)
+	}
+	if all[0].OptName != "WithUsername" || all[0].Action != ActionSet || all[0].Value != "userbob" {
+		t.Fatalf("first event should set WithUsername, got %+v", all[0])
+	}
+	if all[1].OptName != "WithHost" || all[1].Action != ActionSkip {
+		t.Fatalf("second event should skip WithHost, got %+v", all[1])
+	}
+}
+
+func TestExtractWithChangeOnly(t *testing.T) {
+	name, other := "userbob", "userbob"
+	opts := testchangeoptions{Username: "userbob", PhoneNum: 1, PtrString: &name, Items: []string{"a"}}
+	var events []ExtractEvent
+	obs := func(event ExtractEvent) {
+		events = append(events, event)
+	}
+	err := ExtractWithChangeOnly(&opts, obs,
+		WithUsername("userbob"),
+		WithPhoneNum(2),
+		WithPtrString(&other),
+		WithItem("a"),
+	)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(events) != 2 || events[0].OptName != "WithPhoneNum" || events[1].OptName != "WithItem" {
+		t.Fatalf("only WithPhoneNum and WithItem should be observed, got %+v", events)
+	}
+	if opts.PhoneNum != 2 || len(opts.Items) != 2 {
+		t.Fatalf("changes should have applied, got %+v", opts)
+	}
+	// unchanged pointers keep pointing where they did
+	if opts.PtrString != &name {
+		t.Fatalf("PtrString should not have been reassigned")
+	}
+
+}
+
+type testchangeoptions struct {
+	Username  string   `optname:"WithUsername"`
+	PhoneNum  int      `optname:"WithPhoneNum"`
+	PtrString *string  `optname:"WithPtrString"`
+	Items     []string `optname:"WithItem"`
+}
@@ -0,0 +1,84 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// onceResult records the single extraction into a destination pointer.
+type onceResult struct {
+	once sync.Once
+	err  error
+}
+
+var (
+	onceMu      sync.Mutex
+	onceResults = make(map[interface{}]*onceResult)
+)
+
+// ExtractOnce extracts options into dest struct at most once per destination
+// pointer, even across goroutines. Later calls with the same pointer don't
+// extract again and return the error of the first call. A panic during the
+// first call is recovered and cached as its error, as with ExtractWithRecover.
+// Options not in dest are skipped.
+//
+// The first result is remembered by holding on to dest, which keeps it from
+// being garbage collected. Call ForgetOnce when a destination is done with, or
+// ResetOnce to clear every result.
+func ExtractOnce(dest interface{}, options ...interface{}) error {
+	if reflect.ValueOf(dest).Kind() != reflect.Ptr {
+		return fmt.Errorf("dest must be a pointer to a struct")
+	}
+
+	onceMu.Lock()
+	result, found := onceResults[dest]
+	if !found {
+		result = &onceResult{}
+		onceResults[dest] = result
+	}
+	onceMu.Unlock()
+
+	result.once.Do(func() {
+		defer func() {
+			if r := recover(); r != nil {
+				result.err = fmt.Errorf("panic during extraction: %v\n%s", r, stackSnippet())
+			}
+		}()
+		result.err = extract(dest, false, options...)
+	})
+	return result.err
+}
+
+// ForgetOnce drops the remembered result for dest, so the next ExtractOnce
+// with it extracts again.
+func ForgetOnce(dest interface{}) {
+	onceMu.Lock()
+	defer onceMu.Unlock()
+	delete(onceResults, dest)
+}
+
+// ResetOnce drops every remembered ExtractOnce result. It is meant for tests.
+func ResetOnce() {
+	onceMu.Lock()
+	defer onceMu.Unlock()
+	onceResults = make(map[interface{}]*onceResult)
+}
@@ -0,0 +1,74 @@
+package opts
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestExtractOnce(t *testing.T) {
+	defer ResetOnce()
+
+	opts := &testoptions{}
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := ExtractOnce(opts, WithItem("hello")); err != nil {
+				t.Errorf("%s", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(opts.Items) != 1 {
+		t.Fatalf("Items should have been extracted once, got %v", opts.Items)
+	}
+
+	// a different pointer extracts independently
+	other := &testoptions{}
+	if err := ExtractOnce(other, WithItem("world")); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(other.Items) != 1 || other.Items[0] != "world" {
+		t.Fatalf("Items should be [world], got %v", other.Items)
+	}
+
+	// the first error is cached
+	failing := &testduplicateoptions{}
+	first := ExtractOnce(failing, WithItem("hello"))
+	if first == nil {
+		t.Fatalf("ExtractOnce should have failed on duplicate optnames, but err is nil")
+	}
+	if err := ExtractOnce(failing, WithItem("hello")); err != first {
+		t.Fatalf("ExtractOnce should have returned the cached error '%s', got '%v'", first, err)
+	}
+
+	// a panic is cached as the error
+	panicking := &testoptions{}
+	first = ExtractOnce(panicking, WithUsername("userbob"), nil)
+	if first == nil || !strings.HasPrefix(first.Error(), "panic during extraction:") {
+		t.Fatalf("ExtractOnce should have reported the panic, got '%v'", first)
+	}
+	if err := ExtractOnce(panicking, WithUsername("userbob")); err != first {
+		t.Fatalf("ExtractOnce should have returned the cached panic '%s', got '%v'", first, err)
+	}
+
+	ForgetOnce(opts)
+	if err := ExtractOnce(opts, WithItem("again")); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(opts.Items) != 2 {
+		t.Fatalf("Items should have been extracted again after ForgetOnce, got %v", opts.Items)
+	}
+
+	if err := ExtractOnce(testoptions{}); err == nil {
+		t.Fatalf("ExtractOnce should have failed on a non-pointer dest, but err is nil")
+	}
+}
+
+type testduplicateoptions struct {
+	First  string `optname:"WithItem"`
+	Second string `optname:"WithItem"`
+}
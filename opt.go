@@ -0,0 +1,81 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"reflect"
+)
+
+// Opt is an option that carries its own optname along with a typed value, so
+// an option needs no type of its own:
+//
+//	opts.Opt[int]{Name: "WithPort", Value: 8080}
+//
+// Name is matched against optname tags in place of the option's type name or
+// OptName method, and Value is fitted into the field. Other structs with the
+// fields Name and Value, such as type WithHeader struct{ Name, Value string },
+// are options like any other. Named does the same for values whose type isn't
+// known until run time.
+type Opt[T any] struct {
+	Name  string
+	Value T
+}
+
+// carriedOption is implemented by Opt, which is told apart from other structs
+// by the method rather than by its shape.
+type carriedOption interface {
+	// carried returns the optname and value the option carries.
+	carried() (string, reflect.Value)
+	// withValue returns the option carrying value, of the type of Value,
+	// under the same optname.
+	withValue(value interface{}) interface{}
+}
+
+func (o Opt[T]) carried() (string, reflect.Value) {
+	value := reflect.ValueOf(&o.Value).Elem()
+	// look through interfaces to the value they hold
+	for value.Kind() == reflect.Interface {
+		value = value.Elem()
+	}
+	return o.Name, value
+}
+
+func (o Opt[T]) withValue(value interface{}) interface{} {
+	return Opt[T]{Name: o.Name, Value: value.(T)}
+}
+
+// Named returns an option that sets the field tagged with optname name to
+// value, whatever the type of value is.
+func Named(name string, value interface{}) interface{} {
+	return namedOption{name: name, value: value}
+}
+
+// isOpt reports whether v is an Opt.
+func isOpt(v reflect.Value) bool {
+	if !v.CanInterface() {
+		return false
+	}
+	_, ok := v.Interface().(carriedOption)
+	return ok
+}
+
+// resolveOpt returns the optname and value carried by an Opt.
+func resolveOpt(v reflect.Value) (string, reflect.Value) {
+	return v.Interface().(carriedOption).carried()
+}
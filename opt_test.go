@@ -0,0 +1,47 @@
+package opts
+
+import (
+	"testing"
+)
+
+func TestOpt(t *testing.T) {
+	opts := testoptions{}
+	err := MustExtract(&opts,
+		Opt[string]{Name: "WithUsername", Value: "userbob"},
+		Opt[int]{Name: "WithPhoneNum", Value: 8675309},
+		Opt[interface{}]{Name: "WithBool", Value: true},
+		Named("WithItem", "a"),
+		Named("WithItem", WithItem("b")),
+	)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Username != "userbob" || opts.PhoneNum != 8675309 || !opts.Boolean {
+		t.Fatalf("Opt values should have applied, got %+v", opts)
+	}
+	if len(opts.Items) != 2 || opts.Items[0] != "a" || opts.Items[1] != "b" {
+		t.Fatalf("Items should be [a b], got %v", opts.Items)
+	}
+
+	if err := MustExtract(&opts, Opt[int]{Name: "WithNothing", Value: 1}); err == nil {
+		t.Fatalf("MustExtract should have failed on an unknown name, but err is nil")
+	}
+	if err := MustExtract(&opts, Opt[string]{Name: "WithPhoneNum", Value: "not a number"}); err == nil {
+		t.Fatalf("MustExtract should have failed on a value that doesn't fit, but err is nil")
+	}
+
+	// structs shaped like Opt are options of their own type
+	headers := testshapedoptions{}
+	if err := MustExtract(&headers, WithHeader{Name: "Accept", Value: "text/plain"}); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if headers.Header.Name != "Accept" || headers.Header.Value != "text/plain" {
+		t.Fatalf("Header should be {Accept text/plain}, got %+v", headers.Header)
+	}
+}
+
+type WithHeader struct{ Name, Value string }
+
+type testshapedoptions struct {
+	Header WithHeader `optname:"WithHeader"`
+}
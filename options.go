@@ -0,0 +1,64 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"fmt"
+)
+
+// Options is an ordered bundle of named option values. It can be built up,
+// merged and inspected before being extracted, as a typed alternative to
+// passing a raw []interface{} of options.
+//
+// Names are matched against optname tags directly instead of being derived
+// from the value's type. A name is formatted with fmt, so K is usually a
+// string or a type based on one.
+type Options[K comparable, V any] struct {
+	names  []K
+	values []V
+}
+
+// Add appends a named value to the bundle.
+func (o *Options[K, V]) Add(name K, value V) {
+	o.names = append(o.names, name)
+	o.values = append(o.values, value)
+}
+
+// Merge appends every named value of other to the bundle, after the values
+// already held.
+func (o *Options[K, V]) Merge(other *Options[K, V]) {
+	o.names = append(o.names, other.names...)
+	o.values = append(o.values, other.values...)
+}
+
+// Names returns the names held by the bundle in the order they were added.
+func (o *Options[K, V]) Names() []K {
+	names := make([]K, len(o.names))
+	copy(names, o.names)
+	return names
+}
+
+// Extract the bundle into dest struct. Names not in dest are skipped.
+func (o *Options[K, V]) Extract(dest interface{}) error {
+	options := make([]interface{}, len(o.names))
+	for i := range o.names {
+		options[i] = namedOption{name: fmt.Sprint(o.names[i]), value: o.values[i]}
+	}
+	return extract(dest, false, options...)
+}
@@ -0,0 +1,53 @@
+package opts
+
+import (
+	"testing"
+)
+
+func TestOptionsContainer(t *testing.T) {
+	base := &Options[string, interface{}]{}
+	base.Add("WithUsername", "userbob")
+	base.Add("WithItem", "hello")
+
+	extra := &Options[string, interface{}]{}
+	extra.Add("WithItem", "world")
+	extra.Add("WithPhoneNum", 8675309)
+	extra.Add("WithUnknown", true)
+
+	base.Merge(extra)
+
+	names := base.Names()
+	expected := []string{"WithUsername", "WithItem", "WithItem", "WithPhoneNum", "WithUnknown"}
+	if len(names) != len(expected) {
+		t.Fatalf("Names should be %v, got %v", expected, names)
+	}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Fatalf("Names should be %v, got %v", expected, names)
+		}
+	}
+
+	opts := testoptions{}
+	if err := base.Extract(&opts); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Username != "userbob" {
+		t.Fatalf("Username should be 'userbob', got '%s'", opts.Username)
+	}
+	if len(opts.Items) != 2 || opts.Items[0] != "hello" || opts.Items[1] != "world" {
+		t.Fatalf("Items should be [hello world], got %v", opts.Items)
+	}
+	if opts.PhoneNum != 8675309 {
+		t.Fatalf("PhoneNum should be 8675309, got %d", opts.PhoneNum)
+	}
+
+	typed := &Options[string, int]{}
+	typed.Add("WithPhoneNum", 5551234)
+	opts = testoptions{}
+	if err := typed.Extract(&opts); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.PhoneNum != 5551234 {
+		t.Fatalf("PhoneNum should be 5551234, got %d", opts.PhoneNum)
+	}
+}
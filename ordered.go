@@ -0,0 +1,144 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"reflect"
+	"sort"
+)
+
+// orderedOption carries an option along with the key its elements are sorted
+// by.
+type orderedOption struct {
+	key    int
+	option interface{}
+}
+
+// Ordered wraps an option with a key, so that the elements it appends into a
+// slice field are placed by key instead of by call order, which keeps slice
+// contents reproducible however callers order their options. Options that
+// aren't wrapped have key 0, and elements of equal keys keep their call
+// order. The key carries into bundles and groups that are wrapped.
+//
+// Elements a field held before the extraction, such as its defaults, stay
+// ahead of the sorted ones. An option that replaces a slice field whole, or
+// sets one of its elements with At, starts the sorting of that field over.
+// To sort, the appended elements of a field are buffered for the rest of the
+// extraction and the field is rebuilt once all options are applied, which
+// costs a copy of every element appended while any option is wrapped.
+func Ordered(key int, option interface{}) interface{} {
+	return orderedOption{key: key, option: option}
+}
+
+// orderedAppend is the elements one option appended into a slice field.
+type orderedAppend struct {
+	key    int
+	values []reflect.Value
+}
+
+// orderedField is the elements appended into a slice field while sorting.
+type orderedField struct {
+	field   reflect.Value
+	appends []orderedAppend
+	count   int
+}
+
+// containsOrdered reports whether any option, or option carried by another,
+// is wrapped with Ordered.
+func containsOrdered(options []interface{}) bool {
+	for _, option := range expandOptions(options) {
+		switch carrier := option.(type) {
+		case orderedOption:
+			return true
+		case groupOption:
+			if containsOrdered(carrier.options) {
+				return true
+			}
+		case prioritizedOption:
+			if containsOrdered([]interface{}{carrier.option}) {
+				return true
+			}
+		case indexedOption:
+			if containsOrdered([]interface{}{carrier.option}) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// applyOrdered applies the option of an orderedOption at its key.
+func (x *extractor) applyOrdered(optionStruct reflect.Value, fieldMap map[string]reflect.StructField, carrier orderedOption) error {
+	outer := x.orderKey
+	x.orderKey = carrier.key
+	defer func() { x.orderKey = outer }()
+	for _, option := range expandOptions([]interface{}{carrier.option}) {
+		if err := x.apply(optionStruct, fieldMap, option); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordOrder buffers the elements an option appended into a slice field past
+// before, or starts the field over when the option didn't append.
+func (x *extractor) recordOrder(field reflect.Value, sf reflect.StructField, before int, optionValue reflect.Value) {
+	key := keyOf(field)
+	_, encoded := sf.Tag.Lookup("encoding")
+	replaced := optionValue.Kind() == reflect.Slice || optionValue.Kind() == reflect.Array || encoded
+	if x.indexed || replaced || field.Len() < before {
+		delete(x.orderedFields, key)
+		return
+	}
+
+	appended := orderedAppend{key: x.orderKey}
+	for i := before; i < field.Len(); i++ {
+		value := reflect.New(field.Type().Elem()).Elem()
+		value.Set(field.Index(i))
+		appended.values = append(appended.values, value)
+	}
+	if x.orderedFields == nil {
+		x.orderedFields = make(map[fieldKey]*orderedField)
+	}
+	ordered, found := x.orderedFields[key]
+	if !found {
+		ordered = &orderedField{field: field}
+		x.orderedFields[key] = ordered
+	}
+	ordered.appends = append(ordered.appends, appended)
+	ordered.count += len(appended.values)
+}
+
+// sortOrdered rebuilds every slice field elements were buffered for with its
+// elements sorted by key.
+func (x *extractor) sortOrdered() {
+	for _, ordered := range x.orderedFields {
+		sort.SliceStable(ordered.appends, func(i, j int) bool {
+			return ordered.appends[i].key < ordered.appends[j].key
+		})
+		base := ordered.field.Len() - ordered.count
+		rebuilt := reflect.MakeSlice(ordered.field.Type(), base, ordered.field.Len())
+		reflect.Copy(rebuilt, ordered.field.Slice(0, base))
+		for _, appended := range ordered.appends {
+			rebuilt = reflect.Append(rebuilt, appended.values...)
+		}
+		ordered.field.Set(rebuilt)
+	}
+	x.orderedFields = nil
+}
@@ -0,0 +1,64 @@
+package opts
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOrdered(t *testing.T) {
+	opts := testoptions{}
+	err := Extract(&opts,
+		Ordered(2, WithItem("c")),
+		WithItem("zero"),
+		Ordered(1, WithItem("b")),
+		Ordered(-1, WithItem("first")),
+		Ordered(1, WithItem("b2")),
+		WithUsername("userbob"),
+	)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	want := []string{"first", "zero", "b", "b2", "c"}
+	if !reflect.DeepEqual(opts.Items, want) {
+		t.Fatalf("Items should be %v, got %v", want, opts.Items)
+	}
+
+	// elements held before stay ahead, and replacing the slice starts over
+	opts = testoptions{Items: []string{"held"}}
+	err = Extract(&opts,
+		Ordered(5, WithItem("late")),
+		Ordered(1, WithItem("early")),
+	)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if want := []string{"held", "early", "late"}; !reflect.DeepEqual(opts.Items, want) {
+		t.Fatalf("Items should be %v, got %v", want, opts.Items)
+	}
+	opts = testoptions{}
+	err = Extract(&opts,
+		Ordered(5, WithItem("dropped")),
+		Ordered(9, WithList([]string{"x", "y"})),
+		Ordered(1, Named("WithList", "z")),
+	)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if want := []string{"x", "y", "z"}; !reflect.DeepEqual(opts.List, want) {
+		t.Fatalf("List should be %v, got %v", want, opts.List)
+	}
+
+	// groups sort their own slices
+	group := testorderedgroupoptions{}
+	err = Extract(&group, WithGroup("Inner", Ordered(2, WithItem("b")), Ordered(1, WithItem("a"))))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if want := []string{"a", "b"}; !reflect.DeepEqual(group.Inner.Items, want) {
+		t.Fatalf("Inner.Items should be %v, got %v", want, group.Inner.Items)
+	}
+}
+
+type testorderedgroupoptions struct {
+	Inner testoptions `group:"Inner"`
+}
@@ -0,0 +1,87 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ApplyOverlay applies a partial update to dest struct from overlay, a struct
+// or pointer to one whose tagged fields are all pointers, with PATCH semantics.
+// Each overlay field whose pointer isn't nil is dereferenced and its value
+// fitted into the field of dest with the same optname, while nil pointers are
+// skipped and leave dest as it was. A pointer to a zero value, such as a
+// pointer to "" or 0, is a set field and zeroes the field of dest. Overlay
+// fields not in dest are skipped.
+//
+// The values are converted as with ExtractWithCoercion, and between numeric
+// kinds when they fit, so an overlay of *int64 fills an int field and one of
+// *string fills a numeric field from its text. A dest field of the same
+// pointer type as its overlay field receives a pointer to a copy of the value
+// rather than the overlay's pointer, so the two don't share it. Defaults aren't
+// seeded, since dest holds an existing config rather than a new one, but the
+// checks run at the end of an extraction, such as normalization and
+// conditional requirements, do run.
+func ApplyOverlay(dest, overlay interface{}) error {
+	optionStruct, err := destStruct(dest)
+	if err != nil {
+		return err
+	}
+	overlayStruct := reflect.ValueOf(overlay)
+	for overlayStruct.Kind() == reflect.Ptr || overlayStruct.Kind() == reflect.Interface {
+		overlayStruct = overlayStruct.Elem()
+	}
+	if overlayStruct.Kind() != reflect.Struct {
+		return fmt.Errorf("overlay must be a struct or pointer to one, got %T", overlay)
+	}
+
+	x := &extractor{coerce: true, convertNumbers: true}
+	fieldMap, err := x.mapFields(optionStruct.Type())
+	if err != nil {
+		return err
+	}
+	overlayMap, err := x.mapFields(overlayStruct.Type())
+	if err != nil {
+		return err
+	}
+	for _, optname := range orderedFields(overlayMap) {
+		sf := overlayMap[optname]
+		if sf.Type.Kind() != reflect.Ptr {
+			return fmt.Errorf("overlay field %s must be a pointer, got %s", sf.Name, sf.Type.String())
+		}
+		value, ok := fieldByIndex(overlayStruct, sf.Index, false)
+		if !ok || value.IsNil() {
+			continue
+		}
+		field, found := fieldMap[optname]
+		if !found {
+			continue
+		}
+		optionValue := value.Elem()
+		if sf.Type.AssignableTo(field.Type) {
+			optionValue = reflect.New(sf.Type.Elem())
+			optionValue.Elem().Set(value.Elem())
+		}
+		if err := x.assign(optionStruct, fieldMap, optname, optionValue); err != nil {
+			return fmt.Errorf("overlay field %s: %w", sf.Name, err)
+		}
+	}
+	return x.finish(optionStruct, fieldMap)
+}
@@ -0,0 +1,69 @@
+package opts
+
+import (
+	"testing"
+)
+
+func TestApplyOverlay(t *testing.T) {
+	opts := testoptions{Username: "userbob", PhoneNum: 5, Boolean: true, List: []string{"a"}}
+	username, list := "useralice", []string{"b", "c"}
+	if err := ApplyOverlay(&opts, testoverlay{Username: &username, List: &list}); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Username != "useralice" || len(opts.List) != 2 || opts.List[1] != "c" {
+		t.Fatalf("set fields should have been applied, got %+v", opts)
+	}
+	// nil pointers leave the fields as they were
+	if opts.PhoneNum != 5 || !opts.Boolean {
+		t.Fatalf("nil fields should have been skipped, got %+v", opts)
+	}
+
+	// pointers to zero values are set fields
+	empty, zero, off := "", int64(0), false
+	if err := ApplyOverlay(&opts, &testoverlay{Username: &empty, PhoneNum: &zero, Boolean: &off}); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Username != "" || opts.PhoneNum != 0 || opts.Boolean {
+		t.Fatalf("zero values should have been applied, got %+v", opts)
+	}
+
+	// pointer fields get a copy rather than the overlay's pointer
+	ptr := "hello"
+	if err := ApplyOverlay(&opts, testoverlay{PtrString: &ptr}); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.PtrString == nil || *opts.PtrString != "hello" || opts.PtrString == &ptr {
+		t.Fatalf("PtrString should point to a copy of 'hello', got %v", opts.PtrString)
+	}
+
+	// pointees of other types are converted
+	phone := "8675309"
+	if err := ApplyOverlay(&opts, testtextoverlay{PhoneNum: &phone}); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.PhoneNum != 8675309 {
+		t.Fatalf("PhoneNum should be 8675309, got %d", opts.PhoneNum)
+	}
+	bad := "not a number"
+	if err := ApplyOverlay(&opts, testtextoverlay{PhoneNum: &bad}); err == nil {
+		t.Fatalf("ApplyOverlay should have failed converting PhoneNum, but err is nil")
+	}
+
+	if err := ApplyOverlay(&opts, struct {
+		Username string `optname:"WithUsername"`
+	}{}); err == nil {
+		t.Fatalf("ApplyOverlay should have failed on a field that isn't a pointer, but err is nil")
+	}
+}
+
+type testoverlay struct {
+	Username  *string   `optname:"WithUsername"`
+	PhoneNum  *int64    `optname:"WithPhoneNum"`
+	Boolean   *bool     `optname:"WithBool"`
+	List      *[]string `optname:"WithList"`
+	PtrString *string   `optname:"WithPtrString"`
+}
+
+type testtextoverlay struct {
+	PhoneNum *string `optname:"WithPhoneNum"`
+}
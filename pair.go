@@ -0,0 +1,65 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Pair is a key and a value bound for a map field. An option whose type is
+// based on a Pair, such as
+//
+//	type WithEntry Pair[int, string]
+//
+// sets the entry Key of the map field it is extracted into to Value. Any
+// struct with exactly the fields Key and Value is treated the same way.
+type Pair[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+
+// isPair reports whether v is a struct with exactly the fields Key and Value.
+func isPair(v reflect.Value) bool {
+	if v.Kind() != reflect.Struct || v.NumField() != 2 {
+		return false
+	}
+	_, hasKey := v.Type().FieldByName("Key")
+	_, hasValue := v.Type().FieldByName("Value")
+	return hasKey && hasValue
+}
+
+// fitPair sets an entry of a map field from a pair option. The key and value
+// are fitted into the map's key and element types with the same rules as any
+// option, so they may differ from them as long as they fit.
+func (x *extractor) fitPair(field reflect.Value, sf reflect.StructField, optname string, pair reflect.Value) error {
+	key := reflect.New(field.Type().Key()).Elem()
+	if err := x.fit(key, sf, optname, pair.FieldByName("Key")); err != nil {
+		return fmt.Errorf("failed to set %s, key %v does not fit the keys of field %s: %s", optname, pair.FieldByName("Key").Interface(), sf.Name, err)
+	}
+	value := reflect.New(field.Type().Elem()).Elem()
+	if err := x.fit(value, sf, optname, pair.FieldByName("Value")); err != nil {
+		return err
+	}
+	if field.IsNil() {
+		field.Set(reflect.MakeMap(field.Type()))
+	}
+	field.SetMapIndex(key, value)
+	return nil
+}
@@ -0,0 +1,49 @@
+package opts
+
+import (
+	"testing"
+)
+
+func TestPairFields(t *testing.T) {
+	opts := testpairoptions{}
+	err := Extract(&opts,
+		WithStatusText(Pair[int, string]{404, "not found"}),
+		WithStatusText(Pair[int, string]{500, "server error"}),
+		WithLevelName(Pair[testlevel, string]{testlevelDebug, "debug"}),
+		WithLevelCode(Pair[int, string]{2, "warn"}),
+	)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(opts.StatusText) != 2 || opts.StatusText[404] != "not found" || opts.StatusText[500] != "server error" {
+		t.Fatalf("StatusText should have 2 entries, got %v", opts.StatusText)
+	}
+	// keys convert into the map's key type
+	if opts.LevelName[testlevelDebug] != "debug" {
+		t.Fatalf("LevelName should have debug, got %v", opts.LevelName)
+	}
+	if opts.LevelCode[testlevel(2)] != "warn" {
+		t.Fatalf("LevelCode should have warn, got %v", opts.LevelCode)
+	}
+
+	err = Extract(&opts, WithStatusName(Pair[string, string]{"missing", "x"}))
+	if err == nil {
+		t.Fatalf("Extract should have failed on a string key for an int map, but err is nil")
+	}
+}
+
+type testlevel int
+
+const testlevelDebug testlevel = 1
+
+type WithStatusText Pair[int, string]
+type WithLevelName Pair[testlevel, string]
+type WithLevelCode Pair[int, string]
+type WithStatusName Pair[string, string]
+
+type testpairoptions struct {
+	StatusText map[int]string       `optname:"WithStatusText"`
+	LevelName  map[testlevel]string `optname:"WithLevelName"`
+	LevelCode  map[testlevel]string `optname:"WithLevelCode"`
+	StatusName map[int]string       `optname:"WithStatusName"`
+}
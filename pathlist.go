@@ -0,0 +1,47 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"os"
+	"reflect"
+	"strings"
+)
+
+// pathListSeparator separates the paths of a pathlist option, and is only
+// changed by tests.
+var pathListSeparator = os.PathListSeparator
+
+// takesPathList reports whether field is a slice of strings tagged
+// pathlist:"true".
+func takesPathList(field reflect.Value, sf reflect.StructField) bool {
+	return sf.Tag.Get("pathlist") == "true" && field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String
+}
+
+// fitPathList splits a PATH style string option on the path list separator of
+// the OS, such as : on Unix and ; on Windows, appending every path into a
+// slice field. Empty paths are dropped.
+func fitPathList(field reflect.Value, paths string) {
+	for _, path := range strings.Split(paths, string(pathListSeparator)) {
+		if path == "" {
+			continue
+		}
+		field.Set(reflect.Append(field, reflect.ValueOf(path).Convert(field.Type().Elem())))
+	}
+}
@@ -0,0 +1,42 @@
+package opts
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPathList(t *testing.T) {
+	defer func(sep rune) { pathListSeparator = sep }(pathListSeparator)
+
+	for _, test := range []struct {
+		sep   rune
+		paths string
+	}{
+		{':', "/usr/local/bin::/usr/bin:/bin:"},
+		{';', `/usr/local/bin;;/usr/bin;/bin;`},
+	} {
+		pathListSeparator = test.sep
+		opts := testpathlistoptions{}
+		if err := MustExtract(&opts, WithPath(test.paths), WithPath("/sbin")); err != nil {
+			t.Fatalf("%s", err)
+		}
+		want := []string{"/usr/local/bin", "/usr/bin", "/bin", "/sbin"}
+		if !reflect.DeepEqual(opts.Paths, want) {
+			t.Fatalf("Paths split on %q should be %v, got %v", test.sep, want, opts.Paths)
+		}
+	}
+
+	// without the tag the string is a single element
+	pathListSeparator = ':'
+	plain := testdefaultoptions{}
+	if err := Extract(&plain, WithPath("/usr/bin:/bin")); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(plain.Paths) != 3 || plain.Paths[2] != "/usr/bin:/bin" {
+		t.Fatalf("Paths should end with '/usr/bin:/bin', got %v", plain.Paths)
+	}
+}
+
+type testpathlistoptions struct {
+	Paths []string `optname:"WithPath" pathlist:"true"`
+}
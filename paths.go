@@ -0,0 +1,61 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Paths returns every tagged field of dest struct as its dotted path followed
+// by its optname, such as "TLS.Cert -> WithTLSCert", in declaration order. It
+// shares the scanning of extraction, so it shows exactly how options nested in
+// embedded and nested structs are mapped. Paths only reads the type of dest,
+// and returns nil when dest is not a struct or can't be mapped.
+func Paths(dest interface{}) []string {
+	optionStruct, err := destStruct(dest)
+	if err != nil {
+		return nil
+	}
+	fieldMap, err := (&extractor{}).mapFields(optionStruct.Type())
+	if err != nil {
+		return nil
+	}
+
+	paths := make([]string, 0, len(fieldMap))
+	for _, optname := range orderedFields(fieldMap) {
+		paths = append(paths, fieldPath(optionStruct.Type(), fieldMap[optname].Index)+" -> "+optname)
+	}
+	return paths
+}
+
+// fieldPath names the nested field of t at index by the names of the fields
+// leading to it.
+func fieldPath(t reflect.Type, index []int) string {
+	names := make([]string, len(index))
+	for i, step := range index {
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		sf := t.Field(step)
+		names[i] = sf.Name
+		t = sf.Type
+	}
+	return strings.Join(names, ".")
+}
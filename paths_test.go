@@ -0,0 +1,27 @@
+package opts
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPaths(t *testing.T) {
+	want := []string{
+		"testembedded.Items -> WithItem",
+		"Username -> WithUsername",
+		"Server.Host -> WithHost",
+		"Server.Port -> WithPort",
+	}
+	if got := Paths(&testnestedoptions{}); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Paths should be %v, got %v", want, got)
+	}
+
+	want = []string{"Outer.Inner.Host -> WithHost", "Outer.Inner.Port -> WithPort"}
+	if got := Paths(testdeepoptions{}); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Paths should be %v, got %v", want, got)
+	}
+
+	if got := Paths("not a struct"); got != nil {
+		t.Fatalf("Paths should be nil for a string, got %v", got)
+	}
+}
@@ -0,0 +1,222 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Transform is a stage of a pipeline tag. It returns value transformed, with
+// arg being the text after the colon of a parameterized stage such as
+// prefix:app-, or "" for a stage without one.
+type Transform func(value, arg string) (string, error)
+
+// transforms holds the stages pipeline tags can list, the built-in ones along
+// with those registered with RegisterTransform.
+var transforms = struct {
+	mu     sync.RWMutex
+	byName map[string]Transform
+}{byName: map[string]Transform{
+	"trim":       noArg("trim", strings.TrimSpace),
+	"lower":      noArg("lower", strings.ToLower),
+	"upper":      noArg("upper", strings.ToUpper),
+	"nfc":        noArg("nfc", normalizers["nfc"]),
+	"nfd":        noArg("nfd", normalizers["nfd"]),
+	"prefix":     func(value, arg string) (string, error) { return arg + value, nil },
+	"suffix":     func(value, arg string) (string, error) { return value + arg, nil },
+	"trimprefix": func(value, arg string) (string, error) { return strings.TrimPrefix(value, arg), nil },
+	"trimsuffix": func(value, arg string) (string, error) { return strings.TrimSuffix(value, arg), nil },
+}}
+
+// noArg adapts a transform that takes no argument into a Transform refusing
+// one.
+func noArg(name string, transform func(string) string) Transform {
+	return func(value, arg string) (string, error) {
+		if arg != "" {
+			return "", fmt.Errorf("stage %s takes no argument", name)
+		}
+		return transform(value), nil
+	}
+}
+
+// RegisterTransform registers transform as the pipeline stage name, alongside
+// the built-in ones, so pipeline:"trim|slug" can name a custom slug stage.
+// Registering a name again replaces its stage, built-in ones included, and a
+// nil transform removes it. The stages are safe to register while extractions
+// run on other goroutines. An error from a transform fails the extraction
+// naming the field.
+func RegisterTransform(name string, transform Transform) {
+	transforms.mu.Lock()
+	defer transforms.mu.Unlock()
+	if transform == nil {
+		delete(transforms.byName, name)
+		return
+	}
+	transforms.byName[name] = transform
+}
+
+// lookupTransform returns the stage registered as name.
+func lookupTransform(name string) (Transform, bool) {
+	transforms.mu.RLock()
+	defer transforms.mu.RUnlock()
+	transform, found := transforms.byName[name]
+	return transform, found
+}
+
+// pipelineStage is a stage of a pipeline tag with its argument.
+type pipelineStage struct {
+	name      string
+	arg       string
+	transform Transform
+}
+
+// parsePipeline parses a pipeline tag such as trim|lower|prefix:app- into its
+// stages. Stages are separated by | and a stage's argument follows its name
+// after the first :. A backslash keeps the character after it as is, so
+// prefix:a\|b prefixes a|b and suffix:\\ suffixes a single backslash. Colons
+// after the first are part of the argument already, as in prefix:http://.
+func parsePipeline(tag string) ([]pipelineStage, error) {
+	if tag == "" {
+		return nil, nil
+	}
+	var stages []pipelineStage
+	var name, arg strings.Builder
+	current, inArg := &name, false
+	end := func() error {
+		stage := pipelineStage{name: strings.TrimSpace(name.String()), arg: arg.String()}
+		transform, found := lookupTransform(stage.name)
+		if !found {
+			return fmt.Errorf("unknown pipeline stage %q", stage.name)
+		}
+		stage.transform = transform
+		stages = append(stages, stage)
+		name.Reset()
+		arg.Reset()
+		current, inArg = &name, false
+		return nil
+	}
+	for i := 0; i < len(tag); i++ {
+		switch c := tag[i]; {
+		case c == '\\' && i+1 < len(tag):
+			i++
+			current.WriteByte(tag[i])
+		case c == '|':
+			if err := end(); err != nil {
+				return nil, err
+			}
+		case c == ':' && !inArg:
+			current, inArg = &arg, true
+		default:
+			current.WriteByte(c)
+		}
+	}
+	if err := end(); err != nil {
+		return nil, err
+	}
+	return stages, nil
+}
+
+// pipelineFields runs the string fields, and string elements of slice fields,
+// that carry a pipeline tag such as pipeline:"trim|lower|prefix:app-" through
+// its stages left to right once options have been assigned. Stages only run
+// over the values options assigned in this extraction, so extracting into the
+// same struct again, or leaving a field to its default, never runs a value
+// through them twice. The built-in
+// stages are:
+//
+//	trim          remove leading and trailing white space
+//	lower         map to lower case
+//	upper         map to upper case
+//	nfc           Unicode normalization form C
+//	nfd           Unicode normalization form D
+//	prefix:s      add s in front
+//	suffix:s      add s at the end
+//	trimprefix:s  remove s from the front
+//	trimsuffix:s  remove s from the end
+//
+// Custom stages are added with RegisterTransform. Unknown stages result in
+// error when the fields of dest are mapped, before any option is assigned.
+// Pipelines run after the normalize tag, so a field can carry both.
+func (x *extractor) pipelineFields(optionStruct reflect.Value, fieldMap map[string]reflect.StructField) error {
+	for _, optname := range orderedFields(fieldMap) {
+		field := fieldMap[optname]
+		stages, err := parsePipeline(field.Tag.Get("pipeline"))
+		if err != nil {
+			return fmt.Errorf("field %s: %s", field.Name, err)
+		}
+		if stages == nil {
+			continue
+		}
+		fieldValue, ok := fieldByIndex(optionStruct, field.Index, false)
+		if !ok || !x.isSet(fieldValue) {
+			continue
+		}
+
+		switch {
+		case fieldValue.Kind() == reflect.String:
+			err = applyPipeline(stages, fieldValue)
+		case fieldValue.Kind() == reflect.Slice && fieldValue.Type().Elem().Kind() == reflect.String:
+			for i := x.kept[keyOf(fieldValue)]; i < fieldValue.Len() && err == nil; i++ {
+				err = applyPipeline(stages, fieldValue.Index(i))
+			}
+		default:
+			return fmt.Errorf("field %s: pipeline only applies to strings", field.Name)
+		}
+		if err != nil {
+			return fmt.Errorf("field %s: %s", field.Name, err)
+		}
+	}
+	return nil
+}
+
+// keepElements records, before an option is assigned into a pipeline slice
+// field, how many of its leading elements were there before the extraction, so
+// that only the elements options add run through the pipeline. An option
+// replacing the slice leaves none of them.
+func (x *extractor) keepElements(fieldValue reflect.Value, field reflect.StructField, optionValue reflect.Value) {
+	if fieldValue.Kind() != reflect.Slice || field.Tag.Get("pipeline") == "" || !fieldValue.CanAddr() {
+		return
+	}
+	if x.kept == nil {
+		x.kept = make(map[fieldKey]int)
+	}
+	key := keyOf(fieldValue)
+	if _, found := x.kept[key]; !found {
+		x.kept[key] = fieldValue.Len()
+	}
+	if optionValue.Kind() == reflect.Slice && !(x.strictSlice && appendsSlice(fieldValue, field, optionValue)) {
+		x.kept[key] = 0
+	}
+}
+
+// applyPipeline runs the string value through stages in order.
+func applyPipeline(stages []pipelineStage, value reflect.Value) error {
+	s := value.String()
+	for _, stage := range stages {
+		var err error
+		if s, err = stage.transform(s, stage.arg); err != nil {
+			return fmt.Errorf("pipeline stage %s: %s", stage.name, err)
+		}
+	}
+	value.SetString(s)
+	return nil
+}
@@ -0,0 +1,105 @@
+package opts
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestPipelineTag(t *testing.T) {
+	opts := testpipelineoptions{}
+	err := Extract(&opts, WithSlug(" Web "), WithHost("example.com"), WithItem(" A "), WithItem("b"))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Name != "app-web" {
+		t.Fatalf("Name should be 'app-web', got '%s'", opts.Name)
+	}
+	if opts.Host != "https://example.com|" {
+		t.Fatalf("Host should be 'https://example.com|', got '%s'", opts.Host)
+	}
+	if len(opts.Items) != 2 || opts.Items[0] != "A" || opts.Items[1] != "B" {
+		t.Fatalf("Items should be [A B], got %v", opts.Items)
+	}
+
+	// extracting again only runs the values assigned this time
+	if err := Extract(&opts, WithItem(" c ")); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Name != "app-web" || opts.Host != "https://example.com|" {
+		t.Fatalf("fields no option set should be left alone, got %+v", opts)
+	}
+	if len(opts.Items) != 3 || opts.Items[0] != "A" || opts.Items[2] != "C" {
+		t.Fatalf("Items should be [A B C], got %v", opts.Items)
+	}
+	if err := Extract(&opts, WithSlug("svc")); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Name != "app-svc" || opts.Empty != "" {
+		t.Fatalf("Name should be 'app-svc' and Empty empty, got %+v", opts)
+	}
+}
+
+func TestRegisterTransform(t *testing.T) {
+	RegisterTransform("repeat", func(value, arg string) (string, error) {
+		n := 0
+		if _, err := fmt.Sscan(arg, &n); err != nil {
+			return "", fmt.Errorf("repeat needs a count, got %q", arg)
+		}
+		return strings.Repeat(value, n), nil
+	})
+	defer RegisterTransform("repeat", nil)
+
+	opts := testtransformoptions{}
+	if err := Extract(&opts, WithSlug("ab")); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Name != "ABABAB" {
+		t.Fatalf("Name should be 'ABABAB', got '%s'", opts.Name)
+	}
+
+	err := Extract(&struct {
+		Name string `optname:"WithSlug" pipeline:"repeat:x"`
+	}{}, WithSlug("ab"))
+	if err == nil || !strings.Contains(err.Error(), "field Name") {
+		t.Fatalf("err should name field Name, got %v", err)
+	}
+}
+
+func TestPipelineTagErrors(t *testing.T) {
+	// unknown stages fail before any option is assigned
+	opts := struct {
+		Name string `optname:"WithSlug" pipeline:"trim|shout"`
+	}{}
+	err := Extract(&opts, WithSlug("web"))
+	if err == nil || !strings.Contains(err.Error(), `unknown pipeline stage "shout"`) {
+		t.Fatalf("err should report the unknown stage, got %v", err)
+	}
+	if opts.Name != "" {
+		t.Fatalf("Name should have been left alone, got '%s'", opts.Name)
+	}
+
+	if err := Extract(&struct {
+		Name string `optname:"WithSlug" pipeline:"trim:x"`
+	}{}, WithSlug("web")); err == nil {
+		t.Fatalf("Extract should have failed on an argument to trim, but err is nil")
+	}
+	if err := Extract(&struct {
+		Count int `optname:"WithCount" pipeline:"trim"`
+	}{}, WithCount("1")); err == nil {
+		t.Fatalf("Extract should have failed on a pipeline for an int, but err is nil")
+	}
+}
+
+type WithSlug string
+
+type testpipelineoptions struct {
+	Name  string   `optname:"WithSlug" pipeline:"trim|lower|prefix:app-"`
+	Host  string   `optname:"WithHost" pipeline:"prefix:https://|suffix:\\|"`
+	Items []string `optname:"WithItem" pipeline:"trim | upper"`
+	Empty string   `optname:"WithEmpty" pipeline:"prefix:-x"`
+}
+
+type testtransformoptions struct {
+	Name string `optname:"WithSlug" pipeline:"repeat:3|upper"`
+}
@@ -0,0 +1,80 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Handler claims an option at runtime. dest is a pointer to the struct being
+// extracted into and value is the option, so a handler may assign into dest
+// however it likes.
+type Handler func(dest interface{}, value interface{}) error
+
+// handlers holds the handlers registered with RegisterHandler.
+var handlers = struct {
+	mu     sync.RWMutex
+	byName map[string]Handler
+}{byName: make(map[string]Handler)}
+
+// RegisterHandler registers h as the handler for options with the optname
+// name, so plugins can extend the options a host accepts without changing its
+// struct. Registering a name again replaces its handler, and a nil h removes
+// it. The handlers are safe to register while extractions run on other
+// goroutines.
+func RegisterHandler(name string, h Handler) {
+	handlers.mu.Lock()
+	defer handlers.mu.Unlock()
+	if h == nil {
+		delete(handlers.byName, name)
+		return
+	}
+	handlers.byName[name] = h
+}
+
+// lookupHandler returns the handler registered for name.
+func lookupHandler(name string) (Handler, bool) {
+	handlers.mu.RLock()
+	defer handlers.mu.RUnlock()
+	h, found := handlers.byName[name]
+	return h, found
+}
+
+// ExtractWithPlugins extracts options into dest struct, handing every option
+// whose optname has a registered handler to that handler. A handler takes
+// precedence over a field tagged with the same optname, which is then left
+// alone. An error from a handler stops the extraction and is returned. Options
+// with neither a handler nor a field in dest are skipped.
+func ExtractWithPlugins(dest interface{}, options ...interface{}) error {
+	return (&extractor{plugins: true}).extract(dest, options...)
+}
+
+// handle calls the registered handler for optname, reporting whether there is
+// one.
+func (x *extractor) handle(optionStruct reflect.Value, optname string, optionValue reflect.Value) (bool, error) {
+	if !x.plugins || !optionValue.IsValid() {
+		return false, nil
+	}
+	h, found := lookupHandler(optname)
+	if !found {
+		return false, nil
+	}
+	return true, h(optionStruct.Addr().Interface(), optionValue.Interface())
+}
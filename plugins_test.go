@@ -0,0 +1,63 @@
+package opts
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestExtractWithPlugins(t *testing.T) {
+	RegisterHandler("WithPluginFlag", func(dest interface{}, value interface{}) error {
+		opts, ok := dest.(*testpluginoptions)
+		if !ok {
+			return fmt.Errorf("unexpected dest %T", dest)
+		}
+		opts.Flags = append(opts.Flags, string(value.(WithPluginFlag)))
+		return nil
+	})
+	RegisterHandler("WithUsername", func(dest interface{}, value interface{}) error {
+		dest.(*testpluginoptions).Username = "handled:" + string(value.(WithUsername))
+		return nil
+	})
+	defer RegisterHandler("WithPluginFlag", nil)
+	defer RegisterHandler("WithUsername", nil)
+
+	opts := testpluginoptions{}
+	err := ExtractWithPlugins(&opts, WithPluginFlag("a"), WithPluginFlag("b"), WithUsername("userbob"), WithPhoneNum(5))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(opts.Flags) != 2 || opts.Flags[0] != "a" || opts.Flags[1] != "b" {
+		t.Fatalf("Flags should be [a b], got %v", opts.Flags)
+	}
+	// handlers take precedence over tagged fields
+	if opts.Username != "handled:userbob" {
+		t.Fatalf("Username should be 'handled:userbob', got '%s'", opts.Username)
+	}
+	if opts.PhoneNum != 5 {
+		t.Fatalf("PhoneNum should be 5, got %d", opts.PhoneNum)
+	}
+
+	// plain extraction ignores handlers
+	opts = testpluginoptions{}
+	if err := Extract(&opts, WithUsername("userbob")); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Username != "userbob" {
+		t.Fatalf("Username should be 'userbob', got '%s'", opts.Username)
+	}
+
+	RegisterHandler("WithPluginFlag", func(dest interface{}, value interface{}) error {
+		return fmt.Errorf("rejected")
+	})
+	if err := ExtractWithPlugins(&opts, WithPluginFlag("a")); err == nil {
+		t.Fatalf("ExtractWithPlugins should have failed with the handler's error, but err is nil")
+	}
+}
+
+type WithPluginFlag string
+
+type testpluginoptions struct {
+	Username string `optname:"WithUsername"`
+	PhoneNum int    `optname:"WithPhoneNum"`
+	Flags    []string
+}
@@ -0,0 +1,73 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"reflect"
+)
+
+// prioritizedOption carries an option along with its priority.
+type prioritizedOption struct {
+	priority int
+	option   interface{}
+}
+
+// Prioritized wraps an option with a priority, so that the value assigned into
+// a field is decided by priority instead of by call order. An option reaches a
+// single value field only when no option of a higher priority has reached it
+// yet, and options that aren't wrapped have priority 0. Among options of equal
+// priority the last one wins, as usual.
+//
+// Slice fields take every option appended into them regardless of priority.
+// The priority carries into bundles, groups and spread structs that are
+// wrapped.
+func Prioritized(priority int, option interface{}) interface{} {
+	return prioritizedOption{priority: priority, option: option}
+}
+
+// applyPrioritized applies the option of a prioritizedOption at its priority.
+func (x *extractor) applyPrioritized(optionStruct reflect.Value, fieldMap map[string]reflect.StructField, carrier prioritizedOption) error {
+	outer := x.priority
+	x.priority = carrier.priority
+	defer func() { x.priority = outer }()
+	for _, option := range expandOptions([]interface{}{carrier.option}) {
+		if err := x.apply(optionStruct, fieldMap, option); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// outranked reports whether an option of a higher priority than the current
+// one has reached a single value field, and otherwise records the current
+// priority as the field's.
+func (x *extractor) outranked(field reflect.Value) bool {
+	if field.Kind() == reflect.Slice || !field.CanAddr() {
+		return false
+	}
+	key := keyOf(field)
+	if priority, found := x.priorities[key]; found && priority > x.priority {
+		return true
+	}
+	if x.priorities == nil {
+		x.priorities = make(map[fieldKey]int)
+	}
+	x.priorities[key] = x.priority
+	return false
+}
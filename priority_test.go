@@ -0,0 +1,45 @@
+package opts
+
+import (
+	"testing"
+)
+
+func TestPrioritized(t *testing.T) {
+	opts := testoptions{}
+	err := Extract(&opts,
+		Prioritized(10, WithUsername("admin")),
+		WithUsername("userbob"),
+		Prioritized(5, WithUsername("useralice")),
+		Prioritized(3, WithPhoneNum(1)),
+		Prioritized(3, WithPhoneNum(2)),
+		Prioritized(-1, WithItem("a")),
+		WithItem("b"),
+	)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Username != "admin" {
+		t.Fatalf("Username should be 'admin', got '%s'", opts.Username)
+	}
+	// the last of equal priorities wins
+	if opts.PhoneNum != 2 {
+		t.Fatalf("PhoneNum should be 2, got %d", opts.PhoneNum)
+	}
+	if len(opts.Items) != 2 {
+		t.Fatalf("Items should take every option, got %v", opts.Items)
+	}
+
+	// the priority carries into bundles and groups
+	grouped := testgroupoptions{}
+	err = Extract(&grouped,
+		Prioritized(1, Combine(WithHost("app.internal"), WithGroup("DB", WithHost("db.internal")))),
+		WithHost("ignored"),
+		WithGroup("DB", WithHost("ignored")),
+	)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if grouped.Host != "app.internal" || grouped.DB.Host != "db.internal" {
+		t.Fatalf("prioritized hosts should have won, got %+v", grouped)
+	}
+}
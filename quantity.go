@@ -0,0 +1,78 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// quantitySuffixes maps the suffixes a quantity may end with to the amount
+// they multiply it by. Binary suffixes are powers of 1024 and SI suffixes
+// powers of 1000.
+var quantitySuffixes = map[string]float64{
+	"Ki": 1 << 10, "Mi": 1 << 20, "Gi": 1 << 30, "Ti": 1 << 40, "Pi": 1 << 50, "Ei": 1 << 60,
+	"m": 1e-3, "k": 1e3, "K": 1e3, "M": 1e6, "G": 1e9, "T": 1e12, "P": 1e15, "E": 1e18,
+}
+
+// quantityKinds are the values a quantity tag may take.
+var quantityKinds = map[string]bool{"bytes": true, "millis": true}
+
+// parseQuantity parses a number with an optional suffix, such as 512Mi, 2G or
+// 100m. Under the bytes kind the result must be a whole number and the milli
+// suffix is refused.
+func parseQuantity(kind, s string) (float64, error) {
+	number, multiplier := strings.TrimSpace(s), 1.0
+	// binary suffixes are two characters, so they are tried first
+	for _, width := range []int{2, 1} {
+		if len(number) <= width {
+			continue
+		}
+		if m, found := quantitySuffixes[number[len(number)-width:]]; found {
+			number, multiplier = number[:len(number)-width], m
+			break
+		}
+	}
+	if kind == "bytes" && multiplier < 1 {
+		return 0, fmt.Errorf("%q is not a whole number of bytes", s)
+	}
+	f, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a quantity", s)
+	}
+	return f * multiplier, nil
+}
+
+// fitQuantity parses a string option into a numeric field tagged quantity.
+// Quantities that don't fit the field, such as fractional bytes, result in
+// error.
+func fitQuantity(field reflect.Value, sf reflect.StructField, optname, kind string, s string) error {
+	f, err := parseQuantity(kind, s)
+	if err != nil {
+		return parseError(optname, sf, err)
+	}
+	fitted, _, err := convertNumber(field.Type(), sf, optname, reflect.ValueOf(f))
+	if err != nil {
+		return err
+	}
+	field.Set(fitted)
+	return nil
+}
@@ -0,0 +1,59 @@
+package opts
+
+import (
+	"testing"
+)
+
+func TestQuantityFields(t *testing.T) {
+	cases := []struct {
+		memory string
+		want   int64
+	}{
+		{"512Mi", 512 << 20},
+		{"2Gi", 2 << 30},
+		{"1.5Ki", 1536},
+		{"3M", 3000000},
+		{"2G", 2000000000},
+		{"4096", 4096},
+	}
+	for _, c := range cases {
+		opts := testquantityoptions{}
+		if err := Extract(&opts, WithMemory(c.memory)); err != nil {
+			t.Fatalf("%s", err)
+		}
+		if opts.Memory != c.want {
+			t.Fatalf("Memory should be %d for %s, got %d", c.want, c.memory, opts.Memory)
+		}
+	}
+
+	opts := testquantityoptions{}
+	if err := Extract(&opts, WithCPU("100m")); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.CPU != 0.1 {
+		t.Fatalf("CPU should be 0.1, got %v", opts.CPU)
+	}
+
+	for _, memory := range []string{"100m", "1.5", "lots", "12Xi"} {
+		if err := Extract(&opts, WithMemory(memory)); err == nil {
+			t.Fatalf("Extract should have failed on %s bytes, but err is nil", memory)
+		}
+	}
+
+	bad := testbadquantityoptions{}
+	if err := Extract(&bad); err == nil {
+		t.Fatalf("Extract should have failed on an unknown quantity, but err is nil")
+	}
+}
+
+type WithMemory string
+type WithCPU string
+
+type testquantityoptions struct {
+	Memory int64   `optname:"WithMemory" quantity:"bytes"`
+	CPU    float64 `optname:"WithCPU" quantity:"millis"`
+}
+
+type testbadquantityoptions struct {
+	Memory int64 `optname:"WithMemory" quantity:"furlongs"`
+}
@@ -0,0 +1,52 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"fmt"
+	"runtime/debug"
+	"strings"
+)
+
+// stackSnippetLines bounds how much of the stack a recovered panic reports.
+const stackSnippetLines = 24
+
+// ExtractWithRecover extracts options into dest struct like Extract, but any
+// panic raised during extraction is returned as an error carrying the
+// recovered value and a snippet of the stack. Options not in dest are skipped.
+//
+// A panic part way through the options leaves the options before it applied,
+// so dest may be partially populated when an error is returned.
+func ExtractWithRecover(dest interface{}, options ...interface{}) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic during extraction: %v\n%s", r, stackSnippet())
+		}
+	}()
+	return extract(dest, false, options...)
+}
+
+// stackSnippet returns the leading lines of the current goroutine's stack.
+func stackSnippet() string {
+	lines := strings.Split(string(debug.Stack()), "\n")
+	if len(lines) > stackSnippetLines {
+		lines = lines[:stackSnippetLines]
+	}
+	return strings.Join(lines, "\n")
+}
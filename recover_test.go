@@ -0,0 +1,33 @@
+package opts
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExtractWithRecover(t *testing.T) {
+	opts := testoptions{}
+	err := ExtractWithRecover(&opts, WithUsername("userbob"), nil)
+	if err == nil {
+		t.Fatalf("ExtractWithRecover should have failed on a nil option, but err is nil")
+	}
+	if !strings.HasPrefix(err.Error(), "panic during extraction:") {
+		t.Fatalf("ExtractWithRecover should have reported the panic, got '%s'", err)
+	}
+	// options before the panic remain applied
+	if opts.Username != "userbob" {
+		t.Fatalf("Username should be 'userbob', got '%s'", opts.Username)
+	}
+
+	// a struct passed by value cannot be set
+	err = ExtractWithRecover(testoptions{}, WithUsername("userbob"))
+	if err == nil {
+		t.Fatalf("ExtractWithRecover should have failed on an unaddressable dest, but err is nil")
+	}
+
+	opts = testoptions{}
+	err = ExtractWithRecover(&opts, WithUsername("userbob"))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+}
@@ -0,0 +1,58 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sync"
+)
+
+var regexpType = reflect.TypeOf((*regexp.Regexp)(nil))
+
+// compiledRegexps caches the patterns compiled for regexp fields by their
+// source, so extracting the same pattern again doesn't recompile it. A
+// *regexp.Regexp is safe to share between goroutines.
+var compiledRegexps sync.Map
+
+// takesRegexp reports whether field is a *regexp.Regexp or a slice of them.
+func takesRegexp(field reflect.Value) bool {
+	return field.Type() == regexpType || (field.Kind() == reflect.Slice && field.Type().Elem() == regexpType)
+}
+
+// fitRegexp compiles a string option into a *regexp.Regexp field, or appends
+// it to a slice of them.
+func fitRegexp(field reflect.Value, sf reflect.StructField, optname, pattern string) error {
+	compiled, found := compiledRegexps.Load(pattern)
+	if !found {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("failed to set %s, invalid pattern for field %s: %s", optname, sf.Name, err)
+		}
+		compiled, _ = compiledRegexps.LoadOrStore(pattern, re)
+	}
+	re := reflect.ValueOf(compiled)
+	if field.Kind() == reflect.Slice {
+		field.Set(reflect.Append(field, re))
+		return nil
+	}
+	field.Set(re)
+	return nil
+}
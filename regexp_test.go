@@ -0,0 +1,43 @@
+package opts
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRegexpFields(t *testing.T) {
+	opts := testregexpoptions{}
+	err := MustExtract(&opts, WithPattern(`^user[0-9]+$`), WithAllow(`\.internal$`), WithAllow(`^localhost$`))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Pattern == nil || !opts.Pattern.MatchString("user42") || opts.Pattern.MatchString("userbob") {
+		t.Fatalf("Pattern should match user42 only, got %v", opts.Pattern)
+	}
+	if len(opts.Allow) != 2 || !opts.Allow[0].MatchString("db.internal") || !opts.Allow[1].MatchString("localhost") {
+		t.Fatalf("Allow should hold both patterns, got %v", opts.Allow)
+	}
+
+	// identical patterns share the compiled regexp
+	again := testregexpoptions{}
+	if err := MustExtract(&again, WithPattern(`^user[0-9]+$`)); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if again.Pattern != opts.Pattern {
+		t.Fatalf("Pattern should have been compiled once")
+	}
+
+	err = MustExtract(&testregexpoptions{}, WithPattern(`user[`))
+	if err == nil || !strings.Contains(err.Error(), "field Pattern") {
+		t.Fatalf("err should name field Pattern, got %v", err)
+	}
+}
+
+type WithPattern string
+type WithAllow string
+
+type testregexpoptions struct {
+	Pattern *regexp.Regexp   `optname:"WithPattern"`
+	Allow   []*regexp.Regexp `optname:"WithAllow"`
+}
@@ -0,0 +1,181 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// TypeRegistry maps optnames to the option types that carry them, so tagged
+// field values can be turned back into options. A TypeRegistry is safe for
+// concurrent use and its zero value is ready to use.
+type TypeRegistry struct {
+	mu    sync.RWMutex
+	types map[string]reflect.Type
+}
+
+// Register records the type of sample under the optname derived from it.
+func (r *TypeRegistry) Register(sample interface{}) error {
+	if sample == nil {
+		return fmt.Errorf("cannot register a nil sample")
+	}
+	optname, _ := resolveOption(sample)
+	return r.RegisterNamed(optname, sample)
+}
+
+// RegisterNamed records the type of sample under name. Registering the same
+// type under a name again is a no-op, but registering a different type under
+// a taken name results in error.
+func (r *TypeRegistry) RegisterNamed(name string, sample interface{}) error {
+	if sample == nil {
+		return fmt.Errorf("cannot register a nil sample for %s", name)
+	}
+	t := reflect.TypeOf(sample)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.types == nil {
+		r.types = make(map[string]reflect.Type)
+	}
+	if registered, found := r.types[name]; found && registered != t {
+		return fmt.Errorf("option name %s is already registered to %s", name, registered.String())
+	}
+	r.types[name] = t
+	return nil
+}
+
+// Lookup returns the option type registered under name.
+func (r *TypeRegistry) Lookup(name string) (reflect.Type, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	t, found := r.types[name]
+	return t, found
+}
+
+// Unextract turns the tagged fields of src struct back into options, using the
+// types in registry. Fields at their zero value are left out. A slice field
+// whose option type is its element type becomes one option per element, so
+// extracting the result appends them back. Tagged fields with no registered
+// type result in error.
+func Unextract(src interface{}, registry *TypeRegistry) ([]interface{}, error) {
+	optionStruct, err := destStruct(src)
+	if err != nil {
+		return nil, err
+	}
+	fieldMap, err := (&extractor{}).mapFields(optionStruct.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	var options []interface{}
+	for _, optname := range orderedFields(fieldMap) {
+		fieldValue, ok := fieldByIndex(optionStruct, fieldMap[optname].Index, false)
+		if !ok || fieldValue.IsZero() {
+			continue
+		}
+		fieldOptions, err := registry.options(optname, fieldValue)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, fieldOptions...)
+	}
+	return options, nil
+}
+
+// ExtractDiff returns the options that, extracted into a copy of oldSrc,
+// make its tagged fields equal to those of newSrc. Both must be the same
+// struct type. Slice fields carried by element options can only grow by
+// appending, so any other change to them results in error.
+func ExtractDiff(oldSrc, newSrc interface{}, registry *TypeRegistry) ([]interface{}, error) {
+	oldStruct, err := destStruct(oldSrc)
+	if err != nil {
+		return nil, err
+	}
+	newStruct, err := destStruct(newSrc)
+	if err != nil {
+		return nil, err
+	}
+	if oldStruct.Type() != newStruct.Type() {
+		return nil, fmt.Errorf("cannot diff %s against %s", oldStruct.Type().String(), newStruct.Type().String())
+	}
+	fieldMap, err := (&extractor{}).mapFields(newStruct.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	var options []interface{}
+	for _, optname := range orderedFields(fieldMap) {
+		index := fieldMap[optname].Index
+		newValue, ok := fieldByIndex(newStruct, index, false)
+		if !ok {
+			newValue = reflect.Zero(fieldMap[optname].Type)
+		}
+		oldValue, ok := fieldByIndex(oldStruct, index, false)
+		if !ok {
+			oldValue = reflect.Zero(fieldMap[optname].Type)
+		}
+		if reflect.DeepEqual(oldValue.Interface(), newValue.Interface()) {
+			continue
+		}
+
+		t, found := registry.Lookup(optname)
+		if !found {
+			return nil, fmt.Errorf("no type registered for option %s", optname)
+		}
+		// element options can only append onto the old slice
+		if newValue.Kind() == reflect.Slice && !newValue.Type().ConvertibleTo(t) {
+			if oldValue.Len() > newValue.Len() || !reflect.DeepEqual(oldValue.Interface(), newValue.Slice(0, oldValue.Len()).Interface()) {
+				return nil, fmt.Errorf("cannot express change to option %s by appending", optname)
+			}
+			newValue = newValue.Slice(oldValue.Len(), newValue.Len())
+		}
+
+		fieldOptions, err := registry.options(optname, newValue)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, fieldOptions...)
+	}
+	return options, nil
+}
+
+// options converts a field value into the options registered for optname.
+func (r *TypeRegistry) options(optname string, fieldValue reflect.Value) ([]interface{}, error) {
+	t, found := r.Lookup(optname)
+	if !found {
+		return nil, fmt.Errorf("no type registered for option %s", optname)
+	}
+
+	// the whole value carries over
+	if fieldValue.Type().ConvertibleTo(t) {
+		return []interface{}{fieldValue.Convert(t).Interface()}, nil
+	}
+
+	// slices carry over an element at a time
+	if fieldValue.Kind() == reflect.Slice && fieldValue.Type().Elem().ConvertibleTo(t) {
+		options := make([]interface{}, fieldValue.Len())
+		for i := range options {
+			options[i] = fieldValue.Index(i).Convert(t).Interface()
+		}
+		return options, nil
+	}
+	return nil, fmt.Errorf("cannot convert %s into option %s of type %s", fieldValue.Type().String(), optname, t.String())
+}
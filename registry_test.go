@@ -0,0 +1,102 @@
+package opts
+
+import (
+	"reflect"
+	"testing"
+)
+
+func newTestRegistry(t *testing.T) *TypeRegistry {
+	registry := &TypeRegistry{}
+	for _, sample := range []interface{}{
+		WithBool(false),
+		WithItem(""),
+		WithUsername(""),
+		WithPhoneNum(0),
+		WithPtrString(nil),
+		WithList(nil),
+	} {
+		if err := registry.Register(sample); err != nil {
+			t.Fatalf("%s", err)
+		}
+	}
+	return registry
+}
+
+func TestTypeRegistry(t *testing.T) {
+	registry := newTestRegistry(t)
+
+	// the same type registers again without complaint
+	if err := registry.Register(WithItem("")); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if err := registry.RegisterNamed("WithItem", WithUsername("")); err == nil {
+		t.Fatalf("RegisterNamed should have failed on a taken name, but err is nil")
+	}
+	if _, found := registry.Lookup("WithUsername"); !found {
+		t.Fatalf("WithUsername should be registered")
+	}
+}
+
+func TestUnextract(t *testing.T) {
+	registry := newTestRegistry(t)
+
+	original := testoptions{}
+	err := MustExtract(&original,
+		WithBool(true),
+		WithItem("hello"),
+		WithItem("world"),
+		WithUsername("userbob"),
+		WithPhoneNum(8675309),
+		WithPtrString(&strToPoint),
+		WithList([]string{"a", "b"}),
+	)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	options, err := Unextract(&original, registry)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	roundTrip := testoptions{}
+	if err := MustExtract(&roundTrip, options...); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if !reflect.DeepEqual(original, roundTrip) {
+		t.Fatalf("round trip should be %+v, got %+v", original, roundTrip)
+	}
+
+	if _, err := Unextract(&original, &TypeRegistry{}); err == nil {
+		t.Fatalf("Unextract should have failed without registered types, but err is nil")
+	}
+}
+
+func TestExtractDiff(t *testing.T) {
+	registry := newTestRegistry(t)
+
+	before := testoptions{Username: "userbob", Items: []string{"hello"}, PhoneNum: 8675309}
+	after := testoptions{Username: "useralice", Items: []string{"hello", "world"}, PhoneNum: 8675309}
+
+	options, err := ExtractDiff(&before, &after, registry)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(options) != 2 {
+		t.Fatalf("ExtractDiff should have returned 2 options, got %v", options)
+	}
+
+	updated := before
+	updated.Items = append([]string{}, before.Items...)
+	if err := MustExtract(&updated, options...); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if !reflect.DeepEqual(updated, after) {
+		t.Fatalf("applying the diff should give %+v, got %+v", after, updated)
+	}
+
+	shrunk := testoptions{Items: []string{"world"}}
+	if _, err := ExtractDiff(&before, &shrunk, registry); err == nil {
+		t.Fatalf("ExtractDiff should have failed on a slice that can't be appended to, but err is nil")
+	}
+}
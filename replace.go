@@ -0,0 +1,48 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"reflect"
+)
+
+// ExtractReplace reloads the config live points to with all-or-nothing
+// semantics. Options are extracted into an independent deep copy of *live,
+// which is then validated when it implements Validator. Only when both succeed
+// is the copy assigned to *live, so a failed reload leaves *live untouched.
+// Options not in the config are skipped.
+//
+// ExtractReplace does no locking of its own. Callers sharing live across
+// goroutines must hold their lock for the duration of the call.
+func ExtractReplace[T any](live *T, options ...interface{}) error {
+	candidate := reflect.New(reflect.TypeOf(live).Elem())
+	candidate.Elem().Set(deepCopy(reflect.ValueOf(live).Elem()))
+
+	if err := extract(candidate.Interface(), false, options...); err != nil {
+		return err
+	}
+	if validator, ok := candidate.Interface().(Validator); ok {
+		if err := validator.Validate(); err != nil {
+			return err
+		}
+	}
+
+	*live = *candidate.Interface().(*T)
+	return nil
+}
@@ -0,0 +1,52 @@
+package opts
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestExtractReplace(t *testing.T) {
+	live := testreplaceoptions{Username: "userbob", Items: []string{"hello"}}
+
+	err := ExtractReplace(&live, WithUsername("useralice"), WithItem("world"))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if live.Username != "useralice" || len(live.Items) != 2 {
+		t.Fatalf("live should have been replaced, got %+v", live)
+	}
+
+	// a failed validation leaves live untouched, including shared slices
+	before := live.Items
+	err = ExtractReplace(&live, WithUsername(""), WithItem("again"))
+	if !errors.Is(err, errNoUsername) {
+		t.Fatalf("ExtractReplace should have failed validation, got '%v'", err)
+	}
+	if live.Username != "useralice" || len(live.Items) != 2 || len(before) != 2 {
+		t.Fatalf("live should be untouched after a failed reload, got %+v", live)
+	}
+
+	// a failed extraction leaves live untouched
+	err = ExtractReplace(&live, WithItem("again"), WithPhoneNum(1))
+	if err == nil {
+		t.Fatalf("ExtractReplace should have failed to fit, but err is nil")
+	}
+	if len(live.Items) != 2 {
+		t.Fatalf("live should be untouched after a failed reload, got %+v", live)
+	}
+}
+
+var errNoUsername = errors.New("username is required")
+
+type testreplaceoptions struct {
+	Username string   `optname:"WithUsername"`
+	Items    []string `optname:"WithItem"`
+	PhoneNum bool     `optname:"WithPhoneNum"`
+}
+
+func (o *testreplaceoptions) Validate() error {
+	if o.Username == "" {
+		return errNoUsername
+	}
+	return nil
+}
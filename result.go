@@ -0,0 +1,40 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+// ExtractInto extracts options into a fresh T and returns it, so a config can
+// be built in one expression. T must be a struct type. Options not in T are
+// skipped.
+func ExtractInto[T any](options ...interface{}) (T, error) {
+	var dest T
+	err := extract(&dest, false, options...)
+	return dest, err
+}
+
+// ExtractWithResult extracts options into a fresh T as ExtractInto does, then
+// hands the populated T to callback, such as to register it with a container.
+// callback is only called when extraction succeeds, and its error is
+// returned.
+func ExtractWithResult[T any](callback func(T) error, options ...interface{}) error {
+	dest, err := ExtractInto[T](options...)
+	if err != nil {
+		return err
+	}
+	return callback(dest)
+}
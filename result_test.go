@@ -0,0 +1,48 @@
+package opts
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestExtractInto(t *testing.T) {
+	opts, err := ExtractInto[testoptions](WithUsername("userbob"), WithPhoneNum(8675309))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Username != "userbob" || opts.PhoneNum != 8675309 {
+		t.Fatalf("options should have applied, got %+v", opts)
+	}
+	if _, err := ExtractInto[int](WithPhoneNum(1)); err == nil {
+		t.Fatalf("ExtractInto should have failed on a type that isn't a struct, but err is nil")
+	}
+}
+
+func TestExtractWithResult(t *testing.T) {
+	var registered testoptions
+	err := ExtractWithResult(func(opts testoptions) error {
+		registered = opts
+		return nil
+	}, WithUsername("userbob"))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if registered.Username != "userbob" {
+		t.Fatalf("callback should have received Username 'userbob', got %+v", registered)
+	}
+
+	errRegister := errors.New("already registered")
+	err = ExtractWithResult(func(testoptions) error { return errRegister })
+	if !errors.Is(err, errRegister) {
+		t.Fatalf("err should be the callback's error, got %v", err)
+	}
+
+	called := false
+	err = ExtractWithResult(func(testoptions) error {
+		called = true
+		return nil
+	}, Opt[string]{Name: "WithPhoneNum", Value: "not a number"})
+	if err == nil || called {
+		t.Fatalf("callback shouldn't be called when extraction fails, err is %v", err)
+	}
+}
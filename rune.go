@@ -0,0 +1,45 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"fmt"
+	"reflect"
+	"unicode/utf8"
+)
+
+// takesRune reports whether a field takes a string option as the one rune it
+// holds, which fields based on int32 do when tagged runechar:"true". Since
+// rune is an alias of int32, a rune field can't be told apart from an int32
+// one and needs the tag too. Such fields take characters rather than numbers
+// from strings, even under coercion.
+func takesRune(field reflect.Value, sf reflect.StructField) bool {
+	return field.Kind() == reflect.Int32 && sf.Tag.Get("runechar") == "true"
+}
+
+// fitRune assigns the only rune of s into field. Strings of any other length
+// result in error.
+func fitRune(field reflect.Value, sf reflect.StructField, optname, s string) error {
+	r, size := utf8.DecodeRuneInString(s)
+	if size == 0 || size != len(s) || (r == utf8.RuneError && size == 1) {
+		return fmt.Errorf("failed to set %s, field %s takes a single character but got %q", optname, sf.Name, s)
+	}
+	field.SetInt(int64(r))
+	return nil
+}
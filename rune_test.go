@@ -0,0 +1,57 @@
+package opts
+
+import (
+	"testing"
+)
+
+func TestRuneFields(t *testing.T) {
+	opts := testruneoptions{}
+	if err := Extract(&opts, WithDelimiter(","), WithQuote("«")); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Delimiter != ',' {
+		t.Fatalf("Delimiter should be ',', got %q", opts.Delimiter)
+	}
+	if opts.Quote != '«' {
+		t.Fatalf("Quote should be '«', got %q", opts.Quote)
+	}
+
+	coerced := testruneoptions{}
+	if err := ExtractWithCoercion(&coerced, WithCount("5")); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if coerced.Count != 5 {
+		t.Fatalf("Count should be 5, got %d", coerced.Count)
+	}
+
+	// untagged int32 fields still parse numbers
+	ports := testruneportoptions{}
+	if err := ExtractWithCoercion(&ports, Named("WithPort", "8080"), Named("WithLevel", "7")); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if ports.Port != 8080 || ports.Level != 7 {
+		t.Fatalf("Port and Level should be 8080 and 7, got %d and %d", ports.Port, ports.Level)
+	}
+
+	for _, delimiter := range []string{"", ";;", "\xff"} {
+		if err := Extract(&opts, WithDelimiter(delimiter)); err == nil {
+			t.Fatalf("Extract should have failed on delimiter %q, but err is nil", delimiter)
+		}
+	}
+}
+
+type WithDelimiter string
+type WithQuote string
+
+type testquote int32
+
+type testruneoptions struct {
+	Delimiter rune      `optname:"WithDelimiter" runechar:"true"`
+	Quote     testquote `optname:"WithQuote" runechar:"true"`
+	Count     int32     `optname:"WithCount" runechar:"false"`
+}
+
+type testruneportoptions struct {
+	Port  int32 `optname:"WithPort"`
+	Level rune  `optname:"WithLevel"`
+}
@@ -0,0 +1,172 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// OptionSpec describes an option a struct accepts, independent of any
+// instance of the struct.
+type OptionSpec struct {
+	// Name is the optname of the option.
+	Name string
+	// Field is the dotted path of the field the option is assigned into.
+	Field string
+	// Type is the type of the field.
+	Type reflect.Type
+	// Tag is the struct tag of the field, whose encoding, quantity and
+	// similar keys decide how an option is fitted into it.
+	Tag reflect.StructTag
+	// Required options must be given, from a required:"true" tag.
+	Required bool
+	// OneOf lists the values the option may take, formatted by fmt, from a
+	// space separated oneof tag such as oneof:"debug info warn".
+	OneOf []string
+	// Min and Max bound numeric options when not nil, from min and max tags.
+	Min, Max *float64
+}
+
+// Schema describes the options dest struct accepts, in declaration order. The
+// required, oneof, min and max tags of its fields are read into the specs but
+// are only enforced by ValidateAgainstSchema.
+func Schema(dest interface{}) ([]OptionSpec, error) {
+	optionStruct, err := destStruct(dest)
+	if err != nil {
+		return nil, err
+	}
+	fieldMap, err := (&extractor{}).mapFields(optionStruct.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	specs := make([]OptionSpec, 0, len(fieldMap))
+	for _, optname := range orderedFields(fieldMap) {
+		field := fieldMap[optname]
+		spec := OptionSpec{
+			Name:     optname,
+			Field:    fieldPath(optionStruct.Type(), field.Index),
+			Type:     field.Type,
+			Tag:      field.Tag,
+			Required: field.Tag.Get("required") == "true",
+			OneOf:    strings.Fields(field.Tag.Get("oneof")),
+		}
+		bounds := []struct {
+			tag   string
+			bound **float64
+		}{{"min", &spec.Min}, {"max", &spec.Max}}
+		for _, b := range bounds {
+			value, found := field.Tag.Lookup(b.tag)
+			if !found {
+				continue
+			}
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %s %q is not a number", field.Name, b.tag, value)
+			}
+			*b.bound = &f
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+// ValidateAgainstSchema checks options against schema without extracting them
+// anywhere, so a gateway can refuse a bad option set before it reaches the
+// struct it is meant for. Every violation is reported together in one joined
+// error: options the schema doesn't name, options that don't fit the type of
+// their spec, values outside OneOf or the Min and Max bounds, and required
+// options that weren't given. The elements of slice options are checked one by
+// one.
+func ValidateAgainstSchema(schema []OptionSpec, options ...interface{}) error {
+	specs := make(map[string]OptionSpec, len(schema))
+	for _, spec := range schema {
+		specs[spec.Name] = spec
+	}
+
+	var errs []error
+	given := make(map[string]bool)
+	x := &extractor{}
+	for _, option := range expandOptions(options) {
+		optname, optionValue := resolveOption(option)
+		if !optionValue.IsValid() {
+			continue
+		}
+		spec, found := specs[optname]
+		if !found {
+			errs = append(errs, &UnknownOptionError{OptName: optname})
+			continue
+		}
+		given[optname] = true
+
+		field := reflect.New(spec.Type).Elem()
+		if err := x.fit(field, reflect.StructField{Name: spec.Field, Type: spec.Type, Tag: spec.Tag}, optname, optionValue); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		values := []reflect.Value{field}
+		if field.Kind() == reflect.Slice {
+			values = values[:0]
+			for i := 0; i < field.Len(); i++ {
+				values = append(values, field.Index(i))
+			}
+		}
+		for _, value := range values {
+			if err := spec.check(value); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	for _, spec := range schema {
+		if spec.Required && !given[spec.Name] {
+			errs = append(errs, fmt.Errorf("option %s is required", spec.Name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// check tests one value against the OneOf, Min and Max of the spec.
+func (spec OptionSpec) check(value reflect.Value) error {
+	if len(spec.OneOf) > 0 {
+		formatted := fmt.Sprint(value.Interface())
+		allowed := false
+		for _, option := range spec.OneOf {
+			allowed = allowed || option == formatted
+		}
+		if !allowed {
+			return fmt.Errorf("option %s is %s but must be one of %s", spec.Name, formatted, strings.Join(spec.OneOf, ", "))
+		}
+	}
+	if !numericKind(value.Kind()) || (spec.Min == nil && spec.Max == nil) {
+		return nil
+	}
+	f, _, _ := convertNumber(reflect.TypeOf(float64(0)), reflect.StructField{Name: spec.Field}, spec.Name, value)
+	if spec.Min != nil && f.Float() < *spec.Min {
+		return fmt.Errorf("option %s is %v but the minimum is %v", spec.Name, value.Interface(), *spec.Min)
+	}
+	if spec.Max != nil && f.Float() > *spec.Max {
+		return fmt.Errorf("option %s is %v but the maximum is %v", spec.Name, value.Interface(), *spec.Max)
+	}
+	return nil
+}
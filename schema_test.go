@@ -0,0 +1,79 @@
+package opts
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSchema(t *testing.T) {
+	schema, err := Schema(&testschemaoptions{})
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(schema) != 3 {
+		t.Fatalf("schema should describe 3 options, got %+v", schema)
+	}
+	mode := schema[0]
+	if mode.Name != "WithMode" || mode.Field != "Mode" || mode.Type != reflect.TypeOf("") || !mode.Required {
+		t.Fatalf("WithMode spec is wrong, got %+v", mode)
+	}
+	if !reflect.DeepEqual(mode.OneOf, []string{"basic", "advanced"}) {
+		t.Fatalf("WithMode should be one of [basic advanced], got %v", mode.OneOf)
+	}
+	workers := schema[1]
+	if workers.Min == nil || *workers.Min != 1 || workers.Max == nil || *workers.Max != 64 {
+		t.Fatalf("WithWorkers should be bounded by 1 and 64, got %+v", workers)
+	}
+
+	if _, err := Schema(&testbadschemaoptions{}); err == nil {
+		t.Fatalf("Schema should have failed on a min that isn't a number, but err is nil")
+	}
+}
+
+func TestValidateAgainstSchema(t *testing.T) {
+	schema, err := Schema(&testschemaoptions{})
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if err := ValidateAgainstSchema(schema, WithMode("basic"), WithWorkers(8), WithPorts("80")); err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	err = ValidateAgainstSchema(schema, WithMode("expert"), WithWorkers(100), WithUsername("userbob"))
+	if err == nil {
+		t.Fatalf("ValidateAgainstSchema should have failed, but err is nil")
+	}
+	for _, violation := range []string{"must be one of", "maximum is 64", "invalid option WithUsername"} {
+		if !strings.Contains(err.Error(), violation) {
+			t.Fatalf("err should report %q, got %s", violation, err)
+		}
+	}
+
+	err = ValidateAgainstSchema(schema, WithWorkers(0))
+	if err == nil || !strings.Contains(err.Error(), "minimum is 1") || !strings.Contains(err.Error(), "WithMode is required") {
+		t.Fatalf("err should report the minimum and the missing WithMode, got %v", err)
+	}
+
+	// options are fitted with the tags of their field
+	schema, err = Schema(&testencodingoptions{})
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if err := ValidateAgainstSchema(schema, WithCert("aGk=")); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if err := ValidateAgainstSchema(schema, WithCert("not base64!")); err == nil {
+		t.Fatalf("ValidateAgainstSchema should have failed on a bad base64 cert, but err is nil")
+	}
+}
+
+type testschemaoptions struct {
+	Mode    string   `optname:"WithMode" required:"true" oneof:"basic advanced"`
+	Workers int      `optname:"WithWorkers" min:"1" max:"64"`
+	Ports   []string `optname:"WithPorts"`
+}
+
+type testbadschemaoptions struct {
+	Workers int `optname:"WithWorkers" min:"one"`
+}
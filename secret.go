@@ -0,0 +1,74 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// secretMask replaces secret values wherever they are formatted.
+const secretMask = "****"
+
+// Secret holds a sensitive value, such as a password or token, in a tagged
+// field. Options are fitted into the held value as if the field had its type,
+// but the Secret always formats as **** and errors from fitting into it leave
+// the option value out.
+type Secret[T any] struct {
+	value T
+}
+
+// NewSecret returns a Secret holding value.
+func NewSecret[T any](value T) Secret[T] {
+	return Secret[T]{value: value}
+}
+
+// Get returns the held value.
+func (s Secret[T]) Get() T {
+	return s.value
+}
+
+// String masks the held value.
+func (s Secret[T]) String() string {
+	return secretMask
+}
+
+// Format masks the held value for every fmt verb.
+func (s Secret[T]) Format(f fmt.State, verb rune) {
+	io.WriteString(f, secretMask)
+}
+
+// secretField is implemented by *Secret so fit can reach the held value.
+type secretField interface {
+	secretValue() reflect.Value
+}
+
+func (s *Secret[T]) secretValue() reflect.Value {
+	return reflect.ValueOf(&s.value).Elem()
+}
+
+// fitSecret fits optionValue into the value held by secret. The underlying
+// error may quote the value, so it is replaced rather than wrapped.
+func (x *extractor) fitSecret(secret secretField, sf reflect.StructField, optname string, optionValue reflect.Value) error {
+	if err := x.fit(secret.secretValue(), sf, optname, optionValue); err != nil {
+		return fmt.Errorf("failed to set %s into secret field %s, value elided", optname, sf.Name)
+	}
+	return nil
+}
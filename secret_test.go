@@ -0,0 +1,62 @@
+package opts
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestSecretFields(t *testing.T) {
+	opts := testsecretoptions{}
+	err := ExtractWithCoercion(&opts, WithPassword("hunter2"), WithPin("1234"))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Password.Get() != "hunter2" {
+		t.Fatalf("Password should be 'hunter2', got '%s'", opts.Password.Get())
+	}
+	if opts.Pin.Get() != 1234 {
+		t.Fatalf("Pin should be 1234, got %d", opts.Pin.Get())
+	}
+
+	for _, format := range []string{"%v", "%s", "%+v", "%#v", "%d", "%q"} {
+		if out := fmt.Sprintf(format, opts.Password); out != "****" {
+			t.Fatalf("Password formatted with %s should be masked, got '%s'", format, out)
+		}
+	}
+	if out := fmt.Sprintf("%+v", opts); strings.Contains(out, "hunter2") {
+		t.Fatalf("formatting the options leaked the password: %s", out)
+	}
+
+	opts = testsecretoptions{}
+	err = ExtractWithCoercion(&opts, WithPin("12ab"))
+	if err == nil {
+		t.Fatalf("ExtractWithCoercion should have failed to parse the pin, but err is nil")
+	}
+	if strings.Contains(err.Error(), "12ab") {
+		t.Fatalf("error leaked the secret value: %s", err)
+	}
+	if !strings.Contains(err.Error(), "Pin") {
+		t.Fatalf("error should name the field Pin, got '%s'", err)
+	}
+
+	// a secret option assigns wholesale
+	opts = testsecretoptions{}
+	err = Extract(&opts, WithToken(NewSecret("abc")))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Token.Get() != "abc" {
+		t.Fatalf("Token should be 'abc', got '%s'", opts.Token.Get())
+	}
+}
+
+type WithPassword string
+type WithPin string
+type WithToken Secret[string]
+
+type testsecretoptions struct {
+	Password Secret[string] `optname:"WithPassword"`
+	Pin      Secret[int]    `optname:"WithPin"`
+	Token    Secret[string] `optname:"WithToken"`
+}
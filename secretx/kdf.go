@@ -0,0 +1,172 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+// Package secretx derives keys from passphrase options into option structs.
+// It is kept apart from opts so that only programs using it depend on a key
+// derivation function.
+package secretx
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"sync"
+
+	"github.com/protosam/opts"
+	"golang.org/x/crypto/argon2"
+)
+
+// secretMask replaces derived keys wherever they are formatted.
+const secretMask = "****"
+
+// The parameters of a derivation when their tags are left out, as recommended
+// for argon2id by RFC 9106.
+const (
+	defaultTime    = 1
+	defaultMemory  = 64 * 1024
+	defaultThreads = 4
+	defaultLen     = 32
+)
+
+// minSaltLen is the shortest salt argon2 allows.
+const minSaltLen = 8
+
+// Key is a key derived from a passphrase option. It always formats as ****,
+// so it can't leak into logs or error messages.
+type Key struct {
+	key []byte
+}
+
+// Bytes returns the derived key.
+func (k Key) Bytes() []byte {
+	return k.key
+}
+
+// String masks the derived key.
+func (k Key) String() string {
+	return secretMask
+}
+
+// Format masks the derived key for every fmt verb.
+func (k Key) Format(f fmt.State, verb rune) {
+	io.WriteString(f, secretMask)
+}
+
+var register sync.Once
+
+// Register registers converters with opts.RegisterFieldConverter that derive
+// string and []byte options into fields of type Key, so every extraction can
+// fit them. It is safe to call more than once.
+//
+// The field names its derivation and parameters with tags:
+//
+//	kdf        argon2 or argon2id for argon2id, argon2i for argon2i
+//	kdfsalt    the salt, at least 8 bytes
+//	kdftime    the number of passes over the memory, 1 by default
+//	kdfmemory  the memory used in KiB, 65536 by default
+//	kdfthreads the number of threads, 4 by default
+//	kdflen     the length of the key in bytes, 32 by default
+//
+// A salt in a tag is the same for every program built from it, so the same
+// passphrase always derives the same key. Fields without a kdf tag, unknown
+// derivations and malformed parameters result in error naming the field, and
+// the errors never quote the passphrase.
+//
+// The passphrase is not kept once the key is derived: the derivation works on
+// a copy of it, which is zeroed afterwards. The option itself is left to the
+// caller, so a []byte option should be zeroed once the extraction returns,
+// which a string option can't be as Go strings are immutable.
+func Register() {
+	register.Do(func() {
+		opts.RegisterFieldConverter(func(_ context.Context, sf reflect.StructField, passphrase string) (Key, error) {
+			return derive(sf, []byte(passphrase))
+		})
+		opts.RegisterFieldConverter(func(_ context.Context, sf reflect.StructField, passphrase []byte) (Key, error) {
+			// the option belongs to the caller, so derive from a copy
+			return derive(sf, append([]byte(nil), passphrase...))
+		})
+	})
+}
+
+// ExtractWithKDF extracts options into dest struct like opts.Extract, after
+// making sure the converters of Register are registered. Options not in dest
+// are skipped.
+func ExtractWithKDF(dest interface{}, options ...interface{}) error {
+	Register()
+	return opts.Extract(dest, options...)
+}
+
+// derive derives the key for field sf from passphrase and zeroes passphrase,
+// which must not share its bytes with the option.
+func derive(sf reflect.StructField, passphrase []byte) (Key, error) {
+	defer zero(passphrase)
+	salt := sf.Tag.Get("kdfsalt")
+	if len(salt) < minSaltLen {
+		return Key{}, fmt.Errorf("kdfsalt of field %s must be at least %d bytes", sf.Name, minSaltLen)
+	}
+	time, err := parameter(sf, "kdftime", defaultTime, 32)
+	if err != nil {
+		return Key{}, err
+	}
+	memory, err := parameter(sf, "kdfmemory", defaultMemory, 32)
+	if err != nil {
+		return Key{}, err
+	}
+	threads, err := parameter(sf, "kdfthreads", defaultThreads, 8)
+	if err != nil {
+		return Key{}, err
+	}
+	keyLen, err := parameter(sf, "kdflen", defaultLen, 32)
+	if err != nil {
+		return Key{}, err
+	}
+
+	switch kdf := sf.Tag.Get("kdf"); kdf {
+	case "argon2", "argon2id":
+		return Key{key: argon2.IDKey(passphrase, []byte(salt), uint32(time), uint32(memory), uint8(threads), uint32(keyLen))}, nil
+	case "argon2i":
+		return Key{key: argon2.Key(passphrase, []byte(salt), uint32(time), uint32(memory), uint8(threads), uint32(keyLen))}, nil
+	case "":
+		return Key{}, fmt.Errorf("field %s has no kdf tag", sf.Name)
+	default:
+		return Key{}, fmt.Errorf("unknown kdf %q for field %s", kdf, sf.Name)
+	}
+}
+
+// parameter parses the tag of sf named name as a positive integer of bitSize
+// bits, or returns fallback when it is left out.
+func parameter(sf reflect.StructField, name string, fallback uint64, bitSize int) (uint64, error) {
+	tag, found := sf.Tag.Lookup(name)
+	if !found {
+		return fallback, nil
+	}
+	n, err := strconv.ParseUint(tag, 10, bitSize)
+	if err != nil || n == 0 {
+		return 0, fmt.Errorf("%s of field %s must be a positive integer, got %q", name, sf.Name, tag)
+	}
+	return n, nil
+}
+
+// zero overwrites b so the passphrase it held doesn't linger in memory.
+func zero(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
@@ -0,0 +1,81 @@
+package secretx
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/protosam/opts"
+	"golang.org/x/crypto/argon2"
+)
+
+func TestExtractWithKDF(t *testing.T) {
+	opts := testkdfoptions{}
+	if err := ExtractWithKDF(&opts, WithPassphrase("correct horse")); err != nil {
+		t.Fatalf("%s", err)
+	}
+	want := argon2.IDKey([]byte("correct horse"), []byte("saltsalt"), 1, 1024, 1, 16)
+	if !bytes.Equal(opts.Key.Bytes(), want) {
+		t.Fatalf("Key should be the argon2id key of the passphrase, got %x", opts.Key.Bytes())
+	}
+	if s := fmt.Sprintf("%v %+v %x", opts.Key, opts, opts.Key); strings.Contains(s, fmt.Sprintf("%x", want)) || strings.Contains(s, "correct horse") {
+		t.Fatalf("Key should have been masked, got %s", s)
+	}
+
+	// []byte passphrases are left to the caller
+	passphrase := []byte("correct horse")
+	if err := ExtractWithKDF(&opts, WithPassphraseBytes(passphrase)); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if !bytes.Equal(opts.KeyFrom.Bytes(), want) {
+		t.Fatalf("KeyFrom should be the argon2id key of the passphrase, got %x", opts.KeyFrom.Bytes())
+	}
+	if string(passphrase) != "correct horse" {
+		t.Fatalf("passphrase should have been left alone, got %q", passphrase)
+	}
+}
+
+func TestExtractWithKDFErrors(t *testing.T) {
+	tests := map[string]interface{}{
+		"no kdf tag": &struct {
+			Key Key `optname:"WithPassphrase" kdfsalt:"saltsalt"`
+		}{},
+		"unknown kdf": &struct {
+			Key Key `optname:"WithPassphrase" kdf:"md5" kdfsalt:"saltsalt"`
+		}{},
+		"short salt": &struct {
+			Key Key `optname:"WithPassphrase" kdf:"argon2" kdfsalt:"salt"`
+		}{},
+		"bad parameter": &struct {
+			Key Key `optname:"WithPassphrase" kdf:"argon2" kdfsalt:"saltsalt" kdftime:"0"`
+		}{},
+		"threads too big": &struct {
+			Key Key `optname:"WithPassphrase" kdf:"argon2" kdfsalt:"saltsalt" kdfthreads:"256"`
+		}{},
+	}
+	for name, dest := range tests {
+		err := ExtractWithKDF(dest, WithPassphrase("correct horse"))
+		if err == nil || !strings.Contains(err.Error(), "field Key") {
+			t.Fatalf("%s: err should name field Key, got %v", name, err)
+		}
+		if strings.Contains(err.Error(), "correct horse") {
+			t.Fatalf("%s: err should not quote the passphrase, got %s", name, err)
+		}
+	}
+}
+
+func TestKDFTagsAreKnown(t *testing.T) {
+	Register()
+	if err := opts.ExtractStrictTags(&testkdfoptions{}, WithPassphrase("correct horse")); err != nil {
+		t.Fatalf("%s", err)
+	}
+}
+
+type WithPassphrase string
+type WithPassphraseBytes []byte
+
+type testkdfoptions struct {
+	Key     Key `optname:"WithPassphrase" kdf:"argon2" kdfsalt:"saltsalt" kdfmemory:"1024" kdfthreads:"1" kdflen:"16"`
+	KeyFrom Key `optname:"WithPassphraseBytes" kdf:"argon2id" kdfsalt:"saltsalt" kdfmemory:"1024" kdfthreads:"1" kdflen:"16"`
+}
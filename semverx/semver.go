@@ -0,0 +1,62 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+// Package semverx fits semantic versions into option structs. It is kept
+// apart from opts so that only programs using it depend on a semver parser.
+package semverx
+
+import (
+	"sync"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/protosam/opts"
+)
+
+var register sync.Once
+
+// Register registers converters with opts.RegisterConverter that parse string
+// options into fields of type *semver.Version, semver.Version and
+// *semver.Constraints, so every extraction can fit them. It is safe to call
+// more than once.
+//
+// A version names one release, such as 1.2.3, 1.2.3-beta.1 for a pre-release
+// or 1.2.3+build.5 with build metadata, and a leading v as in v1.2.3 is
+// accepted. A constraint names a range of versions to check a version against,
+// such as >= 1.2, < 2 or ^1.4. Malformed versions and constraints result in
+// error naming the field.
+func Register() {
+	register.Do(func() {
+		opts.RegisterConverter(semver.NewVersion)
+		opts.RegisterConverter(func(s string) (semver.Version, error) {
+			version, err := semver.NewVersion(s)
+			if err != nil {
+				return semver.Version{}, err
+			}
+			return *version, nil
+		})
+		opts.RegisterConverter(semver.NewConstraint)
+	})
+}
+
+// ExtractWithSemver extracts options into dest struct like opts.Extract, after
+// making sure the converters of Register are registered. Options not in dest
+// are skipped.
+func ExtractWithSemver(dest interface{}, options ...interface{}) error {
+	Register()
+	return opts.Extract(dest, options...)
+}
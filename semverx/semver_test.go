@@ -0,0 +1,47 @@
+package semverx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+func TestExtractWithSemver(t *testing.T) {
+	opts := testsemveroptions{}
+	err := ExtractWithSemver(&opts,
+		WithVersion("1.2.3-beta.1+build.5"),
+		WithMinimum("v2.0.1"),
+		WithSupported(">= 1.2, < 2"),
+	)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Version == nil || opts.Version.Prerelease() != "beta.1" || opts.Version.Metadata() != "build.5" {
+		t.Fatalf("Version should be 1.2.3-beta.1+build.5, got %v", opts.Version)
+	}
+	if opts.Minimum.Major() != 2 || opts.Minimum.Patch() != 1 {
+		t.Fatalf("Minimum should be 2.0.1, got %v", opts.Minimum)
+	}
+	if opts.Supported == nil || !opts.Supported.Check(semver.MustParse("1.4.0")) || opts.Supported.Check(&opts.Minimum) {
+		t.Fatalf("Supported should hold 1.4.0 but not 2.0.1, got %v", opts.Supported)
+	}
+
+	err = ExtractWithSemver(&testsemveroptions{}, WithVersion("one.two"))
+	if err == nil || !strings.Contains(err.Error(), "field Version") {
+		t.Fatalf("err should name field Version, got %v", err)
+	}
+	if err := ExtractWithSemver(&testsemveroptions{}, WithSupported(">>> 1")); err == nil {
+		t.Fatalf("ExtractWithSemver should have failed on a malformed constraint, but err is nil")
+	}
+}
+
+type WithVersion string
+type WithMinimum string
+type WithSupported string
+
+type testsemveroptions struct {
+	Version   *semver.Version     `optname:"WithVersion"`
+	Minimum   semver.Version      `optname:"WithMinimum"`
+	Supported *semver.Constraints `optname:"WithSupported"`
+}
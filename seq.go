@@ -0,0 +1,96 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"reflect"
+)
+
+// seqArity reports whether t has the shape of an iter.Seq, a
+// func(yield func(V) bool), or of an iter.Seq2, a func(yield func(K, V) bool),
+// returning how many values each yield takes, or 0 for any other type.
+func seqArity(t reflect.Type) int {
+	if t.Kind() != reflect.Func || t.NumIn() != 1 || t.NumOut() != 0 {
+		return 0
+	}
+	yield := t.In(0)
+	if yield.Kind() != reflect.Func || yield.NumOut() != 1 || yield.Out(0).Kind() != reflect.Bool {
+		return 0
+	}
+	if n := yield.NumIn(); n == 1 || n == 2 {
+		return n
+	}
+	return 0
+}
+
+// fitSeq ranges over an iterator option, appending every value an iter.Seq
+// yields to a slice field, or setting every pair an iter.Seq2 yields as an
+// entry of a map field. Each value is fitted like an option of its own, so it
+// must convert into the element type. The iterator must be finite, and it is
+// stopped at the first value that doesn't fit, leaving the field as it was.
+func (x *extractor) fitSeq(field reflect.Value, sf reflect.StructField, optname string, seq reflect.Value) error {
+	// fit into a copy so a failure leaves the field, and anything sharing
+	// its map or backing array, alone
+	fitted := reflect.New(field.Type()).Elem()
+	switch field.Kind() {
+	case reflect.Map:
+		fitted.Set(reflect.MakeMapWithSize(field.Type(), field.Len()))
+		iter := field.MapRange()
+		for iter.Next() {
+			fitted.SetMapIndex(iter.Key(), iter.Value())
+		}
+	case reflect.Slice:
+		if !field.IsNil() {
+			fitted.Set(reflect.AppendSlice(reflect.MakeSlice(field.Type(), 0, field.Len()), field))
+		}
+	default:
+		fitted.Set(field)
+	}
+
+	var err error
+	yield := reflect.MakeFunc(seq.Type().In(0), func(args []reflect.Value) []reflect.Value {
+		if len(args) == 1 {
+			err = x.fit(fitted, sf, optname, args[0])
+		} else {
+			err = x.fitEntry(fitted, sf, optname, args[0], args[1])
+		}
+		return []reflect.Value{reflect.ValueOf(err == nil)}
+	})
+	seq.Call([]reflect.Value{yield})
+	if err != nil {
+		return err
+	}
+	field.Set(fitted)
+	return nil
+}
+
+// fitEntry sets key to value in a map field, fitting both into the map's
+// types.
+func (x *extractor) fitEntry(field reflect.Value, sf reflect.StructField, optname string, key, value reflect.Value) error {
+	fittedKey := reflect.New(field.Type().Key()).Elem()
+	if err := x.fit(fittedKey, sf, optname, key); err != nil {
+		return err
+	}
+	fittedValue := reflect.New(field.Type().Elem()).Elem()
+	if err := x.fit(fittedValue, sf, optname, value); err != nil {
+		return err
+	}
+	field.SetMapIndex(fittedKey, fittedValue)
+	return nil
+}
@@ -0,0 +1,82 @@
+package opts
+
+import (
+	"testing"
+)
+
+func TestSeqOptions(t *testing.T) {
+	opts := testseqoptions{Ports: []int{22}}
+	err := MustExtract(&opts,
+		WithPortSeq(countTo(3)),
+		WithLabelSeq(func(yield func(string, int) bool) {
+			for i, name := range []string{"zero", "one"} {
+				if !yield(name, i) {
+					return
+				}
+			}
+		}),
+	)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(opts.Ports) != 4 || opts.Ports[0] != 22 || opts.Ports[3] != 3 {
+		t.Fatalf("Ports should be [22 1 2 3], got %v", opts.Ports)
+	}
+	if len(opts.Labels) != 2 || opts.Labels["one"] != 1 {
+		t.Fatalf("Labels should be map[one:1 zero:0], got %v", opts.Labels)
+	}
+
+	// the iterator stops at the first value that doesn't fit
+	yielded := 0
+	err = MustExtract(&opts, WithNameSeq(func(yield func(int) bool) {
+		for _, n := range []int{1, 2, 3} {
+			yielded++
+			if !yield(n) {
+				return
+			}
+		}
+	}))
+	if err == nil {
+		t.Fatalf("MustExtract should have failed fitting ints into []string, but err is nil")
+	}
+	if yielded != 1 || opts.Names != nil {
+		t.Fatalf("the iterator should have stopped leaving Names alone, got %d yielded and %v", yielded, opts.Names)
+	}
+
+	// a failing iterator leaves the map of a field alone
+	counts := map[string]int{"kept": 1}
+	opts = testseqoptions{Counts: counts}
+	err = ExtractWithCoercion(&opts, WithCountSeq(func(yield func(string, string) bool) {
+		if yield("added", "2") {
+			yield("bad", "not a number")
+		}
+	}))
+	if err == nil {
+		t.Fatalf("ExtractWithCoercion should have failed parsing a word as an int, but err is nil")
+	}
+	if len(counts) != 1 || len(opts.Counts) != 1 {
+		t.Fatalf("Counts should be map[kept:1], got %v", opts.Counts)
+	}
+}
+
+func countTo(n int) func(yield func(int) bool) {
+	return func(yield func(int) bool) {
+		for i := 1; i <= n; i++ {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+}
+
+type WithPortSeq func(yield func(int) bool)
+type WithLabelSeq func(yield func(string, int) bool)
+type WithNameSeq func(yield func(int) bool)
+type WithCountSeq func(yield func(string, string) bool)
+
+type testseqoptions struct {
+	Ports  []int          `optname:"WithPortSeq"`
+	Labels map[string]int `optname:"WithLabelSeq"`
+	Names  []string       `optname:"WithNameSeq"`
+	Counts map[string]int `optname:"WithCountSeq"`
+}
@@ -0,0 +1,62 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// OptSetter is implemented by field types that assign options into themselves,
+// such as accumulators and validating wrappers. A field whose type, or pointer
+// to its type, implements OptSetter has SetOpt called with every option value
+// bound for it in place of the built-in fits, which it wins over. Nil pointer
+// fields are allocated first. An error from SetOpt is returned naming the
+// field.
+type OptSetter interface {
+	SetOpt(value interface{}) error
+}
+
+var optSetterType = reflect.TypeOf((*OptSetter)(nil)).Elem()
+
+// optSetter returns the OptSetter of a field, allocating a nil pointer field
+// that implements it.
+func optSetter(field reflect.Value) (OptSetter, bool) {
+	if field.Kind() == reflect.Ptr && field.Type().Implements(optSetterType) {
+		if field.IsNil() {
+			if !field.CanSet() {
+				return nil, false
+			}
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+		return field.Interface().(OptSetter), true
+	}
+	if field.CanAddr() && field.Addr().Type().Implements(optSetterType) {
+		return field.Addr().Interface().(OptSetter), true
+	}
+	return nil, false
+}
+
+// fitSetter hands optionValue to the OptSetter of a field.
+func fitSetter(setter OptSetter, sf reflect.StructField, optname string, optionValue reflect.Value) error {
+	if err := setter.SetOpt(optionValue.Interface()); err != nil {
+		return fmt.Errorf("failed to set %s, field %s: %s", optname, sf.Name, err)
+	}
+	return nil
+}
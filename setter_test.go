@@ -0,0 +1,63 @@
+package opts
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestOptSetter(t *testing.T) {
+	opts := testsetteroptions{}
+	err := MustExtract(&opts, WithTotal(2), WithTotal(3), WithPeak(7), WithPeak(4))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Total.sum != 5 || opts.Total.count != 2 {
+		t.Fatalf("Total should have accumulated 5 over 2 options, got %+v", opts.Total)
+	}
+	if opts.Peak == nil || opts.Peak.sum != 11 {
+		t.Fatalf("Peak should have been allocated and accumulated 11, got %+v", opts.Peak)
+	}
+
+	err = MustExtract(&opts, WithTotal(-1))
+	if err == nil || !strings.Contains(err.Error(), "field Total") {
+		t.Fatalf("err should name field Total, got %v", err)
+	}
+}
+
+type WithTotal int
+type WithPeak int
+
+// testaccumulator sums every option set into it.
+type testaccumulator struct {
+	sum, count int
+}
+
+func (a *testaccumulator) SetOpt(value interface{}) error {
+	n, err := toInt(value)
+	if err != nil {
+		return err
+	}
+	if n < 0 {
+		return errors.New("negative values are not allowed")
+	}
+	a.sum += n
+	a.count++
+	return nil
+}
+
+func toInt(value interface{}) (int, error) {
+	switch v := value.(type) {
+	case WithTotal:
+		return int(v), nil
+	case WithPeak:
+		return int(v), nil
+	}
+	return 0, fmt.Errorf("unexpected %T", value)
+}
+
+type testsetteroptions struct {
+	Total testaccumulator  `optname:"WithTotal"`
+	Peak  *testaccumulator `optname:"WithPeak"`
+}
@@ -0,0 +1,70 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// KindMismatchError is returned when the kind of an option is refused for its
+// field, under ExtractStrictShape when they disagree on being a slice, under
+// ExtractStrictSlice for a single option bound for a slice and under
+// ExtractNoStringCoercion for a string bound for a number or back. Want is the
+// kind of the field and Got the kind of the option, as reflect.Kind names them.
+type KindMismatchError struct {
+	OptName string
+	Want    string
+	Got     string
+}
+
+func (e *KindMismatchError) Error() string {
+	return fmt.Sprintf("failed to set %s, field is a %s but the option is a %s", e.OptName, e.Want, e.Got)
+}
+
+// ExtractStrictShape extracts options into dest struct, refusing to append a
+// single option into a slice field or to fit a slice option into a single
+// value field. A refused option results in a *KindMismatchError. Strings
+// decoded into bytes by an encoding tag are single values that fit a []byte
+// field. Options not in dest are skipped.
+func ExtractStrictShape(dest interface{}, options ...interface{}) error {
+	return (&extractor{strictShape: true}).extract(dest, options...)
+}
+
+// checkShape reports a *KindMismatchError when the option and field disagree
+// on being a slice.
+func checkShape(field reflect.Value, sf reflect.StructField, optname string, optionValue reflect.Value) error {
+	if field.Kind() == reflect.Interface {
+		return nil
+	}
+	if field.CanAddr() {
+		if _, ok := field.Addr().Interface().(secretField); ok {
+			return nil
+		}
+	}
+	fieldSlice := field.Kind() == reflect.Slice || field.Kind() == reflect.Array
+	optionSlice := optionValue.Kind() == reflect.Slice || optionValue.Kind() == reflect.Array
+	if _, found := sf.Tag.Lookup("encoding"); found && optionValue.Kind() == reflect.String {
+		optionSlice = true
+	}
+	if fieldSlice == optionSlice {
+		return nil
+	}
+	return &KindMismatchError{OptName: optname, Want: field.Kind().String(), Got: optionValue.Kind().String()}
+}
@@ -0,0 +1,42 @@
+package opts
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestExtractStrictShape(t *testing.T) {
+	opts := testoptions{}
+	if err := ExtractStrictShape(&opts, WithList{"a", "b"}, WithUsername("userbob")); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(opts.List) != 2 || opts.Username != "userbob" {
+		t.Fatalf("options should have applied, got %+v", opts)
+	}
+
+	var mismatch *KindMismatchError
+	err := ExtractStrictShape(&opts, WithItem("a"))
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("err should be a *KindMismatchError, got %v", err)
+	}
+	if mismatch.OptName != "WithItem" || mismatch.Want != "slice" || mismatch.Got != "string" {
+		t.Fatalf("mismatch should be {WithItem slice string}, got %+v", mismatch)
+	}
+
+	err = ExtractStrictShape(&testshapeoptions{}, WithList{"a"})
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("err should be a *KindMismatchError, got %v", err)
+	}
+	if mismatch.Want != "string" || mismatch.Got != "slice" {
+		t.Fatalf("mismatch should want string and get slice, got %+v", mismatch)
+	}
+
+	// the default stays permissive
+	if err := Extract(&opts, WithItem("a")); err != nil {
+		t.Fatalf("%s", err)
+	}
+}
+
+type testshapeoptions struct {
+	List string `optname:"WithList"`
+}
@@ -0,0 +1,330 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// Source identifies where a value came from, such as SourceEnv.
+type Source string
+
+// The sources of the layers provided by this package.
+const (
+	SourceDefault Source = "default"
+	SourceEnv     Source = "env"
+	SourceFlag    Source = "flag"
+	SourceFile    Source = "file"
+	SourceOptions Source = "options"
+)
+
+// SourceNotPermittedError is returned when a layer holds a value for a field
+// whose source tag doesn't permit the layer's source.
+type SourceNotPermittedError struct {
+	Field  string
+	Source Source
+}
+
+func (e *SourceNotPermittedError) Error() string {
+	return fmt.Sprintf("field %s may not be set from %s", e.Field, e.Source)
+}
+
+// checkSource refuses a value from the extractor's source for a field whose
+// source tag doesn't list it. Values assigned outside of layers have no source
+// and are always permitted.
+func (x *extractor) checkSource(field reflect.StructField) error {
+	tag, found := field.Tag.Lookup("source")
+	if !found || x.source == "" {
+		return nil
+	}
+	for _, permitted := range strings.Split(tag, ",") {
+		if Source(strings.TrimSpace(permitted)) == x.source {
+			return nil
+		}
+	}
+	return &SourceNotPermittedError{Field: field.Name, Source: x.source}
+}
+
+// Layer supplies values for tagged fields from a single source. Layers are
+// stacked by LoadConfig and ValueSources, so that each one can override those
+// before it. Implementing Layer adds a custom source, such as a secrets
+// manager, that is stacked alongside those provided by this package.
+type Layer interface {
+	// Source identifies where the values of the layer come from.
+	Source() Source
+	// Values returns the values the layer holds for optnames, keyed by
+	// optname. A field takes every value held for it in order, so scalar
+	// fields end up with the last one and slice fields with all of them.
+	// Optnames the layer holds nothing for are left out.
+	Values(optnames []string) (map[string][]interface{}, error)
+}
+
+// LoadConfig extracts layers into dest struct in the order given, with later
+// layers taking precedence, as a single entry point for twelve-factor configs
+// such as
+//
+//	LoadConfig(&config, DefaultSource(defaults), FileSource("app.conf"), EnvSource("APP"), FlagsSource(flag.CommandLine))
+//
+// Precedence is resolved as in ValueSources, and errors name the source of the
+// offending layer.
+func LoadConfig(dest interface{}, layers ...Layer) error {
+	_, err := ValueSources(dest, layers...)
+	return err
+}
+
+// ValueSources extracts layers into dest struct and returns the source of the
+// value every tagged field ended up with, keyed by optname. Comparing the
+// sources of two successive extractions shows which fields changed where they
+// come from, say from a default to the environment, on a reload.
+//
+// Layers are applied in the order given and later layers take precedence. A
+// layer holding a value for a scalar field overrides the value of any earlier
+// layer, and one holding values for a slice field replaces the earlier
+// elements, unless the field is tagged slicemode:"append" in which case the
+// elements of every layer are kept in order.
+//
+// A field tagged with a comma separated list of sources, such as
+// source:"env,flag", may only be set by layers of those sources, so a
+// sensitive field can't be overridden by a less trusted input. A value from
+// any other layer results in a *SourceNotPermittedError. The sources of the
+// layers of this package are default, env, flag, file and options, and custom
+// layers name their own. Fields no layer holds a value for keep their value
+// and are left out of the result. Values are parsed into the kind of the field
+// as with ExtractWithCoercion, and errors name the source of the offending
+// layer.
+func ValueSources(dest interface{}, layers ...Layer) (map[string]Source, error) {
+	optionStruct, err := destStruct(dest)
+	if err != nil {
+		return nil, err
+	}
+	x := &extractor{coerce: true, convertNumbers: true}
+	fieldMap, err := x.prepare(optionStruct)
+	if err != nil {
+		return nil, err
+	}
+	optnames := orderedFields(fieldMap)
+
+	sources := make(map[string]Source)
+	for _, layer := range layers {
+		x.source = layer.Source()
+		values, err := layer.Values(optnames)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", layer.Source(), err)
+		}
+		for _, optname := range optnames {
+			fieldValues, found := values[optname]
+			if !found || len(fieldValues) == 0 {
+				continue
+			}
+			// a slice is replaced by the layer unless it appends, once the
+			// layer is known to be permitted
+			field := fieldMap[optname]
+			if err := x.checkSource(field); err != nil {
+				return nil, fmt.Errorf("%s: %w", layer.Source(), err)
+			}
+			if field.Type.Kind() == reflect.Slice && field.Tag.Get("slicemode") != "append" {
+				fieldValue, _ := fieldByIndex(optionStruct, field.Index, true)
+				fieldValue.Set(reflect.Zero(field.Type))
+			}
+			for _, value := range fieldValues {
+				if err := x.assign(optionStruct, fieldMap, optname, reflect.ValueOf(value)); err != nil {
+					return nil, fmt.Errorf("%s: %w", layer.Source(), err)
+				}
+			}
+			sources[optname] = layer.Source()
+		}
+	}
+	x.source = ""
+	if err := x.finish(optionStruct, fieldMap); err != nil {
+		return nil, err
+	}
+	return sources, nil
+}
+
+// defaultSource is the Layer of DefaultSource.
+type defaultSource map[string]interface{}
+
+// DefaultSource returns a layer holding values keyed by optname, with the
+// source SourceDefault. It usually comes first, beneath every other layer.
+func DefaultSource(values map[string]interface{}) Layer {
+	return defaultSource(values)
+}
+
+func (d defaultSource) Source() Source {
+	return SourceDefault
+}
+
+func (d defaultSource) Values(optnames []string) (map[string][]interface{}, error) {
+	values := make(map[string][]interface{})
+	for _, optname := range optnames {
+		if value, found := d[optname]; found {
+			values[optname] = []interface{}{value}
+		}
+	}
+	return values, nil
+}
+
+// envSource is the Layer of EnvSource.
+type envSource string
+
+// EnvSource returns a layer holding the environment variables named after
+// optnames, with the source SourceEnv. The variable for an optname is prefix,
+// an underscore and the optname in upper snake case without its leading With,
+// so with the prefix APP the optname WithMaxConns is read from APP_MAX_CONNS.
+// An empty prefix leaves out the underscore too. A variable holds one value,
+// even for slice fields.
+func EnvSource(prefix string) Layer {
+	return envSource(prefix)
+}
+
+func (e envSource) Source() Source {
+	return SourceEnv
+}
+
+func (e envSource) Values(optnames []string) (map[string][]interface{}, error) {
+	values := make(map[string][]interface{})
+	for _, optname := range optnames {
+		if value, found := os.LookupEnv(envName(string(e), optname)); found {
+			values[optname] = []interface{}{value}
+		}
+	}
+	return values, nil
+}
+
+// envName returns the environment variable an optname is read from.
+func envName(prefix, optname string) string {
+	name := strings.ToUpper(caseKey(optname))
+	if prefix == "" {
+		return name
+	}
+	return prefix + "_" + name
+}
+
+// optionsSource is the Layer of OptionsSource.
+type optionsSource []interface{}
+
+// OptionsSource returns a layer holding options, with the source
+// SourceOptions. Options are named as they are when extracted, and bundles
+// made with Combine are expanded. Groups and spread structs are not named by a
+// single optname, so they are left out.
+func OptionsSource(options ...interface{}) Layer {
+	held := make(optionsSource, len(options))
+	copy(held, options)
+	return held
+}
+
+func (o optionsSource) Source() Source {
+	return SourceOptions
+}
+
+func (o optionsSource) Values(optnames []string) (map[string][]interface{}, error) {
+	values := make(map[string][]interface{})
+	for _, option := range expandOptions(o) {
+		if carriesOptions(option) {
+			continue
+		}
+		optname, optionValue := resolveOption(option)
+		if !optionValue.IsValid() {
+			continue
+		}
+		values[optname] = append(values[optname], optionValue.Interface())
+	}
+	return values, nil
+}
+
+// flagsSource is the Layer of FlagsSource.
+type flagsSource struct {
+	fs *flag.FlagSet
+}
+
+// FlagsSource returns a layer holding the flags of fs that were set on the
+// command line, with the source SourceFlag, so flags left at their defaults
+// don't override earlier layers. fs must have been parsed. The flag for an
+// optname is the optname in kebab case without its leading With, so the
+// optname WithMaxConns is read from -max-conns. Flags with a flag.Getter
+// value, as the flags defined by the flag package have, hold their typed value
+// and others hold their value formatted as a string.
+func FlagsSource(fs *flag.FlagSet) Layer {
+	return flagsSource{fs: fs}
+}
+
+func (f flagsSource) Source() Source {
+	return SourceFlag
+}
+
+func (f flagsSource) Values(optnames []string) (map[string][]interface{}, error) {
+	names := make(map[string]string, len(optnames))
+	for _, optname := range optnames {
+		names[flagName(optname)] = optname
+	}
+	values := make(map[string][]interface{})
+	f.fs.Visit(func(fl *flag.Flag) {
+		optname, found := names[fl.Name]
+		if !found {
+			return
+		}
+		var value interface{} = fl.Value.String()
+		if getter, ok := fl.Value.(flag.Getter); ok {
+			value = getter.Get()
+		}
+		values[optname] = []interface{}{value}
+	})
+	return values, nil
+}
+
+// flagName returns the flag an optname is read from.
+func flagName(optname string) string {
+	return strings.ReplaceAll(caseKey(optname), "_", "-")
+}
+
+// fileSource is the Layer of FileSource.
+type fileSource string
+
+// FileSource returns a layer holding the Name=Value lines of the file at path,
+// in the format of ExtractKeyValueFile, with the source SourceFile. The file is
+// read when the layer is applied and errors name its path. Repeated names hold
+// every value in order.
+func FileSource(path string) Layer {
+	return fileSource(path)
+}
+
+func (f fileSource) Source() Source {
+	return SourceFile
+}
+
+func (f fileSource) Values(optnames []string) (map[string][]interface{}, error) {
+	file, err := os.Open(string(f))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	pairs, err := readKeyValues(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", f, err)
+	}
+	values := make(map[string][]interface{})
+	for _, pair := range pairs {
+		values[pair.name] = append(values[pair.name], pair.value)
+	}
+	return values, nil
+}
@@ -0,0 +1,160 @@
+package opts
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValueSources(t *testing.T) {
+	t.Setenv("APP_PHONE_NUM", "8675309")
+	t.Setenv("APP_ITEM", "from-env")
+
+	defaults := DefaultSource(map[string]interface{}{
+		"WithUsername": "nobody",
+		"WithPhoneNum": 1,
+		"WithBool":     true,
+		"WithItem":     "from-default",
+	})
+
+	opts := testoptions{}
+	sources, err := ValueSources(&opts, defaults, EnvSource("APP"), OptionsSource(WithUsername("userbob")))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	want := map[string]Source{
+		"WithUsername": SourceOptions,
+		"WithPhoneNum": SourceEnv,
+		"WithBool":     SourceDefault,
+		"WithItem":     SourceEnv,
+	}
+	if len(sources) != len(want) {
+		t.Fatalf("sources should be %v, got %v", want, sources)
+	}
+	for optname, source := range want {
+		if sources[optname] != source {
+			t.Fatalf("source of %s should be %s, got %s", optname, source, sources[optname])
+		}
+	}
+	if opts.Username != "userbob" || opts.PhoneNum != 8675309 || !opts.Boolean {
+		t.Fatalf("layers should have applied in order, got %+v", opts)
+	}
+	// later layers replace slices
+	if len(opts.Items) != 1 || opts.Items[0] != "from-env" {
+		t.Fatalf("Items should be [from-env], got %v", opts.Items)
+	}
+
+	t.Setenv("APP_PHONE_NUM", "not a number")
+	if _, err := ValueSources(&testoptions{}, EnvSource("APP")); err == nil {
+		t.Fatalf("ValueSources should have failed parsing APP_PHONE_NUM, but err is nil")
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	if name := envName("APP", "WithMaxConns"); name != "APP_MAX_CONNS" {
+		t.Fatalf("name should be 'APP_MAX_CONNS', got '%s'", name)
+	}
+	if name := envName("", "WithTLSCert"); name != "TLS_CERT" {
+		t.Fatalf("name should be 'TLS_CERT', got '%s'", name)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.conf")
+	err := os.WriteFile(path, []byte("WithUsername=fromfile\nWithPhoneNum=1\nWithItem=a\nWithItem=b\n"), 0o600)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	t.Setenv("APP_PHONE_NUM", "2")
+
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	fs.Int("phone-num", 0, "")
+	fs.String("username", "flagdefault", "")
+	fs.Bool("bool", false, "")
+	if err := fs.Parse([]string{"-phone-num", "3", "-bool"}); err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	opts := testloadoptions{}
+	err = LoadConfig(&opts,
+		FileSource(path),
+		EnvSource("APP"),
+		FlagsSource(fs),
+		OptionsSource(WithItem("c")),
+	)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	// flags left at their defaults don't override the file
+	if opts.Username != "fromfile" {
+		t.Fatalf("Username should be 'fromfile', got '%s'", opts.Username)
+	}
+	if opts.PhoneNum != 3 || !opts.Boolean {
+		t.Fatalf("flags should have overridden earlier layers, got %+v", opts)
+	}
+	if len(opts.Items) != 3 || opts.Items[0] != "a" || opts.Items[2] != "c" {
+		t.Fatalf("Items should append across layers as [a b c], got %v", opts.Items)
+	}
+
+	err = LoadConfig(&testloadoptions{}, FileSource(filepath.Join(t.TempDir(), "missing.conf")))
+	if err == nil || !strings.HasPrefix(err.Error(), "file: ") {
+		t.Fatalf("err should name the file source, got %v", err)
+	}
+}
+
+func TestSourceTag(t *testing.T) {
+	t.Setenv("APP_PASSWORD", "from-env")
+
+	opts := testsourceoptions{}
+	err := LoadConfig(&opts, EnvSource("APP"), OptionsSource(WithUsername("userbob")))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Password != "from-env" || opts.Username != "userbob" {
+		t.Fatalf("permitted sources should have applied, got %+v", opts)
+	}
+
+	err = LoadConfig(&testsourceoptions{}, OptionsSource(WithPassword("from-options")))
+	var notPermitted *SourceNotPermittedError
+	if !errors.As(err, &notPermitted) {
+		t.Fatalf("err should be a *SourceNotPermittedError, got %v", err)
+	}
+	if notPermitted.Field != "Password" || notPermitted.Source != SourceOptions {
+		t.Fatalf("err should name Password and options, got %+v", notPermitted)
+	}
+
+	// a refused layer leaves a slice field alone
+	opts = testsourceoptions{Hosts: []string{"kept"}}
+	err = LoadConfig(&opts, OptionsSource(WithHost("from-options")))
+	if !errors.As(err, &notPermitted) {
+		t.Fatalf("err should be a *SourceNotPermittedError, got %v", err)
+	}
+	if len(opts.Hosts) != 1 || opts.Hosts[0] != "kept" {
+		t.Fatalf("Hosts should be [kept], got %v", opts.Hosts)
+	}
+
+	// options applied outside of layers have no source
+	opts = testsourceoptions{}
+	if err := Extract(&opts, WithPassword("direct")); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Password != "direct" {
+		t.Fatalf("Password should be 'direct', got '%s'", opts.Password)
+	}
+}
+
+type testsourceoptions struct {
+	Username string   `optname:"WithUsername"`
+	Password string   `optname:"WithPassword" source:"env, flag"`
+	Hosts    []string `optname:"WithHost" source:"env"`
+}
+
+type testloadoptions struct {
+	Username string   `optname:"WithUsername"`
+	PhoneNum int      `optname:"WithPhoneNum"`
+	Boolean  bool     `optname:"WithBool"`
+	Items    []string `optname:"WithItem" slicemode:"append"`
+}
@@ -0,0 +1,70 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// spreadOption carries a struct whose tagged fields extract as options.
+type spreadOption struct {
+	value interface{}
+}
+
+// Spread wraps a struct, or a pointer to one, so that it extracts as a set of
+// options rather than as a single value. Each tagged field of the struct that
+// isn't at its zero value extracts as an option named by the field's optname,
+// so a partially filled config struct can be passed as options. Zero fields
+// are skipped and leave the destination alone.
+//
+// Without Spread a struct option is assigned wholesale to the field its type
+// name matches, like any other option.
+func Spread(structOption interface{}) interface{} {
+	return spreadOption{value: structOption}
+}
+
+// spread assigns the non-zero tagged fields of a spread struct.
+func (x *extractor) spread(optionStruct reflect.Value, fieldMap map[string]reflect.StructField, carrier spreadOption) error {
+	source := reflect.ValueOf(carrier.value)
+	if source.Kind() == reflect.Ptr {
+		if source.IsNil() {
+			return nil
+		}
+		source = source.Elem()
+	}
+	if source.Kind() != reflect.Struct {
+		return fmt.Errorf("spread option must be a struct")
+	}
+
+	sourceMap, err := x.mapFields(source.Type())
+	if err != nil {
+		return err
+	}
+	for _, optname := range orderedFields(sourceMap) {
+		fieldValue, ok := fieldByIndex(source, sourceMap[optname].Index, false)
+		if !ok || fieldValue.IsZero() {
+			continue
+		}
+		if err := x.assign(optionStruct, fieldMap, optname, fieldValue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package opts
+
+import (
+	"testing"
+)
+
+func TestSpread(t *testing.T) {
+	partial := testspreadoptions{Username: "userbob", PhoneNum: 8675309}
+
+	opts := testoptions{Boolean: true, Items: []string{"kept"}}
+	err := MustExtract(&opts, Spread(partial), WithItem("hello"))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Username != "userbob" || opts.PhoneNum != 8675309 {
+		t.Fatalf("spread fields should have applied, got %+v", opts)
+	}
+	// zero fields of the spread struct leave dest alone
+	if !opts.Boolean {
+		t.Fatalf("Boolean should have been left alone")
+	}
+	if len(opts.Items) != 2 || opts.Items[0] != "kept" || opts.Items[1] != "hello" {
+		t.Fatalf("Items should be [kept hello], got %v", opts.Items)
+	}
+
+	opts = testoptions{}
+	if err := MustExtract(&opts, Spread(&partial)); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Username != "userbob" {
+		t.Fatalf("Username should be 'userbob', got '%s'", opts.Username)
+	}
+
+	if err := MustExtract(&opts, Spread(testspreadextra{Host: "localhost"})); err == nil {
+		t.Fatalf("MustExtract should have failed on a spread field dest doesn't have, but err is nil")
+	}
+	if err := Extract(&opts, Spread("not a struct")); err == nil {
+		t.Fatalf("Extract should have failed spreading a string, but err is nil")
+	}
+}
+
+type testspreadoptions struct {
+	Username string `optname:"WithUsername"`
+	PhoneNum int    `optname:"WithPhoneNum"`
+	Boolean  bool   `optname:"WithBool"`
+}
+
+type testspreadextra struct {
+	Host string `optname:"WithHost"`
+}
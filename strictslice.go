@@ -0,0 +1,74 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"reflect"
+)
+
+// ExtractStrictSlice extracts options into dest struct, refusing to append a
+// single option into a slice field, so slice fields are only set by slice
+// options. A refused option results in a *KindMismatchError rather than being
+// appended. Options not in dest are skipped.
+//
+// Slice options replace the elements of their field as in Extract, unless the
+// field is tagged slicemode:"append" in which case they add to them, taking
+// the place of the single options that would have been appended. Unlike
+// ExtractStrictShape, slice options for fields holding a single value fit as
+// they otherwise would. Single options converted into a whole slice rather
+// than appended, such as strings decoded by an encoding tag, split by a
+// pathlist tag or fitted by a registered converter, are not refused.
+func ExtractStrictSlice(dest interface{}, options ...interface{}) error {
+	return (&extractor{strictSlice: true}).extract(dest, options...)
+}
+
+// checkSliceAppend reports a *KindMismatchError when a single option would be
+// appended into a slice field.
+func checkSliceAppend(field reflect.Value, sf reflect.StructField, optname string, optionValue reflect.Value) error {
+	if field.Kind() != reflect.Slice || optionValue.Kind() == reflect.Slice {
+		return nil
+	}
+	if _, found := sf.Tag.Lookup("encoding"); found {
+		return nil
+	}
+	if takesPathList(field, sf) {
+		return nil
+	}
+	if _, found := lookupConverter(optionValue.Type(), field.Type()); found {
+		return nil
+	}
+	return &KindMismatchError{OptName: optname, Want: field.Kind().String(), Got: optionValue.Kind().String()}
+}
+
+// appendsSlice reports whether a slice option adds to the elements of its
+// field rather than replacing them.
+func appendsSlice(field reflect.Value, sf reflect.StructField, optionValue reflect.Value) bool {
+	return field.Kind() == reflect.Slice && optionValue.Kind() == reflect.Slice && sf.Tag.Get("slicemode") == "append"
+}
+
+// fitAppend fits a slice option into a slice of its own and appends that to
+// field.
+func (x *extractor) fitAppend(field reflect.Value, sf reflect.StructField, optname string, optionValue reflect.Value) error {
+	elements := reflect.New(field.Type()).Elem()
+	if err := x.fit(elements, sf, optname, optionValue); err != nil {
+		return err
+	}
+	field.Set(reflect.AppendSlice(field, elements))
+	return nil
+}
@@ -0,0 +1,51 @@
+package opts
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestExtractStrictSlice(t *testing.T) {
+	opts := testoptions{}
+	if err := ExtractStrictSlice(&opts, WithList{"a", "b"}, WithUsername("userbob")); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(opts.List) != 2 || opts.Username != "userbob" {
+		t.Fatalf("options should have applied, got %+v", opts)
+	}
+
+	var mismatch *KindMismatchError
+	err := ExtractStrictSlice(&opts, WithItem("a"))
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("err should be a *KindMismatchError, got %v", err)
+	}
+	if mismatch.OptName != "WithItem" || mismatch.Want != "slice" || mismatch.Got != "string" {
+		t.Fatalf("mismatch should be {WithItem slice string}, got %+v", mismatch)
+	}
+	if len(opts.Items) != 0 {
+		t.Fatalf("Items should have been left empty, got %v", opts.Items)
+	}
+
+	// slice options replace the elements of fields that don't append
+	if err := ExtractStrictSlice(&opts, WithList{"c"}); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(opts.List) != 1 || opts.List[0] != "c" {
+		t.Fatalf("List should be [c], got %v", opts.List)
+	}
+
+	// and add to those tagged slicemode:"append"
+	defaults := testdefaultoptions{}
+	if err := ExtractStrictSlice(&defaults, Named("WithPath", []string{"/opt"})); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(defaults.Paths) < 2 || defaults.Paths[len(defaults.Paths)-1] != "/opt" {
+		t.Fatalf("Paths should have had /opt appended to its defaults, got %v", defaults.Paths)
+	}
+
+	// single options converted into a whole slice are not refused
+	cert := testencodingoptions{}
+	if err := ExtractStrictSlice(&cert, WithCert("aGVsbG8=")); err != nil {
+		t.Fatalf("%s", err)
+	}
+}
@@ -0,0 +1,99 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// SyncExtract converges dest struct to the state desired holds, for
+// reconcilers that declare a config rather than add to it. desired maps the
+// optnames of dest to their values, which are converted as with
+// ExtractNestedMap. Keys not in dest are skipped.
+//
+// Unlike the other extractions, which only assign the options they are given,
+// SyncExtract also zeroes every tagged field whose optname is absent from
+// desired, treating it as removed, so a field no longer specified goes back to
+// its zero value rather than keeping what it held. Defaults are not seeded,
+// and slice and map fields are replaced wholesale by their value in desired.
+//
+// The optnames of the fields that went from zero to a value are returned in
+// added, those whose value changed in changed and those zeroed for being absent
+// in removed, each in the order of the fields. On error dest is left as it was
+// and the lists are nil.
+func SyncExtract(dest interface{}, desired map[string]interface{}) (added, changed, removed []string, err error) {
+	optionStruct, err := destStruct(dest)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	x := &extractor{coerce: true, convertNumbers: true}
+	fieldMap, err := x.mapFields(optionStruct.Type())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// converge a copy so a failure leaves dest alone
+	work := reflect.New(optionStruct.Type()).Elem()
+	work.Set(deepCopy(optionStruct))
+	for _, optname := range orderedFields(fieldMap) {
+		field := fieldMap[optname]
+		value, present := desired[optname]
+		fieldValue, ok := fieldByIndex(work, field.Index, present)
+		if !ok {
+			continue
+		}
+		fieldValue.Set(reflect.Zero(fieldValue.Type()))
+		if !present {
+			continue
+		}
+		if list, isList := value.([]interface{}); isList && field.Type.Kind() == reflect.Slice {
+			for i, elem := range list {
+				if err := x.assign(work, fieldMap, optname, reflect.ValueOf(elem)); err != nil {
+					return nil, nil, nil, fmt.Errorf("key %s[%d]: %w", optname, i, err)
+				}
+			}
+			continue
+		}
+		if err := x.assign(work, fieldMap, optname, reflect.ValueOf(value)); err != nil {
+			return nil, nil, nil, fmt.Errorf("key %s: %w", optname, err)
+		}
+	}
+	if err := x.finish(work, fieldMap); err != nil {
+		return nil, nil, nil, err
+	}
+
+	for _, optname := range orderedFields(fieldMap) {
+		field := fieldMap[optname]
+		before, hadValue := fieldByIndex(optionStruct, field.Index, false)
+		hadValue = hadValue && !before.IsZero()
+		after, _ := fieldByIndex(work, field.Index, false)
+		_, present := desired[optname]
+		switch {
+		case !present && hadValue:
+			removed = append(removed, optname)
+		case present && !hadValue && after.IsValid() && !after.IsZero():
+			added = append(added, optname)
+		case present && hadValue && !reflect.DeepEqual(before.Interface(), after.Interface()):
+			changed = append(changed, optname)
+		}
+	}
+	optionStruct.Set(work)
+	return added, changed, removed, nil
+}
@@ -0,0 +1,55 @@
+package opts
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSyncExtract(t *testing.T) {
+	opts := testoptions{Username: "userbob", PhoneNum: 5, Items: []string{"a", "b"}}
+	added, changed, removed, err := SyncExtract(&opts, map[string]interface{}{
+		"WithUsername": "useralice",
+		"WithItem":     []interface{}{"c"},
+		"WithBool":     true,
+		"WithUnknown":  1,
+	})
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if !reflect.DeepEqual(added, []string{"WithBool"}) {
+		t.Fatalf("added should be [WithBool], got %v", added)
+	}
+	if !reflect.DeepEqual(changed, []string{"WithItem", "WithUsername"}) {
+		t.Fatalf("changed should be [WithItem WithUsername], got %v", changed)
+	}
+	if !reflect.DeepEqual(removed, []string{"WithPhoneNum"}) {
+		t.Fatalf("removed should be [WithPhoneNum], got %v", removed)
+	}
+	// slices are replaced rather than appended to, absent fields are zeroed
+	want := testoptions{Username: "useralice", Items: []string{"c"}, Boolean: true}
+	if !reflect.DeepEqual(opts, want) {
+		t.Fatalf("opts should be %+v, got %+v", want, opts)
+	}
+
+	// converging again changes nothing
+	added, changed, removed, err = SyncExtract(&opts, map[string]interface{}{
+		"WithUsername": "useralice",
+		"WithItem":     []interface{}{"c"},
+		"WithBool":     true,
+	})
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if added != nil || changed != nil || removed != nil {
+		t.Fatalf("nothing should have changed, got %v %v %v", added, changed, removed)
+	}
+
+	// failures leave dest alone
+	_, _, _, err = SyncExtract(&opts, map[string]interface{}{"WithPhoneNum": "not a number"})
+	if err == nil {
+		t.Fatalf("SyncExtract should have failed fitting WithPhoneNum, but err is nil")
+	}
+	if !reflect.DeepEqual(opts, want) {
+		t.Fatalf("opts should have been left as %+v, got %+v", want, opts)
+	}
+}
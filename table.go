@@ -0,0 +1,155 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// ExtractTable appends one element to the slice dest points to for every row,
+// fitting each cell into the element field whose optname is the matching
+// entry of header. Elements may be structs or pointers to structs. Cells are
+// typed values fitted like options, with numbers converting into any numeric
+// field they fit.
+//
+// Header entries that match no field result in error, as do rows that are not
+// as wide as the header, naming the row index. Rows before a failing row
+// remain appended.
+func ExtractTable(dest interface{}, header []string, rows [][]interface{}) error {
+	slice := reflect.ValueOf(dest)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("dest must be a pointer to a slice")
+	}
+	slice = slice.Elem()
+
+	elemType := slice.Type().Elem()
+	structType := elemType
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return fmt.Errorf("dest elements must be structs")
+	}
+
+	x := &extractor{mustFind: true, convertNumbers: true}
+	fieldMap, err := x.mapFields(structType)
+	if err != nil {
+		return err
+	}
+	for _, column := range header {
+		if _, found := x.lookup(fieldMap, column); !found {
+			return fmt.Errorf("invalid column %s", column)
+		}
+	}
+
+	for i, row := range rows {
+		if len(row) != len(header) {
+			return fmt.Errorf("row %d: has %d cells but the header has %d", i, len(row), len(header))
+		}
+		elem := reflect.New(structType)
+		if err := x.applyDefaults(elem.Elem(), fieldMap); err != nil {
+			return err
+		}
+		for j, cell := range row {
+			if err := x.assign(elem.Elem(), fieldMap, header[j], reflect.ValueOf(cell)); err != nil {
+				return fmt.Errorf("row %d: %w", i, err)
+			}
+		}
+		if err := x.finish(elem.Elem(), fieldMap); err != nil {
+			return fmt.Errorf("row %d: %w", i, err)
+		}
+
+		if elemType.Kind() == reflect.Ptr {
+			slice.Set(reflect.Append(slice, elem))
+		} else {
+			slice.Set(reflect.Append(slice, elem.Elem()))
+		}
+	}
+	return nil
+}
+
+// ExtractColumns builds one struct per record from columnar data, where each
+// key of columns is an optname and its slice holds the value of every record
+// in order. Record i starts as a deep copy of template, a struct or pointer to
+// one, so records share nothing with template or with each other, and the
+// i-th value of each column is fitted into it as with ExtractTable. Nil
+// values leave the template's value in place. The records are returned in
+// order, as structs when template is a struct and as pointers when it is a
+// pointer.
+//
+// Columns that match no field result in error, as do columns not as long as
+// the others.
+func ExtractColumns(columns map[string][]interface{}, template interface{}) ([]interface{}, error) {
+	templateValue := reflect.ValueOf(template)
+	structValue := templateValue
+	if structValue.Kind() == reflect.Ptr {
+		structValue = structValue.Elem()
+	}
+	if structValue.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("template must be a struct or a pointer to one")
+	}
+
+	x := &extractor{mustFind: true, convertNumbers: true}
+	fieldMap, err := x.mapFields(structValue.Type())
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(columns))
+	for name := range columns {
+		if _, found := x.lookup(fieldMap, name); !found {
+			return nil, fmt.Errorf("invalid column %s", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	length := 0
+	for i, name := range names {
+		if i == 0 {
+			length = len(columns[name])
+		} else if len(columns[name]) != length {
+			return nil, fmt.Errorf("column %s has %d values but column %s has %d", name, len(columns[name]), names[0], length)
+		}
+	}
+
+	records := make([]interface{}, length)
+	for i := range records {
+		record := reflect.New(structValue.Type())
+		record.Elem().Set(deepCopy(structValue))
+		if err := x.applyDefaults(record.Elem(), fieldMap); err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			if err := x.assign(record.Elem(), fieldMap, name, reflect.ValueOf(columns[name][i])); err != nil {
+				return nil, fmt.Errorf("record %d: %w", i, err)
+			}
+		}
+		if err := x.finish(record.Elem(), fieldMap); err != nil {
+			return nil, fmt.Errorf("record %d: %w", i, err)
+		}
+		if templateValue.Kind() == reflect.Ptr {
+			records[i] = record.Interface()
+		} else {
+			records[i] = record.Elem().Interface()
+		}
+	}
+	return records, nil
+}
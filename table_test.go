@@ -0,0 +1,87 @@
+package opts
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExtractTable(t *testing.T) {
+	header := []string{"WithHost", "WithPort"}
+	rows := [][]interface{}{
+		{"db1.internal", 5432},
+		{"db2.internal", int64(5433)},
+	}
+
+	var servers []testserver
+	if err := ExtractTable(&servers, header, rows); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(servers) != 2 {
+		t.Fatalf("servers should have 2 rows, got %v", servers)
+	}
+	if servers[0].Host != "db1.internal" || servers[0].Port != 5432 {
+		t.Fatalf("servers[0] should be {db1.internal 5432}, got %+v", servers[0])
+	}
+	if servers[1].Host != "db2.internal" || servers[1].Port != 5433 {
+		t.Fatalf("servers[1] should be {db2.internal 5433}, got %+v", servers[1])
+	}
+
+	var pointers []*testserver
+	if err := ExtractTable(&pointers, header, rows); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(pointers) != 2 || pointers[1].Port != 5433 {
+		t.Fatalf("pointers should hold both rows, got %v", pointers)
+	}
+
+	servers = nil
+	err := ExtractTable(&servers, header, [][]interface{}{{"db1.internal", 5432}, {"db2.internal"}})
+	if err == nil {
+		t.Fatalf("ExtractTable should have failed on a short row, but err is nil")
+	}
+	if !strings.HasPrefix(err.Error(), "row 1:") {
+		t.Fatalf("error should name row 1, got '%s'", err)
+	}
+
+	if err := ExtractTable(&servers, []string{"WithHost", "WithRegion"}, nil); err == nil {
+		t.Fatalf("ExtractTable should have failed on an unknown column, but err is nil")
+	}
+}
+
+func TestExtractColumns(t *testing.T) {
+	template := testoptions{Items: []string{"base"}}
+	records, err := ExtractColumns(map[string][]interface{}{
+		"WithUsername": {"userbob", "useralice"},
+		"WithPhoneNum": {int64(1), nil},
+		"WithItem":     {"a", "b"},
+	}, template)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("there should be 2 records, got %d", len(records))
+	}
+	first, second := records[0].(testoptions), records[1].(testoptions)
+	if first.Username != "userbob" || first.PhoneNum != 1 || second.Username != "useralice" || second.PhoneNum != 0 {
+		t.Fatalf("records should hold their values, got %+v and %+v", first, second)
+	}
+	// records share nothing with the template or each other
+	if len(first.Items) != 2 || first.Items[1] != "a" || second.Items[1] != "b" || len(template.Items) != 1 {
+		t.Fatalf("Items should be copied per record, got %v, %v and %v", first.Items, second.Items, template.Items)
+	}
+
+	pointers, err := ExtractColumns(map[string][]interface{}{"WithUsername": {"userbob"}}, &template)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if record, ok := pointers[0].(*testoptions); !ok || record.Username != "userbob" {
+		t.Fatalf("records should be pointers, got %#v", pointers[0])
+	}
+
+	if _, err := ExtractColumns(map[string][]interface{}{"WithUsername": {"a"}, "WithItem": {}}, template); err == nil {
+		t.Fatalf("ExtractColumns should have failed on unequal columns, but err is nil")
+	}
+	if _, err := ExtractColumns(map[string][]interface{}{"WithHost": {"a"}}, template); err == nil {
+		t.Fatalf("ExtractColumns should have failed on an unknown column, but err is nil")
+	}
+}
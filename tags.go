@@ -0,0 +1,136 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// knownTags are the tag keys ExtractStrictTags accepts. It holds every key the
+// package reads, so a feature adding a tag adds its key here, along with the
+// keys of the encoders whose tags commonly share fields with optname.
+var knownTags = map[string]bool{
+	"optname":        true,
+	"group":          true,
+	"default":        true,
+	"defaultsep":     true,
+	"const":          true,
+	"slicemode":      true,
+	"inherit":        true,
+	"deprecated":     true,
+	"feature":        true,
+	"normalize":      true,
+	"pipeline":       true,
+	"encoding":       true,
+	"jsonfit":        true,
+	"padzero":        true,
+	"quantity":       true,
+	"runechar":       true,
+	"pathlist":       true,
+	"fingerprint":    true,
+	"source":         true,
+	"required":       true,
+	"requiredif":     true,
+	"requiredunless": true,
+	"oneof":          true,
+	"min":            true,
+	"max":            true,
+	"minlen":         true,
+	"maxlen":         true,
+	"lenmode":        true,
+	"truncatelen":    true,
+	"header":         true,
+	"metadata":       true,
+	"kdf":            true,
+	"kdfsalt":        true,
+	"kdftime":        true,
+	"kdfmemory":      true,
+	"kdfthreads":     true,
+	"kdflen":         true,
+
+	"json": true,
+	"yaml": true,
+	"xml":  true,
+	"toml": true,
+}
+
+// ExtractStrictTags extracts options into dest struct after making sure that
+// every tag key on the fields of dest, and of the structs nested in it, is one
+// the package knows. An unknown key is almost always a typo, such as
+// defualt:"5", and results in error before any option is assigned. Options not
+// in dest are skipped.
+//
+// The known keys are optname, group, default, defaultsep, const, slicemode,
+// inherit, deprecated, feature, normalize, pipeline, encoding, jsonfit,
+// padzero, quantity, runechar, pathlist, fingerprint, source, required,
+// requiredif, requiredunless, oneof, min, max, minlen, maxlen, lenmode,
+// truncatelen, header, metadata, kdf, kdfsalt, kdftime, kdfmemory, kdfthreads
+// and kdflen, along with json, yaml, xml and toml for encoders. Tags named for
+// ExtractValues are not known and so can't be used with it.
+func ExtractStrictTags(dest interface{}, options ...interface{}) error {
+	return (&extractor{strictTags: true}).extract(dest, options...)
+}
+
+// checkTagKeys refuses the tag keys of a field that aren't known.
+func checkTagKeys(sf reflect.StructField) error {
+	keys, err := tagKeys(sf.Tag)
+	if err != nil {
+		return fmt.Errorf("field %s: %s", sf.Name, err)
+	}
+	for _, key := range keys {
+		if !knownTags[key] {
+			return fmt.Errorf("field %s: unknown tag %s", sf.Name, key)
+		}
+	}
+	return nil
+}
+
+// tagKeys lists the keys of a struct tag in the conventional key:"value"
+// format.
+func tagKeys(tag reflect.StructTag) ([]string, error) {
+	var keys []string
+	rest := strings.TrimLeft(string(tag), " ")
+	for rest != "" {
+		colon := strings.Index(rest, ":")
+		if colon <= 0 || colon+1 >= len(rest) || rest[colon+1] != '"' {
+			return nil, fmt.Errorf("malformed tag %q", string(tag))
+		}
+		key := rest[:colon]
+		// the quoted value runs to the first unescaped quote
+		end := colon + 2
+		for end < len(rest) && rest[end] != '"' {
+			if rest[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(rest) {
+			return nil, fmt.Errorf("malformed tag %q", string(tag))
+		}
+		if _, err := strconv.Unquote(rest[colon+1 : end+1]); err != nil {
+			return nil, fmt.Errorf("malformed tag %q", string(tag))
+		}
+		keys = append(keys, key)
+		rest = strings.TrimLeft(rest[end+1:], " ")
+	}
+	return keys, nil
+}
@@ -0,0 +1,52 @@
+package opts
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractStrictTags(t *testing.T) {
+	opts := testtagoptions{}
+	if err := ExtractStrictTags(&opts, WithUsername("userbob")); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Username != "userbob" || opts.PhoneNum != 5 {
+		t.Fatalf("options and defaults should have applied, got %+v", opts)
+	}
+
+	if err := ExtractStrictTags(&testtypotagoptions{}); err == nil {
+		t.Fatalf("ExtractStrictTags should have failed on defualt, but err is nil")
+	}
+	// nested structs are checked too
+	if err := ExtractStrictTags(&struct{ Inner testtypotagoptions }{}); err == nil {
+		t.Fatalf("ExtractStrictTags should have failed on a nested defualt, but err is nil")
+	}
+	// other extractions ignore unknown keys
+	if err := Extract(&testtypotagoptions{}); err != nil {
+		t.Fatalf("%s", err)
+	}
+}
+
+func TestTagKeys(t *testing.T) {
+	keys, err := tagKeys(`optname:"WithItem" default:"a\"b|c"  json:"item,omitempty"`)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if !reflect.DeepEqual(keys, []string{"optname", "default", "json"}) {
+		t.Fatalf("keys should be [optname default json], got %v", keys)
+	}
+	for _, tag := range []reflect.StructTag{`optname`, `optname:WithItem`, `optname:"WithItem`} {
+		if _, err := tagKeys(tag); err == nil {
+			t.Fatalf("tagKeys should have failed on %s, but err is nil", tag)
+		}
+	}
+}
+
+type testtagoptions struct {
+	Username string `optname:"WithUsername" json:"username"`
+	PhoneNum int    `optname:"WithPhoneNum" default:"5"`
+}
+
+type testtypotagoptions struct {
+	PhoneNum int `optname:"WithPhoneNum" defualt:"5"`
+}
@@ -0,0 +1,73 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+)
+
+// ExtractTemplated extracts options into dest struct after rendering string
+// options that contain "{{" as text/template templates against data. This lets
+// options share values, e.g. WithName("{{.Env}}-service"). Options that are not
+// strings pass through untouched. Options not in dest are skipped.
+func ExtractTemplated(dest interface{}, data map[string]interface{}, options ...interface{}) error {
+	options = expandOptions(options)
+	rendered := make([]interface{}, len(options))
+	for i, option := range options {
+		option, err := renderOption(option, data)
+		if err != nil {
+			return err
+		}
+		rendered[i] = option
+	}
+	return extract(dest, false, rendered...)
+}
+
+// renderOption renders a templated string option, keeping its type.
+func renderOption(option interface{}, data map[string]interface{}) (interface{}, error) {
+	if option == nil {
+		return option, nil
+	}
+	optname, optionValue := resolveOption(option)
+	if optionValue.Kind() != reflect.String || !strings.Contains(optionValue.String(), "{{") {
+		return option, nil
+	}
+
+	tmpl, err := template.New(optname).Option("missingkey=error").Parse(optionValue.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template for %s: %s", optname, err)
+	}
+	var out strings.Builder
+	if err := tmpl.Execute(&out, data); err != nil {
+		return nil, fmt.Errorf("failed to render template for %s: %s", optname, err)
+	}
+
+	renderedValue := reflect.New(optionValue.Type()).Elem()
+	renderedValue.SetString(out.String())
+	switch carrier := option.(type) {
+	case namedOption:
+		return namedOption{name: carrier.name, value: renderedValue.Interface()}, nil
+	case carriedOption:
+		return carrier.withValue(renderedValue.Interface()), nil
+	}
+	return renderedValue.Interface(), nil
+}
@@ -0,0 +1,55 @@
+package opts
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExtractTemplated(t *testing.T) {
+	data := map[string]interface{}{"Env": "prod", "Region": "us-east"}
+
+	opts := testoptions{}
+	err := ExtractTemplated(&opts, data,
+		WithUsername("{{.Env}}-service"),
+		WithItem("{{.Region}}"),
+		WithItem("plain"),
+		WithPhoneNum(8675309),
+	)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Username != "prod-service" {
+		t.Fatalf("Username should be 'prod-service', got '%s'", opts.Username)
+	}
+	if len(opts.Items) != 2 || opts.Items[0] != "us-east" || opts.Items[1] != "plain" {
+		t.Fatalf("Items should be [us-east plain], got %v", opts.Items)
+	}
+	if opts.PhoneNum != 8675309 {
+		t.Fatalf("PhoneNum should be 8675309, got %d", opts.PhoneNum)
+	}
+
+	// Opt options keep their name once rendered
+	opts = testoptions{}
+	err = ExtractTemplated(&opts, data, Opt[string]{Name: "WithUsername", Value: "{{.Env}}-service"}, Opt[interface{}]{Name: "WithItem", Value: "{{.Region}}"})
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Username != "prod-service" || len(opts.Items) != 1 || opts.Items[0] != "us-east" {
+		t.Fatalf("Opt options should have rendered, got %+v", opts)
+	}
+
+	opts = testoptions{}
+	err = ExtractTemplated(&opts, data, WithUsername("{{.Missing}}"))
+	if err == nil {
+		t.Fatalf("ExtractTemplated should have failed on a missing key, but err is nil")
+	}
+	if !strings.Contains(err.Error(), "WithUsername") {
+		t.Fatalf("error should name the option WithUsername, got '%s'", err)
+	}
+
+	opts = testoptions{}
+	err = ExtractTemplated(&opts, data, WithUsername("{{.Env"))
+	if err == nil {
+		t.Fatalf("ExtractTemplated should have failed on a malformed template, but err is nil")
+	}
+}
@@ -0,0 +1,62 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"reflect"
+)
+
+// ExtractTransactional extracts options into dest struct in two phases, so
+// either every option is assigned or none is. Options not in dest result in
+// error.
+//
+// The first phase fits every option into a scratch struct of the same type,
+// which finds unknown options, type mismatches and values that fail to parse
+// or convert while dest is untouched. Only when it passes does the second
+// phase extract the options into dest.
+//
+// The passes that follow assigning, which are inheritance, normalization,
+// pipelines, string interning, length checks, fingerprints, the field hook and
+// conditional requirements, depend on the values in dest and only run in the
+// second phase. When one of them fails, dest is restored to a deep copy taken
+// before the second phase, as ExtractReplace would leave it.
+//
+// Everything that runs while options are fitted runs in both phases: the
+// SetOpt methods of OptSetter fields, handlers registered with
+// RegisterHandler, registered converters and the functions behind $ defaults
+// are each called twice per option or field, once against the zero scratch
+// struct. Those with side effects, such as I/O or counting calls, see both
+// calls, and a SetOpt that depends on the value its field already holds is
+// only pre-checked against the zero value.
+func ExtractTransactional(dest interface{}, options ...interface{}) error {
+	optionStruct, err := destStruct(dest)
+	if err != nil {
+		return err
+	}
+	scratch := reflect.New(optionStruct.Type())
+	if err := (&extractor{mustFind: true, dryRun: true}).extract(scratch.Interface(), options...); err != nil {
+		return err
+	}
+	snapshot := deepCopy(optionStruct)
+	if err := extract(dest, true, options...); err != nil {
+		optionStruct.Set(snapshot)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package opts
+
+import (
+	"testing"
+)
+
+func TestExtractTransactional(t *testing.T) {
+	opts := testoptions{Username: "userbob", Items: []string{"kept"}}
+	err := ExtractTransactional(&opts, WithUsername("useralice"), WithItem("added"), WithPhoneNum(5))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Username != "useralice" || opts.PhoneNum != 5 {
+		t.Fatalf("options should have applied, got %+v", opts)
+	}
+	if len(opts.Items) != 2 || opts.Items[1] != "added" {
+		t.Fatalf("Items should be [kept added], got %v", opts.Items)
+	}
+
+	// a failing option leaves dest untouched
+	opts = testoptions{Username: "userbob", Items: []string{"kept"}}
+	err = ExtractTransactional(&opts, WithUsername("useralice"), WithItem("added"), WithInvalidOption(true))
+	if err == nil {
+		t.Fatalf("ExtractTransactional should have failed on an unknown option, but err is nil")
+	}
+	if opts.Username != "userbob" || len(opts.Items) != 1 {
+		t.Fatalf("opts should be untouched, got %+v", opts)
+	}
+
+	// so does an unknown group
+	err = ExtractTransactional(&opts, WithUsername("useralice"), WithGroup("DB"), WithPhoneNum(5))
+	if err == nil {
+		t.Fatalf("ExtractTransactional should have failed on an unknown group, but err is nil")
+	}
+	if opts.Username != "userbob" || opts.PhoneNum != 0 {
+		t.Fatalf("opts should be untouched, got %+v", opts)
+	}
+
+	// and a failing pass after the options are assigned
+	lengths := testlengthoptions{Username: "userbob"}
+	err = ExtractTransactional(&lengths, WithUsername("  useralice  "), WithHost("toolong"))
+	if err == nil {
+		t.Fatalf("ExtractTransactional should have failed on a maxlen, but err is nil")
+	}
+	if lengths.Username != "userbob" || lengths.Host != "" {
+		t.Fatalf("lengths should be untouched, got %+v", lengths)
+	}
+}
@@ -0,0 +1,86 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"reflect"
+	"unicode/utf8"
+)
+
+// truncate shortens the string held by a field tagged truncatelen, or every
+// string element of a slice field, to at most the tagged length, so overlong
+// input from untrusted sources is cut rather than refused as with maxlen. The
+// length of a string is its number of runes, or of bytes when the field is
+// tagged lenmode:"bytes", and strings are only ever cut between runes, so a
+// string cut by bytes may end up shorter than the length rather than end in a
+// broken rune. before holds the value prior to truncating when it was cut.
+func truncate(field reflect.Value, sf reflect.StructField) (before reflect.Value, truncated bool) {
+	n, found, err := parseLength(sf, "truncatelen")
+	if err != nil || !found {
+		return reflect.Value{}, false
+	}
+	bytes := sf.Tag.Get("lenmode") == "bytes"
+
+	switch {
+	case field.Kind() == reflect.String:
+		if cut, ok := truncateString(field.String(), n, bytes); ok {
+			before = reflect.New(field.Type()).Elem()
+			before.Set(field)
+			field.SetString(cut)
+			return before, true
+		}
+	case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String:
+		for i := 0; i < field.Len(); i++ {
+			cut, ok := truncateString(field.Index(i).String(), n, bytes)
+			if !ok {
+				continue
+			}
+			if !truncated {
+				before = reflect.MakeSlice(field.Type(), field.Len(), field.Len())
+				reflect.Copy(before, field)
+				truncated = true
+			}
+			field.Index(i).SetString(cut)
+		}
+	}
+	return before, truncated
+}
+
+// truncateString cuts s to at most n runes, or n bytes, reporting whether it
+// was longer.
+func truncateString(s string, n int, bytes bool) (string, bool) {
+	if bytes {
+		if len(s) <= n {
+			return s, false
+		}
+		cut := n
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		return s[:cut], true
+	}
+	runes := 0
+	for i := range s {
+		if runes == n {
+			return s[:i], true
+		}
+		runes++
+	}
+	return s, false
+}
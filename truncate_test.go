@@ -0,0 +1,76 @@
+package opts
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTruncate(t *testing.T) {
+	opts := testtruncateoptions{}
+	records, err := ExtractWithLog(&opts,
+		WithUsername("userbob-the-builder"),
+		WithNote("héllo"),
+		WithItem("short"),
+		WithItem("much too long"),
+	)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Username != "userbob" {
+		t.Fatalf("Username should be cut to 'userbob', got '%s'", opts.Username)
+	}
+	// é is two bytes, so 2 bytes can't hold more than the h
+	if opts.Note != "h" {
+		t.Fatalf("Note should be cut to 'h', got '%s'", opts.Note)
+	}
+	if want := []string{"short", "much "}; !reflect.DeepEqual(opts.Items, want) {
+		t.Fatalf("Items should be %v, got %v", want, opts.Items)
+	}
+
+	var truncations []ApplyRecord
+	for _, record := range records {
+		if record.Action == ActionTruncate {
+			truncations = append(truncations, record)
+		}
+	}
+	if len(truncations) != 3 {
+		t.Fatalf("there should be 3 truncations, got %+v", truncations)
+	}
+	if truncations[0].Before != "userbob-the-builder" || truncations[0].After != "userbob" {
+		t.Fatalf("the first truncation should record both values, got %+v", truncations[0])
+	}
+
+	if err := Extract(&testbadtruncateoptions{}); err == nil {
+		t.Fatalf("Extract should have failed on a bad truncatelen, but err is nil")
+	}
+}
+
+func TestTruncateString(t *testing.T) {
+	tests := []struct {
+		s     string
+		n     int
+		bytes bool
+		want  string
+	}{
+		{"héllo", 2, false, "hé"},
+		{"héllo", 3, true, "hé"},
+		{"日本語", 2, false, "日本"},
+		{"日本語", 5, true, "日"},
+		{"short", 10, false, "short"},
+	}
+	for _, test := range tests {
+		if cut, _ := truncateString(test.s, test.n, test.bytes); cut != test.want {
+			t.Fatalf("truncateString(%q, %d, %v) should be %q, got %q", test.s, test.n, test.bytes, test.want, cut)
+		}
+	}
+}
+
+type testtruncateoptions struct {
+	Username string   `optname:"WithUsername" truncatelen:"7"`
+	Note     string   `optname:"WithNote" truncatelen:"2" lenmode:"bytes"`
+	Items    []string `optname:"WithItem" truncatelen:"5"`
+}
+
+type testbadtruncateoptions struct {
+	Username string `optname:"WithUsername" truncatelen:"-1"`
+}
@@ -0,0 +1,69 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// ExtractUint64Map extracts the flat numeric config a plugin host provides,
+// such as through WebAssembly host imports, from m into dest struct. Each key
+// is the optname of an integer or floating point field, or a slice of them,
+// and its value is converted into the field's type. A value that doesn't fit
+// its field, such as one above 127 for an int8 or above math.MaxInt64 for an
+// int64, results in an *OverflowError rather than wrapping around. Keys of
+// fields that aren't numeric result in error, while unknown keys are skipped.
+func ExtractUint64Map(dest interface{}, m map[string]uint64) error {
+	optionStruct, err := destStruct(dest)
+	if err != nil {
+		return err
+	}
+	x := &extractor{convertNumbers: true}
+	fieldMap, err := x.prepare(optionStruct)
+	if err != nil {
+		return err
+	}
+
+	// apply in a stable order so errors are deterministic
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		field, found := x.lookup(fieldMap, key)
+		if !found {
+			continue
+		}
+		t := field.Type
+		if t.Kind() == reflect.Slice {
+			t = t.Elem()
+		}
+		if !numericKind(t.Kind()) {
+			return fmt.Errorf("key %s: field %s of type %s is not numeric", key, field.Name, field.Type.String())
+		}
+		if err := x.assign(optionStruct, fieldMap, key, reflect.ValueOf(m[key])); err != nil {
+			return fmt.Errorf("key %s: %w", key, err)
+		}
+	}
+	return x.finish(optionStruct, fieldMap)
+}
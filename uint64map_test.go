@@ -0,0 +1,43 @@
+package opts
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestExtractUint64Map(t *testing.T) {
+	opts := testuint64options{}
+	err := ExtractUint64Map(&opts, map[string]uint64{
+		"WithLevel":   7,
+		"WithLimit":   math.MaxUint32,
+		"WithRatio64": 3,
+		"WithUnknown": 1,
+	})
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Level != 7 || opts.Limit != math.MaxUint32 || opts.Ratio != 3 {
+		t.Fatalf("values should have been converted, got %+v", opts)
+	}
+
+	var overflow *OverflowError
+	err = ExtractUint64Map(&testuint64options{}, map[string]uint64{"WithLevel": 128})
+	if !errors.As(err, &overflow) {
+		t.Fatalf("err should be an *OverflowError, got %v", err)
+	}
+	err = ExtractUint64Map(&testuint64options{}, map[string]uint64{"WithLimit": math.MaxUint64})
+	if !errors.As(err, &overflow) {
+		t.Fatalf("err should be an *OverflowError, got %v", err)
+	}
+	if err := ExtractUint64Map(&testuint64options{}, map[string]uint64{"WithUsername": 1}); err == nil {
+		t.Fatalf("ExtractUint64Map should have failed on a field that isn't numeric, but err is nil")
+	}
+}
+
+type testuint64options struct {
+	Level    int8    `optname:"WithLevel"`
+	Limit    int64   `optname:"WithLimit"`
+	Ratio    float64 `optname:"WithRatio64"`
+	Username string  `optname:"WithUsername"`
+}
@@ -0,0 +1,130 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Validator is implemented by option structs that can check themselves once
+// options have been applied.
+type Validator interface {
+	Validate() error
+}
+
+// ContextValidator is implemented by option structs whose checks reach out to
+// other systems and so take a context.
+type ContextValidator interface {
+	ValidateContext(ctx context.Context) error
+}
+
+// ErrNonRetryable is wrapped by validation errors that retrying won't fix, so
+// ExtractWithValidationRetry gives up on them straight away.
+var ErrNonRetryable = errors.New("validation is not retryable")
+
+// ExtractWithValidationRetry extracts options into dest struct once, then
+// validates it, retrying validation on error up to attempts times in all. This
+// is for configs checked against flaky external systems at startup. Options
+// not in dest are skipped.
+//
+// dest is validated with ValidateContext when it implements ContextValidator,
+// or otherwise with Validate when it implements Validator, and is accepted as
+// is when it implements neither. The wait between attempts starts at backoff
+// and doubles after each one. An error wrapping ErrNonRetryable is returned
+// without retrying, and when ctx is done while waiting its error is returned.
+func ExtractWithValidationRetry(ctx context.Context, dest interface{}, attempts int, backoff time.Duration, options ...interface{}) error {
+	if err := extract(dest, false, options...); err != nil {
+		return err
+	}
+
+	validate := func() error { return nil }
+	switch validator := dest.(type) {
+	case ContextValidator:
+		validate = func() error { return validator.ValidateContext(ctx) }
+	case Validator:
+		validate = validator.Validate
+	}
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = validate(); err == nil || errors.Is(err, ErrNonRetryable) || attempt >= attempts {
+			return err
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+// ExtractValidateAll extracts options into dest struct, then runs every check
+// on it and reports every failure together in one joined error instead of
+// stopping at the first. validators maps optnames to checks of the value of
+// their field, which run first in optname order. dest is validated last with
+// Validate when it implements Validator. A validator for an optname dest
+// doesn't have is reported as a failure too. Options that fail to fit are
+// returned straight away, before any validation runs. Options not in dest are
+// skipped.
+func ExtractValidateAll(dest interface{}, validators map[string]func(interface{}) error, options ...interface{}) error {
+	x := &extractor{}
+	if err := x.extract(dest, options...); err != nil {
+		return err
+	}
+	optionStruct, _ := destStruct(dest)
+	fieldMap, err := x.mapFields(optionStruct.Type())
+	if err != nil {
+		return err
+	}
+
+	optnames := make([]string, 0, len(validators))
+	for optname := range validators {
+		optnames = append(optnames, optname)
+	}
+	sort.Strings(optnames)
+
+	var errs []error
+	for _, optname := range optnames {
+		field, found := x.lookup(fieldMap, optname)
+		if !found {
+			errs = append(errs, fmt.Errorf("invalid validator %s", optname))
+			continue
+		}
+		var value interface{}
+		if fieldValue, ok := fieldByIndex(optionStruct, field.Index, false); ok && fieldValue.CanInterface() {
+			value = fieldValue.Interface()
+		}
+		if err := validators[optname](value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", optname, err))
+		}
+	}
+	if validator, ok := dest.(Validator); ok {
+		if err := validator.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,119 @@
+package opts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExtractWithValidationRetry(t *testing.T) {
+	opts := testretryoptions{failures: 2}
+	err := ExtractWithValidationRetry(context.Background(), &opts, 3, time.Millisecond, WithUsername("userbob"))
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Username != "userbob" || opts.calls != 3 {
+		t.Fatalf("validation should have passed on the third call, got %+v", opts)
+	}
+
+	opts = testretryoptions{failures: 5}
+	if err := ExtractWithValidationRetry(context.Background(), &opts, 3, time.Millisecond); err == nil {
+		t.Fatalf("ExtractWithValidationRetry should have run out of attempts, but err is nil")
+	}
+	if opts.calls != 3 {
+		t.Fatalf("validation should have been called 3 times, got %d", opts.calls)
+	}
+
+	// non-retryable errors abort immediately
+	opts = testretryoptions{failures: 5, permanent: true}
+	err = ExtractWithValidationRetry(context.Background(), &opts, 3, time.Millisecond)
+	if !errors.Is(err, ErrNonRetryable) || opts.calls != 1 {
+		t.Fatalf("validation should have stopped on the first call, got %d calls and %v", opts.calls, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	opts = testretryoptions{failures: 5}
+	err = ExtractWithValidationRetry(ctx, &opts, 3, time.Hour)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err should be context.Canceled, got %v", err)
+	}
+}
+
+type testretryoptions struct {
+	Username  string `optname:"WithUsername"`
+	failures  int
+	permanent bool
+	calls     int
+}
+
+func (o *testretryoptions) ValidateContext(ctx context.Context) error {
+	o.calls++
+	if o.calls > o.failures {
+		return nil
+	}
+	if o.permanent {
+		return fmt.Errorf("bad username: %w", ErrNonRetryable)
+	}
+	return fmt.Errorf("lookup failed")
+}
+
+func TestExtractValidateAll(t *testing.T) {
+	notEmpty := func(value interface{}) error {
+		if value == "" {
+			return errors.New("must not be empty")
+		}
+		return nil
+	}
+	positive := func(value interface{}) error {
+		if value.(int) <= 0 {
+			return errors.New("must be positive")
+		}
+		return nil
+	}
+	validators := map[string]func(interface{}) error{
+		"WithUsername": notEmpty,
+		"WithPhoneNum": positive,
+	}
+
+	opts := testvalidateoptions{}
+	if err := ExtractValidateAll(&opts, validators, WithUsername("userbob"), WithPhoneNum(1)); err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	err := ExtractValidateAll(&testvalidateoptions{}, validators, WithUsername("root"))
+	if err == nil {
+		t.Fatalf("ExtractValidateAll should have failed, but err is nil")
+	}
+	want := "WithPhoneNum: must be positive\nroot is reserved"
+	if err.Error() != want {
+		t.Fatalf("err should be %q, got %q", want, err)
+	}
+
+	validators["WithHost"] = notEmpty
+	err = ExtractValidateAll(&testvalidateoptions{}, validators, WithUsername("userbob"), WithPhoneNum(1))
+	if err == nil || !strings.Contains(err.Error(), "invalid validator WithHost") {
+		t.Fatalf("err should report the WithHost validator, got %v", err)
+	}
+
+	// fit errors come before validation
+	err = ExtractValidateAll(&testvalidateoptions{}, validators, Opt[string]{Name: "WithPhoneNum", Value: "1"})
+	if err == nil || strings.Contains(err.Error(), "WithHost") {
+		t.Fatalf("err should be the fit error alone, got %v", err)
+	}
+}
+
+type testvalidateoptions struct {
+	Username string `optname:"WithUsername"`
+	PhoneNum int    `optname:"WithPhoneNum"`
+}
+
+func (o *testvalidateoptions) Validate() error {
+	if o.Username == "root" {
+		return errors.New("root is reserved")
+	}
+	return nil
+}
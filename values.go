@@ -0,0 +1,100 @@
+/*
+   Copyright 2021 - protosam
+   Source can be found at https://github.com/protosam/opts
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+
+*/
+
+package opts
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// ExtractValues extracts string values keyed by name into dest struct, as a
+// base for helpers that read request headers, metadata and other multi-valued
+// maps. Names not in dest are skipped.
+//
+// A name matches the field with the same name in its tag, or otherwise the
+// field whose optname is the same name, after normalize has been applied to
+// both sides. A nil normalize compares names as they are. Values are parsed
+// into the kind of the field as with ExtractWithCoercion. A slice field takes
+// every value of a name in order, any other field takes the first value.
+func ExtractValues(dest interface{}, values map[string][]string, tag string, normalize func(string) string) error {
+	return extractValues(dest, values, tag, normalize, false)
+}
+
+// MustExtractValues is ExtractValues where names not in dest result in error.
+func MustExtractValues(dest interface{}, values map[string][]string, tag string, normalize func(string) string) error {
+	return extractValues(dest, values, tag, normalize, true)
+}
+
+// Underlying values function.
+func extractValues(dest interface{}, values map[string][]string, tag string, normalize func(string) string, mustFind bool) error {
+	if normalize == nil {
+		normalize = func(name string) string { return name }
+	}
+	optionStruct, err := destStruct(dest)
+	if err != nil {
+		return err
+	}
+	x := &extractor{mustFind: mustFind, coerce: true}
+	fieldMap, err := x.prepare(optionStruct)
+	if err != nil {
+		return err
+	}
+
+	// map the names values are found by to optnames
+	optnames := make(map[string]string, len(fieldMap))
+	for optname, sf := range fieldMap {
+		if name := sf.Tag.Get(tag); name != "" {
+			optnames[normalize(name)] = optname
+			continue
+		}
+		optnames[normalize(optname)] = optname
+	}
+
+	// apply in a stable order so errors are deterministic
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		optname, found := optnames[normalize(name)]
+		if !found {
+			if mustFind {
+				return fmt.Errorf("invalid %s %s", tag, name)
+			}
+			continue
+		}
+		fieldValues := values[name]
+		if len(fieldValues) == 0 {
+			continue
+		}
+		// only slices take every value
+		if fieldMap[optname].Type.Kind() != reflect.Slice {
+			fieldValues = fieldValues[:1]
+		}
+		for _, value := range fieldValues {
+			if err := x.assign(optionStruct, fieldMap, optname, reflect.ValueOf(value)); err != nil {
+				return fmt.Errorf("%s %s: %w", tag, name, err)
+			}
+		}
+	}
+	return x.finish(optionStruct, fieldMap)
+}
@@ -0,0 +1,37 @@
+package opts
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExtractValues(t *testing.T) {
+	values := map[string][]string{
+		"USER":     {"userbob"},
+		"ITEM":     {"a", "b"},
+		"withbool": {"true"},
+	}
+
+	opts := testvaluesoptions{}
+	if err := ExtractValues(&opts, values, "key", strings.ToUpper); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if opts.Username != "userbob" || !opts.Boolean {
+		t.Fatalf("values should have applied, got %+v", opts)
+	}
+	if len(opts.Items) != 2 || opts.Items[0] != "a" || opts.Items[1] != "b" {
+		t.Fatalf("Items should be [a b], got %v", opts.Items)
+	}
+
+	// without normalization names must match exactly
+	opts = testvaluesoptions{}
+	if err := MustExtractValues(&opts, values, "key", nil); err == nil {
+		t.Fatalf("MustExtractValues should have failed on withbool, but err is nil")
+	}
+}
+
+type testvaluesoptions struct {
+	Username string   `optname:"WithUsername" key:"user"`
+	Items    []string `optname:"WithItem" key:"item"`
+	Boolean  bool     `optname:"WithBool"`
+}